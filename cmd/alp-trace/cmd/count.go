@@ -63,17 +63,17 @@ func NewCountCmd() *cobra.Command {
 			switch format {
 			case "json":
 				jsonKeys := parsers.NewJSONKeys(opts.JSON.UriKey, opts.JSON.MethodKey, opts.JSON.TimeKey,
-					opts.JSON.ResponseTimeKey, opts.JSON.RequestTimeKey, opts.JSON.BodyBytesKey, opts.JSON.StatusKey, opts.JSON.TraceIDKey)
-				parser = parsers.NewJSONParser(f, jsonKeys, opts.QueryString, opts.QueryStringIgnoreValues)
+					opts.JSON.ResponseTimeKey, opts.JSON.RequestTimeKey, opts.JSON.BodyBytesKey, opts.JSON.ReqBodyBytesKey, opts.JSON.StatusKey, opts.JSON.TraceIDKey)
+				parser = parsers.NewJSONParser(f, jsonKeys, opts.QueryString, opts.QueryStringIgnoreValues, opts.Strict)
 			case "ltsv":
 				label := parsers.NewLTSVLabel(opts.LTSV.UriLabel, opts.LTSV.MethodLabel, opts.LTSV.TimeLabel,
-					opts.LTSV.ApptimeLabel, opts.LTSV.ReqtimeLabel, opts.LTSV.SizeLabel, opts.LTSV.StatusLabel, opts.LTSV.TraceIDLabel,
+					opts.LTSV.ApptimeLabel, opts.LTSV.ReqtimeLabel, opts.LTSV.SizeLabel, opts.LTSV.ReqSizeLabel, opts.LTSV.StatusLabel, opts.LTSV.TraceIDLabel,
 				)
-				parser = parsers.NewLTSVParser(f, label, opts.QueryString, opts.QueryStringIgnoreValues)
+				parser = parsers.NewLTSVParser(f, label, opts.QueryString, opts.QueryStringIgnoreValues, opts.Strict)
 			case "regexp":
 				names := parsers.NewSubexpNames(opts.Regexp.UriSubexp, opts.Regexp.MethodSubexp, opts.Regexp.TimeSubexp,
-					opts.Regexp.ResponseTimeSubexp, opts.Regexp.RequestTimeSubexp, opts.Regexp.BodyBytesSubexp, opts.Regexp.StatusSubexp)
-				parser, err = parsers.NewRegexpParser(f, opts.Regexp.Pattern, names, opts.QueryString, opts.QueryStringIgnoreValues)
+					opts.Regexp.ResponseTimeSubexp, opts.Regexp.RequestTimeSubexp, opts.Regexp.BodyBytesSubexp, opts.Regexp.ReqBodyBytesSubexp, opts.Regexp.StatusSubexp, opts.Regexp.TraceIDSubexp)
+				parser, err = parsers.NewRegexpParser(f, opts.Regexp.Pattern, names, opts.QueryString, opts.QueryStringIgnoreValues, opts.Strict)
 				if err != nil {
 					return err
 				}
@@ -39,9 +39,11 @@ func NewDiffCmd(rootCmd *cobra.Command) *cobra.Command {
 
 			sts.SetOptions(opts)
 			sts.SetSortOptions(sortOptions)
+			sts.SetGroupBy(opts.GroupBy)
 
 			printOptions := stats.NewPrintOptions(opts.NoHeaders, opts.ShowFooters, opts.DecodeUri, opts.PaginationLimit)
 			printer := stats.NewPrinter(os.Stdout, opts.Output, opts.Format, opts.Percentiles, printOptions)
+			printer.SetGroupBy(opts.GroupBy)
 			if err = printer.Validate(); err != nil {
 				return err
 			}
@@ -66,6 +68,7 @@ func NewDiffCmd(rootCmd *cobra.Command) *cobra.Command {
 
 			toSts.SetOptions(opts)
 			toSts.SetSortOptions(sortOptions)
+			toSts.SetGroupBy(opts.GroupBy)
 
 			tof, err := os.Open(to)
 			if err != nil {
@@ -79,9 +82,7 @@ func NewDiffCmd(rootCmd *cobra.Command) *cobra.Command {
 
 			toSts.SortWithOptions()
 
-			printer.Print(sts, toSts)
-
-			return nil
+			return printer.Print(sts, toSts)
 		},
 	}
 
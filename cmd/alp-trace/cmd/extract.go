@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tetsuzawa/alp-trace/parsers"
+	"github.com/tetsuzawa/alp-trace/profiler"
+	"github.com/tetsuzawa/alp-trace/stats"
+)
+
+// NewExtractCmd makes the extract subcommand of a log format, it reads the log with the flags of the parent command
+func NewExtractCmd(createOptions createOptionsFunc, newParser newParserFunc) *cobra.Command {
+	var extractCmd = &cobra.Command{
+		Use:   "extract",
+		Short: "Extract the log lines of the traces of a scenario or the trace IDs",
+		Long:  `Extract the log lines of the traces of a scenario or the trace IDs`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sortOptions := stats.NewSortOptions()
+			opts, err := createOptions(cmd.Parent(), sortOptions)
+			if err != nil {
+				return err
+			}
+
+			// the log is read to the end
+			opts.Follow = false
+
+			scenarioID, err := cmd.PersistentFlags().GetString("scenario")
+			if err != nil {
+				return err
+			}
+
+			traceIDsFile, err := cmd.PersistentFlags().GetString("trace-ids-file")
+			if err != nil {
+				return err
+			}
+
+			outFile, err := cmd.PersistentFlags().GetString("out-file")
+			if err != nil {
+				return err
+			}
+
+			traceIDs := opts.TraceIDs
+			if traceIDsFile != "" {
+				ids, err := readTraceIDsFile(traceIDsFile)
+				if err != nil {
+					return err
+				}
+				traceIDs = append(traceIDs, ids...)
+			}
+
+			if scenarioID == "" && len(traceIDs) == 0 {
+				return fmt.Errorf("--scenario, --trace-ids or --trace-ids-file is required")
+			}
+
+			var w io.Writer = os.Stdout
+			if outFile != "" {
+				of, err := os.Create(outFile)
+				if err != nil {
+					return err
+				}
+				defer of.Close()
+				w = of
+			}
+
+			// the log is read twice to select the traces of the scenario, so stdin is kept in a temporary file
+			if opts.File == "" && scenarioID != "" {
+				tmp, err := os.CreateTemp("", "alp-trace-extract-")
+				if err != nil {
+					return err
+				}
+				defer os.Remove(tmp.Name())
+
+				_, err = io.Copy(tmp, os.Stdin)
+				tmp.Close()
+				if err != nil {
+					return err
+				}
+				opts.File = tmp.Name()
+			}
+
+			prof := profiler.NewProfiler(os.Stdout, os.Stderr, opts)
+
+			files := make([]io.Closer, 0, 2)
+			defer func() {
+				for _, f := range files {
+					f.Close()
+				}
+			}()
+
+			newExtractParser := func() (parsers.Parser, error) {
+				f, err := prof.Open(opts.File)
+				if err != nil {
+					return nil, err
+				}
+				files = append(files, f)
+
+				return newParser(opts, f)
+			}
+
+			err = prof.Extract(newExtractParser, scenarioID, traceIDs, w)
+
+			return err
+		},
+	}
+
+	extractCmd.PersistentFlags().StringP("scenario", "", "", "The scenario ID of the traces")
+	extractCmd.PersistentFlags().StringP("trace-ids-file", "", "", "The file of the trace IDs, one per line")
+	extractCmd.PersistentFlags().StringP("out-file", "", "", "Write the lines to the file instead of stdout")
+
+	return extractCmd
+}
+
+func readTraceIDsFile(filename string) ([]string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	traceIDs := make([]string, 0)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		traceID := strings.TrimSpace(scanner.Text())
+		if traceID == "" {
+			continue
+		}
+		traceIDs = append(traceIDs, traceID)
+	}
+
+	return traceIDs, scanner.Err()
+}
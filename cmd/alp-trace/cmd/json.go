@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"io"
 	"os"
 
 	"github.com/tetsuzawa/alp-trace/options"
@@ -18,62 +19,11 @@ func NewJSONCmd(rootCmd *cobra.Command) *cobra.Command {
 		Long:  `Profile the logs for JSON`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			sortOptions := stats.NewSortOptions()
-			opts, err := createOptions(cmd, sortOptions)
+			opts, err := createJSONOptions(cmd, sortOptions)
 			if err != nil {
 				return err
 			}
 
-			uriKey, err := cmd.PersistentFlags().GetString("uri-key")
-			if err != nil {
-				return err
-			}
-
-			methodKey, err := cmd.PersistentFlags().GetString("method-key")
-			if err != nil {
-				return err
-			}
-
-			timeKey, err := cmd.PersistentFlags().GetString("time-key")
-			if err != nil {
-				return err
-			}
-
-			responseTimeKey, err := cmd.PersistentFlags().GetString("restime-key")
-			if err != nil {
-				return err
-			}
-
-			requestTimeKey, err := cmd.PersistentFlags().GetString("reqtime-key")
-			if err != nil {
-				return err
-			}
-
-			bodyBytesKey, err := cmd.PersistentFlags().GetString("body-bytes-key")
-			if err != nil {
-				return err
-			}
-
-			statusKey, err := cmd.PersistentFlags().GetString("status-key")
-			if err != nil {
-				return err
-			}
-
-			traceIDKey, err := cmd.PersistentFlags().GetString("traceid-key")
-			if err != nil {
-				return err
-			}
-
-			opts = options.SetOptions(opts,
-				options.UriKey(uriKey),
-				options.MethodKey(methodKey),
-				options.TimeKey(timeKey),
-				options.ResponseTimeKey(responseTimeKey),
-				options.RequestTimeKey(requestTimeKey),
-				options.BodyBytesKey(bodyBytesKey),
-				options.StatusKey(statusKey),
-				options.TraceIDKey(traceIDKey),
-			)
-
 			prof := profiler.NewProfiler(os.Stdout, os.Stderr, opts)
 
 			f, err := prof.Open(opts.File)
@@ -82,9 +32,10 @@ func NewJSONCmd(rootCmd *cobra.Command) *cobra.Command {
 			}
 			defer f.Close()
 
-			keys := parsers.NewJSONKeys(opts.JSON.UriKey, opts.JSON.MethodKey, opts.JSON.TimeKey,
-				opts.JSON.ResponseTimeKey, opts.JSON.RequestTimeKey, opts.JSON.BodyBytesKey, opts.JSON.StatusKey, opts.JSON.TraceIDKey)
-			parser := parsers.NewJSONParser(f, keys, opts.QueryString, opts.QueryStringIgnoreValues)
+			parser, err := newJSONParser(opts, f)
+			if err != nil {
+				return err
+			}
 
 			err = prof.Run(sortOptions, parser)
 
@@ -93,14 +44,91 @@ func NewJSONCmd(rootCmd *cobra.Command) *cobra.Command {
 	}
 
 	defineOptions(jsonCmd)
+	jsonCmd.AddCommand(NewShowCmd(createJSONOptions, newJSONParser))
+	jsonCmd.AddCommand(NewExtractCmd(createJSONOptions, newJSONParser))
+	jsonCmd.AddCommand(NewServeCmd(createJSONOptions, newJSONParser))
+	jsonCmd.AddCommand(NewTUICmd(createJSONOptions, newJSONParser))
 	jsonCmd.PersistentFlags().StringP("uri-key", "", options.DefaultUriKeyOption, "Change the uri key")
 	jsonCmd.PersistentFlags().StringP("method-key", "", options.DefaultMethodKeyOption, "Change the method key")
 	jsonCmd.PersistentFlags().StringP("time-key", "", options.DefaultTimeKeyOption, "Change the time key")
 	jsonCmd.PersistentFlags().StringP("restime-key", "", options.DefaultResponseTimeKeyOption, "Change the response_time key")
 	jsonCmd.PersistentFlags().StringP("reqtime-key", "", options.DefaultRequestTimeKeyOption, "Change the request_time key")
 	jsonCmd.PersistentFlags().StringP("body-bytes-key", "", options.DefaultBodyBytesKeyOption, "Change the body_bytes key")
+	jsonCmd.PersistentFlags().StringP("req-body-bytes-key", "", options.DefaultReqBodyBytesKeyOption, "Change the request_body_bytes key")
 	jsonCmd.PersistentFlags().StringP("status-key", "", options.DefaultStatusKeyOption, "Change the status key")
 	jsonCmd.PersistentFlags().StringP("traceid-key", "", options.DefaultTraceIDKeyOption, "Change the trace_id key")
 
 	return jsonCmd
 }
+
+func createJSONOptions(cmd *cobra.Command, sortOptions *stats.SortOptions) (*options.Options, error) {
+	opts, err := createOptions(cmd, sortOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	uriKey, err := cmd.PersistentFlags().GetString("uri-key")
+	if err != nil {
+		return nil, err
+	}
+
+	methodKey, err := cmd.PersistentFlags().GetString("method-key")
+	if err != nil {
+		return nil, err
+	}
+
+	timeKey, err := cmd.PersistentFlags().GetString("time-key")
+	if err != nil {
+		return nil, err
+	}
+
+	responseTimeKey, err := cmd.PersistentFlags().GetString("restime-key")
+	if err != nil {
+		return nil, err
+	}
+
+	requestTimeKey, err := cmd.PersistentFlags().GetString("reqtime-key")
+	if err != nil {
+		return nil, err
+	}
+
+	bodyBytesKey, err := cmd.PersistentFlags().GetString("body-bytes-key")
+	if err != nil {
+		return nil, err
+	}
+
+	reqBodyBytesKey, err := cmd.PersistentFlags().GetString("req-body-bytes-key")
+	if err != nil {
+		return nil, err
+	}
+
+	statusKey, err := cmd.PersistentFlags().GetString("status-key")
+	if err != nil {
+		return nil, err
+	}
+
+	traceIDKey, err := cmd.PersistentFlags().GetString("traceid-key")
+	if err != nil {
+		return nil, err
+	}
+
+	opts = options.SetOptions(opts,
+		options.UriKey(uriKey),
+		options.MethodKey(methodKey),
+		options.TimeKey(timeKey),
+		options.ResponseTimeKey(responseTimeKey),
+		options.RequestTimeKey(requestTimeKey),
+		options.BodyBytesKey(bodyBytesKey),
+		options.ReqBodyBytesKey(reqBodyBytesKey),
+		options.StatusKey(statusKey),
+		options.TraceIDKey(traceIDKey),
+	)
+
+	return opts, nil
+}
+
+func newJSONParser(opts *options.Options, r io.Reader) (parsers.Parser, error) {
+	keys := parsers.NewJSONKeys(opts.JSON.UriKey, opts.JSON.MethodKey, opts.JSON.TimeKey,
+		opts.JSON.ResponseTimeKey, opts.JSON.RequestTimeKey, opts.JSON.BodyBytesKey, opts.JSON.ReqBodyBytesKey, opts.JSON.StatusKey, opts.JSON.TraceIDKey)
+	return parsers.NewJSONParser(r, keys, opts.QueryString, opts.QueryStringIgnoreValues, opts.Strict), nil
+}
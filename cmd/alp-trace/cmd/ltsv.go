@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"io"
 	"os"
 
 	"github.com/tetsuzawa/alp-trace/options"
@@ -18,62 +19,11 @@ func NewLTSVCmd(rootCmd *cobra.Command) *cobra.Command {
 		Long:  `Profile the logs for LTSV`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			sortOptions := stats.NewSortOptions()
-			opts, err := createOptions(cmd, sortOptions)
+			opts, err := createLTSVOptions(cmd, sortOptions)
 			if err != nil {
 				return err
 			}
 
-			uriLabel, err := cmd.PersistentFlags().GetString("uri-label")
-			if err != nil {
-				return err
-			}
-
-			methodLabel, err := cmd.PersistentFlags().GetString("method-label")
-			if err != nil {
-				return err
-			}
-
-			timeLabel, err := cmd.PersistentFlags().GetString("time-label")
-			if err != nil {
-				return err
-			}
-
-			appTimeLabel, err := cmd.PersistentFlags().GetString("apptime-label")
-			if err != nil {
-				return err
-			}
-
-			reqTimeLabel, err := cmd.PersistentFlags().GetString("reqtime-label")
-			if err != nil {
-				return err
-			}
-
-			sizeLabel, err := cmd.PersistentFlags().GetString("size-label")
-			if err != nil {
-				return err
-			}
-
-			statusLabel, err := cmd.PersistentFlags().GetString("status-label")
-			if err != nil {
-				return err
-			}
-
-			traceIDLabel, err := cmd.PersistentFlags().GetString("traceid-label")
-			if err != nil {
-				return err
-			}
-
-			opts = options.SetOptions(opts,
-				options.UriLabel(uriLabel),
-				options.MethodLabel(methodLabel),
-				options.TimeLabel(timeLabel),
-				options.ApptimeLabel(appTimeLabel),
-				options.ReqtimeLabel(reqTimeLabel),
-				options.SizeLabel(sizeLabel),
-				options.StatusLabel(statusLabel),
-				options.TraceIDLabel(traceIDLabel),
-			)
-
 			prof := profiler.NewProfiler(os.Stdout, os.Stderr, opts)
 
 			f, err := prof.Open(opts.File)
@@ -82,10 +32,10 @@ func NewLTSVCmd(rootCmd *cobra.Command) *cobra.Command {
 			}
 			defer f.Close()
 
-			label := parsers.NewLTSVLabel(opts.LTSV.UriLabel, opts.LTSV.MethodLabel, opts.LTSV.TimeLabel,
-				opts.LTSV.ApptimeLabel, opts.LTSV.ReqtimeLabel, opts.LTSV.SizeLabel, opts.LTSV.StatusLabel, opts.LTSV.TraceIDLabel,
-			)
-			parser := parsers.NewLTSVParser(f, label, opts.QueryString, opts.QueryStringIgnoreValues)
+			parser, err := newLTSVParser(opts, f)
+			if err != nil {
+				return err
+			}
 
 			err = prof.Run(sortOptions, parser)
 
@@ -94,6 +44,10 @@ func NewLTSVCmd(rootCmd *cobra.Command) *cobra.Command {
 	}
 
 	defineOptions(ltsvCmd)
+	ltsvCmd.AddCommand(NewShowCmd(createLTSVOptions, newLTSVParser))
+	ltsvCmd.AddCommand(NewExtractCmd(createLTSVOptions, newLTSVParser))
+	ltsvCmd.AddCommand(NewServeCmd(createLTSVOptions, newLTSVParser))
+	ltsvCmd.AddCommand(NewTUICmd(createLTSVOptions, newLTSVParser))
 
 	ltsvCmd.PersistentFlags().StringP("uri-label", "", options.DefaultUriLabelOption, "Change the uri label")
 	ltsvCmd.PersistentFlags().StringP("method-label", "", options.DefaultMethodLabelOption, "Change the method label")
@@ -101,8 +55,82 @@ func NewLTSVCmd(rootCmd *cobra.Command) *cobra.Command {
 	ltsvCmd.PersistentFlags().StringP("apptime-label", "", options.DefaultApptimeLabelOption, "Change the apptime label")
 	ltsvCmd.PersistentFlags().StringP("reqtime-label", "", options.DefaultReqtimeLabelOption, "Change the reqtime label")
 	ltsvCmd.PersistentFlags().StringP("size-label", "", options.DefaultSizeLabelOption, "Change the size label")
+	ltsvCmd.PersistentFlags().StringP("req-size-label", "", options.DefaultReqSizeLabelOption, "Change the request size label")
 	ltsvCmd.PersistentFlags().StringP("status-label", "", options.DefaultStatusLabelOption, "Change the status label")
-	ltsvCmd.PersistentFlags().StringP("traceid-key", "", options.DefaultTraceIDLabelOption, "Change the trace_id label")
+	ltsvCmd.PersistentFlags().StringP("traceid-label", "", options.DefaultTraceIDLabelOption, "Change the trace_id label")
 
 	return ltsvCmd
 }
+
+func createLTSVOptions(cmd *cobra.Command, sortOptions *stats.SortOptions) (*options.Options, error) {
+	opts, err := createOptions(cmd, sortOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	uriLabel, err := cmd.PersistentFlags().GetString("uri-label")
+	if err != nil {
+		return nil, err
+	}
+
+	methodLabel, err := cmd.PersistentFlags().GetString("method-label")
+	if err != nil {
+		return nil, err
+	}
+
+	timeLabel, err := cmd.PersistentFlags().GetString("time-label")
+	if err != nil {
+		return nil, err
+	}
+
+	appTimeLabel, err := cmd.PersistentFlags().GetString("apptime-label")
+	if err != nil {
+		return nil, err
+	}
+
+	reqTimeLabel, err := cmd.PersistentFlags().GetString("reqtime-label")
+	if err != nil {
+		return nil, err
+	}
+
+	sizeLabel, err := cmd.PersistentFlags().GetString("size-label")
+	if err != nil {
+		return nil, err
+	}
+
+	reqSizeLabel, err := cmd.PersistentFlags().GetString("req-size-label")
+	if err != nil {
+		return nil, err
+	}
+
+	statusLabel, err := cmd.PersistentFlags().GetString("status-label")
+	if err != nil {
+		return nil, err
+	}
+
+	traceIDLabel, err := cmd.PersistentFlags().GetString("traceid-label")
+	if err != nil {
+		return nil, err
+	}
+
+	opts = options.SetOptions(opts,
+		options.UriLabel(uriLabel),
+		options.MethodLabel(methodLabel),
+		options.TimeLabel(timeLabel),
+		options.ApptimeLabel(appTimeLabel),
+		options.ReqtimeLabel(reqTimeLabel),
+		options.SizeLabel(sizeLabel),
+		options.ReqSizeLabel(reqSizeLabel),
+		options.StatusLabel(statusLabel),
+		options.TraceIDLabel(traceIDLabel),
+	)
+
+	return opts, nil
+}
+
+func newLTSVParser(opts *options.Options, r io.Reader) (parsers.Parser, error) {
+	label := parsers.NewLTSVLabel(opts.LTSV.UriLabel, opts.LTSV.MethodLabel, opts.LTSV.TimeLabel,
+		opts.LTSV.ApptimeLabel, opts.LTSV.ReqtimeLabel, opts.LTSV.SizeLabel, opts.LTSV.ReqSizeLabel, opts.LTSV.StatusLabel, opts.LTSV.TraceIDLabel,
+	)
+	return parsers.NewLTSVParser(r, label, opts.QueryString, opts.QueryStringIgnoreValues, opts.Strict), nil
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
 
 	"github.com/tetsuzawa/alp-trace/helpers"
@@ -15,7 +16,7 @@ func defineOptions(cmd *cobra.Command) {
 	cmd.PersistentFlags().StringP("file", "", "", "The access log file")
 	cmd.PersistentFlags().StringP("dump", "", "", "Dump profiled data as YAML")
 	cmd.PersistentFlags().StringP("load", "", "", "Load the profiled YAML data")
-	cmd.PersistentFlags().StringP("format", "", options.DefaultFormatOption, "The output format (pretty, table, markdown, tsv, csv and html)")
+	cmd.PersistentFlags().StringP("format", "", options.DefaultFormatOption, "The output format (pretty, table, markdown, tsv, csv, html, openmetrics, json, ndjson, mermaid, dot, folded, pprof and chrome)")
 	cmd.PersistentFlags().StringP("sort", "", options.DefaultSortOption, "Output the results in sorted order")
 	cmd.PersistentFlags().BoolP("reverse", "r", false, "Sort results in reverse order")
 	cmd.PersistentFlags().BoolP("noheaders", "", false, "Output no header line at all (only --format=tsv, csv)")
@@ -33,6 +34,17 @@ func defineOptions(cmd *cobra.Command) {
 	cmd.PersistentFlags().StringP("percentiles", "", "", "Specifies the percentiles separated by commas")
 	cmd.PersistentFlags().IntP("page", "", options.DefaultPaginationLimit, "Number of pages of pagination")
 	cmd.PersistentFlags().BoolP("trace", "", false, "Enable tracing analysis")
+	cmd.PersistentFlags().BoolP("strict", "", false, "Abort on the lines that cannot be parsed instead of skipping them")
+	cmd.PersistentFlags().StringP("session-key", "", "", "Synthesize the trace IDs of the logs without trace ID by the log entries separated by commas (e.g. remote_addr,user_agent or http_cookie:SESSIONID)")
+	cmd.PersistentFlags().DurationP("session-gap", "", options.DefaultSessionGapOption, "Split the sessions after the inactivity gap (only use with --session-key)")
+	cmd.PersistentFlags().StringP("group-by", "", "", "Split the results by the log entries separated by commas (e.g. host,upstream_addr), not applied with --trace")
+	cmd.PersistentFlags().StringP("scenario-key", "", options.DefaultScenarioKeyOption, "The requests that identify a scenario (uri_method_status, uri_method)")
+	cmd.PersistentFlags().DurationP("bucket", "", 0, "Output the time series of the endpoints or the scenarios per the bucket (e.g. 1m) as tsv, csv or json")
+	cmd.PersistentFlags().StringP("histogram", "", "", "Output the response time histogram of the endpoints or the scenarios by the buckets (linear:start,width,count, exp:start,factor,count or the bounds separated by commas)")
+	cmd.PersistentFlags().StringP("trace-ids", "", "", "The trace IDs exported in the chrome format or extracted separated by commas (default the slowest trace of each scenario)")
+	cmd.PersistentFlags().BoolP("follow", "", false, "Keep reading the log as it grows and output the results every interval")
+	cmd.PersistentFlags().DurationP("interval", "", options.DefaultIntervalOption, "The interval to output the results (only use with --follow)")
+	cmd.PersistentFlags().DurationP("window", "", 0, "Output the results of the requests read in the last window (e.g. 1m) instead of all (only use with --follow)")
 }
 
 func createOptions(cmd *cobra.Command, sortOptions *stats.SortOptions) (*options.Options, error) {
@@ -61,6 +73,16 @@ func createOptions(cmd *cobra.Command, sortOptions *stats.SortOptions) (*options
 		return nil, err
 	}
 
+	groupBy, err := cmd.PersistentFlags().GetString("group-by")
+	if err != nil {
+		return nil, err
+	}
+
+	err = sortOptions.SetGroupBy(helpers.SplitCSV(groupBy))
+	if err != nil {
+		return nil, err
+	}
+
 	sort, err := cmd.PersistentFlags().GetString("sort")
 	if err != nil {
 		return nil, err
@@ -163,6 +185,56 @@ func createOptions(cmd *cobra.Command, sortOptions *stats.SortOptions) (*options
 		return nil, err
 	}
 
+	strict, err := cmd.PersistentFlags().GetBool("strict")
+	if err != nil {
+		return nil, err
+	}
+
+	sessionKeys, err := cmd.PersistentFlags().GetString("session-key")
+	if err != nil {
+		return nil, err
+	}
+
+	sessionGap, err := cmd.PersistentFlags().GetDuration("session-gap")
+	if err != nil {
+		return nil, err
+	}
+
+	scenarioKey, err := cmd.PersistentFlags().GetString("scenario-key")
+	if err != nil {
+		return nil, err
+	}
+
+	bucket, err := cmd.PersistentFlags().GetDuration("bucket")
+	if err != nil {
+		return nil, err
+	}
+
+	histogram, err := cmd.PersistentFlags().GetString("histogram")
+	if err != nil {
+		return nil, err
+	}
+
+	traceIDs, err := cmd.PersistentFlags().GetString("trace-ids")
+	if err != nil {
+		return nil, err
+	}
+
+	follow, err := cmd.PersistentFlags().GetBool("follow")
+	if err != nil {
+		return nil, err
+	}
+
+	interval, err := cmd.PersistentFlags().GetDuration("interval")
+	if err != nil {
+		return nil, err
+	}
+
+	window, err := cmd.PersistentFlags().GetDuration("window")
+	if err != nil {
+		return nil, err
+	}
+
 	var opts *options.Options
 	if config != "" {
 		cf, err := os.Open(config)
@@ -176,6 +248,13 @@ func createOptions(cmd *cobra.Command, sortOptions *stats.SortOptions) (*options
 			return nil, err
 		}
 
+		if groupBy == "" {
+			err = sortOptions.SetGroupBy(opts.GroupBy)
+			if err != nil {
+				return nil, err
+			}
+		}
+
 		err = sortOptions.SetAndValidate(opts.Sort)
 		if err != nil {
 			return nil, err
@@ -186,7 +265,7 @@ func createOptions(cmd *cobra.Command, sortOptions *stats.SortOptions) (*options
 		opts = options.NewOptions()
 	}
 
-	return options.SetOptions(opts,
+	opts = options.SetOptions(opts,
 		options.File(file),
 		options.Dump(dump),
 		options.Load(load),
@@ -208,5 +287,22 @@ func createOptions(cmd *cobra.Command, sortOptions *stats.SortOptions) (*options
 		options.Percentiles(percentiles),
 		options.PaginationLimit(paginationLimit),
 		options.Trace(trace),
-	), nil
+		options.Strict(strict),
+		options.CSVSessionKeys(sessionKeys),
+		options.SessionGap(sessionGap),
+		options.CSVGroupBy(groupBy),
+		options.ScenarioKey(scenarioKey),
+		options.Bucket(bucket),
+		options.Histogram(histogram),
+		options.CSVTraceIDs(traceIDs),
+		options.Follow(follow),
+		options.Interval(interval),
+		options.Window(window),
+	)
+
+	if opts.ScenarioKey != options.ScenarioKeyUriMethodStatus && opts.ScenarioKey != options.ScenarioKeyUriMethod {
+		return nil, fmt.Errorf("invalid scenario key: %s", opts.ScenarioKey)
+	}
+
+	return opts, nil
 }
@@ -1,7 +1,9 @@
 package cmd
 
 import (
+	"io"
 	"os"
+	"strconv"
 
 	"github.com/tetsuzawa/alp-trace/options"
 	"github.com/tetsuzawa/alp-trace/parsers"
@@ -18,26 +20,11 @@ func NewPcapCmd(rootCmd *cobra.Command) *cobra.Command {
 		Long:  `Profile the HTTP requests for captured packets`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			sortOptions := stats.NewSortOptions()
-			opts, err := createOptions(cmd, sortOptions)
+			opts, err := createPcapOptions(cmd, sortOptions)
 			if err != nil {
 				return err
 			}
 
-			serverIPs, err := cmd.PersistentFlags().GetStringSlice("pcap-server-ip")
-			if err != nil {
-				return err
-			}
-
-			serverPort, err := cmd.PersistentFlags().GetUint16("pcap-server-port")
-			if err != nil {
-				return err
-			}
-
-			opts = options.SetOptions(opts,
-				options.PcapServerIPs(serverIPs),
-				options.PcapServerPort(serverPort),
-			)
-
 			prof := profiler.NewProfiler(os.Stdout, os.Stderr, opts)
 
 			f, err := prof.Open(opts.File)
@@ -46,7 +33,7 @@ func NewPcapCmd(rootCmd *cobra.Command) *cobra.Command {
 			}
 			defer f.Close()
 
-			parser, err := parsers.NewPcapParser(f, opts.Pcap.ServerIPs, opts.Pcap.ServerPort, opts.QueryString, opts.QueryStringIgnoreValues)
+			parser, err := newPcapParser(opts, f)
 			if err != nil {
 				return err
 			}
@@ -58,9 +45,72 @@ func NewPcapCmd(rootCmd *cobra.Command) *cobra.Command {
 	}
 
 	defineOptions(pcapCmd)
+	pcapCmd.AddCommand(NewShowCmd(createPcapOptions, newPcapParser))
+	pcapCmd.AddCommand(NewServeCmd(createPcapOptions, newPcapParser))
+	pcapCmd.AddCommand(NewTUICmd(createPcapOptions, newPcapParser))
 
 	pcapCmd.PersistentFlags().StringSliceP("pcap-server-ip", "", []string{options.DefaultPcapServerIPsOption[0]}, "HTTP server IP address of the captured packets")
-	pcapCmd.PersistentFlags().Uint16P("pcap-server-port", "", options.DefaultPcapServerPortOption, "HTTP server TCP port of the captured packets")
+	pcapCmd.PersistentFlags().StringSliceP("pcap-server-port", "", []string{}, "HTTP server TCP ports or port ranges (e.g. 80,8080,3000-3010) of the captured packets (default 80)")
+	pcapCmd.PersistentFlags().StringSliceP("pcap-server-addr", "", []string{}, "HTTP server IP:port endpoints (e.g. 192.168.1.10:8080,[::1]:3000-3010) of the captured packets")
+	pcapCmd.PersistentFlags().StringSliceP("pcap-service", "", []string{}, "Service names mapped from the server ports or endpoints (e.g. web=80,api=8080,node=192.168.1.10:3000-3010)")
 
 	return pcapCmd
 }
+
+func createPcapOptions(cmd *cobra.Command, sortOptions *stats.SortOptions) (*options.Options, error) {
+	opts, err := createOptions(cmd, sortOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	serverIPs, err := cmd.PersistentFlags().GetStringSlice("pcap-server-ip")
+	if err != nil {
+		return nil, err
+	}
+
+	serverPorts, err := cmd.PersistentFlags().GetStringSlice("pcap-server-port")
+	if err != nil {
+		return nil, err
+	}
+
+	serverAddrs, err := cmd.PersistentFlags().GetStringSlice("pcap-server-addr")
+	if err != nil {
+		return nil, err
+	}
+
+	services, err := cmd.PersistentFlags().GetStringSlice("pcap-service")
+	if err != nil {
+		return nil, err
+	}
+
+	opts = options.SetOptions(opts,
+		options.PcapServerIPs(serverIPs),
+		options.PcapServerPorts(serverPorts),
+		options.PcapServerAddrs(serverAddrs),
+		options.PcapServices(services),
+	)
+
+	// the endpoints alone don't fall back to the default ips crossed with the default port
+	ipsSet := cmd.PersistentFlags().Changed("pcap-server-ip")
+	portsSet := len(opts.Pcap.ServerPorts) > 0 || opts.Pcap.ServerPort != options.DefaultPcapServerPortOption
+	if len(opts.Pcap.ServerAddrs) > 0 && !ipsSet && !portsSet {
+		opts.Pcap.ServerIPs = nil
+		return opts, nil
+	}
+
+	// server_port is kept for the compatibility with the older configuration files
+	if len(opts.Pcap.ServerPorts) == 0 {
+		opts.Pcap.ServerPorts = []string{strconv.Itoa(int(opts.Pcap.ServerPort))}
+	}
+
+	return opts, nil
+}
+
+func newPcapParser(opts *options.Options, r io.Reader) (parsers.Parser, error) {
+	servers, err := parsers.NewPcapServers(opts.Pcap.ServerIPs, opts.Pcap.ServerPorts, opts.Pcap.ServerAddrs, opts.Pcap.Services)
+	if err != nil {
+		return nil, err
+	}
+
+	return parsers.NewPcapParser(r, servers, opts.QueryString, opts.QueryStringIgnoreValues)
+}
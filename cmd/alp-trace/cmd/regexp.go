@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"io"
 	"os"
 
 	"github.com/tetsuzawa/alp-trace/options"
@@ -18,62 +19,11 @@ func NewRegexpCmd(rootCmd *cobra.Command) *cobra.Command {
 		Long:  `Profile the logs that match a regular expression`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			sortOptions := stats.NewSortOptions()
-			opts, err := createOptions(cmd, sortOptions)
+			opts, err := createRegexpOptions(cmd, sortOptions)
 			if err != nil {
 				return err
 			}
 
-			pattern, err := cmd.PersistentFlags().GetString("pattern")
-			if err != nil {
-				return err
-			}
-
-			uriSubexp, err := cmd.PersistentFlags().GetString("uri-subexp")
-			if err != nil {
-				return err
-			}
-
-			methodSubexp, err := cmd.PersistentFlags().GetString("method-subexp")
-			if err != nil {
-				return err
-			}
-
-			timeSubexp, err := cmd.PersistentFlags().GetString("time-subexp")
-			if err != nil {
-				return err
-			}
-
-			restimeSubexp, err := cmd.PersistentFlags().GetString("restime-subexp")
-			if err != nil {
-				return err
-			}
-
-			reqtimeSubexp, err := cmd.PersistentFlags().GetString("reqtime-subexp")
-			if err != nil {
-				return err
-			}
-
-			bodyBytesSubexp, err := cmd.PersistentFlags().GetString("body-bytes-subexp")
-			if err != nil {
-				return err
-			}
-
-			statusSubexp, err := cmd.PersistentFlags().GetString("status-subexp")
-			if err != nil {
-				return err
-			}
-
-			opts = options.SetOptions(opts,
-				options.Pattern(pattern),
-				options.UriSubexp(uriSubexp),
-				options.MethodSubexp(methodSubexp),
-				options.TimeSubexp(timeSubexp),
-				options.ResponseTimeSubexp(restimeSubexp),
-				options.RequestTimeSubexp(reqtimeSubexp),
-				options.BodyBytesSubexp(bodyBytesSubexp),
-				options.StatusSubexp(statusSubexp),
-			)
-
 			prof := profiler.NewProfiler(os.Stdout, os.Stderr, opts)
 
 			f, err := prof.Open(opts.File)
@@ -82,9 +32,7 @@ func NewRegexpCmd(rootCmd *cobra.Command) *cobra.Command {
 			}
 			defer f.Close()
 
-			names := parsers.NewSubexpNames(opts.Regexp.UriSubexp, opts.Regexp.MethodSubexp, opts.Regexp.TimeSubexp,
-				opts.Regexp.ResponseTimeSubexp, opts.Regexp.RequestTimeSubexp, opts.Regexp.BodyBytesSubexp, opts.Regexp.StatusSubexp)
-			parser, err := parsers.NewRegexpParser(f, opts.Regexp.Pattern, names, opts.QueryString, opts.QueryStringIgnoreValues)
+			parser, err := newRegexpParser(opts, f)
 			if err != nil {
 				return err
 			}
@@ -96,6 +44,10 @@ func NewRegexpCmd(rootCmd *cobra.Command) *cobra.Command {
 	}
 
 	defineOptions(regexpCmd)
+	regexpCmd.AddCommand(NewShowCmd(createRegexpOptions, newRegexpParser))
+	regexpCmd.AddCommand(NewExtractCmd(createRegexpOptions, newRegexpParser))
+	regexpCmd.AddCommand(NewServeCmd(createRegexpOptions, newRegexpParser))
+	regexpCmd.AddCommand(NewTUICmd(createRegexpOptions, newRegexpParser))
 
 	regexpCmd.PersistentFlags().StringP("pattern", "", options.DefaultPatternOption, "Regular expressions pattern matching the log")
 	regexpCmd.PersistentFlags().StringP("uri-subexp", "", options.DefaultUriSubexpOption, "Change the uri sub expression")
@@ -104,7 +56,87 @@ func NewRegexpCmd(rootCmd *cobra.Command) *cobra.Command {
 	regexpCmd.PersistentFlags().StringP("restime-subexp", "", options.DefaultResponseTimeSubexpOption, "Change the response_time sub expression")
 	regexpCmd.PersistentFlags().StringP("reqtime-subexp", "", options.DefaultRequestTimeSubexpOption, "Change the request_time sub expression")
 	regexpCmd.PersistentFlags().StringP("body-bytes-subexp", "", options.DefaultBodyBytesSubexpOption, "Change the body_bytes sub expression")
+	regexpCmd.PersistentFlags().StringP("req-body-bytes-subexp", "", options.DefaultReqBodyBytesSubexpOption, "Change the request_body_bytes sub expression")
 	regexpCmd.PersistentFlags().StringP("status-subexp", "", options.DefaultStatusSubexpOption, "Change the status sub expression")
+	regexpCmd.PersistentFlags().StringP("traceid-subexp", "", options.DefaultTraceIDSubexpOption, "Change the trace_id sub expression")
 
 	return regexpCmd
 }
+
+func createRegexpOptions(cmd *cobra.Command, sortOptions *stats.SortOptions) (*options.Options, error) {
+	opts, err := createOptions(cmd, sortOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	pattern, err := cmd.PersistentFlags().GetString("pattern")
+	if err != nil {
+		return nil, err
+	}
+
+	uriSubexp, err := cmd.PersistentFlags().GetString("uri-subexp")
+	if err != nil {
+		return nil, err
+	}
+
+	methodSubexp, err := cmd.PersistentFlags().GetString("method-subexp")
+	if err != nil {
+		return nil, err
+	}
+
+	timeSubexp, err := cmd.PersistentFlags().GetString("time-subexp")
+	if err != nil {
+		return nil, err
+	}
+
+	restimeSubexp, err := cmd.PersistentFlags().GetString("restime-subexp")
+	if err != nil {
+		return nil, err
+	}
+
+	reqtimeSubexp, err := cmd.PersistentFlags().GetString("reqtime-subexp")
+	if err != nil {
+		return nil, err
+	}
+
+	bodyBytesSubexp, err := cmd.PersistentFlags().GetString("body-bytes-subexp")
+	if err != nil {
+		return nil, err
+	}
+
+	reqBodyBytesSubexp, err := cmd.PersistentFlags().GetString("req-body-bytes-subexp")
+	if err != nil {
+		return nil, err
+	}
+
+	statusSubexp, err := cmd.PersistentFlags().GetString("status-subexp")
+	if err != nil {
+		return nil, err
+	}
+
+	traceIDSubexp, err := cmd.PersistentFlags().GetString("traceid-subexp")
+	if err != nil {
+		return nil, err
+	}
+
+	opts = options.SetOptions(opts,
+		options.Pattern(pattern),
+		options.UriSubexp(uriSubexp),
+		options.MethodSubexp(methodSubexp),
+		options.TimeSubexp(timeSubexp),
+		options.ResponseTimeSubexp(restimeSubexp),
+		options.RequestTimeSubexp(reqtimeSubexp),
+		options.BodyBytesSubexp(bodyBytesSubexp),
+		options.ReqBodyBytesSubexp(reqBodyBytesSubexp),
+		options.StatusSubexp(statusSubexp),
+		options.TraceIDSubexp(traceIDSubexp),
+	)
+
+	return opts, nil
+}
+
+func newRegexpParser(opts *options.Options, r io.Reader) (parsers.Parser, error) {
+	names := parsers.NewSubexpNames(opts.Regexp.UriSubexp, opts.Regexp.MethodSubexp, opts.Regexp.TimeSubexp,
+		opts.Regexp.ResponseTimeSubexp, opts.Regexp.RequestTimeSubexp, opts.Regexp.BodyBytesSubexp, opts.Regexp.ReqBodyBytesSubexp, opts.Regexp.StatusSubexp, opts.Regexp.TraceIDSubexp)
+	return parsers.NewRegexpParser(r, opts.Regexp.Pattern, names, opts.QueryString, opts.QueryStringIgnoreValues, opts.Strict)
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tetsuzawa/alp-trace/profiler"
+	"github.com/tetsuzawa/alp-trace/stats"
+)
+
+const defaultListenAddr = "127.0.0.1:8080"
+
+// NewServeCmd makes the serve subcommand of a log format, it reads the log with the flags of the parent command
+func NewServeCmd(createOptions createOptionsFunc, newParser newParserFunc) *cobra.Command {
+	var serveCmd = &cobra.Command{
+		Use:   "serve",
+		Short: "Serve the profiles of the endpoints and the scenarios over HTTP",
+		Long:  `Serve the dashboard and the JSON API of the profiles of the endpoints and the scenarios`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sortOptions := stats.NewSortOptions()
+			opts, err := createOptions(cmd.Parent(), sortOptions)
+			if err != nil {
+				return err
+			}
+
+			listen, err := cmd.PersistentFlags().GetString("listen")
+			if err != nil {
+				return err
+			}
+
+			prof := profiler.NewProfiler(os.Stdout, os.Stderr, opts)
+
+			f, err := prof.Open(opts.File)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			parser, err := newParser(opts, f)
+			if err != nil {
+				return err
+			}
+
+			err = prof.Serve(listen, sortOptions, parser)
+
+			return err
+		},
+	}
+
+	serveCmd.PersistentFlags().StringP("listen", "", defaultListenAddr, "The address to listen on")
+
+	return serveCmd
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tetsuzawa/alp-trace/options"
+	"github.com/tetsuzawa/alp-trace/parsers"
+	"github.com/tetsuzawa/alp-trace/profiler"
+	"github.com/tetsuzawa/alp-trace/stats"
+)
+
+type createOptionsFunc func(cmd *cobra.Command, sortOptions *stats.SortOptions) (*options.Options, error)
+
+type newParserFunc func(opts *options.Options, r io.Reader) (parsers.Parser, error)
+
+// NewShowCmd makes the show subcommand of a log format, it reads the log with the flags of the parent command
+func NewShowCmd(createOptions createOptionsFunc, newParser newParserFunc) *cobra.Command {
+	var showCmd = &cobra.Command{
+		Use:   "show <trace-id>...",
+		Short: "Show the waterfall of the requests of the traces",
+		Long:  `Show the waterfall of the requests of the traces`,
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sortOptions := stats.NewSortOptions()
+			opts, err := createOptions(cmd.Parent(), sortOptions)
+			if err != nil {
+				return err
+			}
+
+			// the log is read to the end
+			opts.Follow = false
+
+			prof := profiler.NewProfiler(os.Stdout, os.Stderr, opts)
+
+			f, err := prof.Open(opts.File)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			parser, err := newParser(opts, f)
+			if err != nil {
+				return err
+			}
+
+			err = prof.Show(parser, args)
+
+			return err
+		},
+	}
+
+	return showCmd
+}
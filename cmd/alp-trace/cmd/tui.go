@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tetsuzawa/alp-trace/profiler"
+	"github.com/tetsuzawa/alp-trace/stats"
+)
+
+// NewTUICmd makes the tui subcommand of a log format, it reads the log with the flags of the parent command
+func NewTUICmd(createOptions createOptionsFunc, newParser newParserFunc) *cobra.Command {
+	var tuiCmd = &cobra.Command{
+		Use:   "tui",
+		Short: "Explore the endpoints and the scenarios on the terminal",
+		Long:  `Explore the endpoints and the scenarios on the terminal with sorting, filtering and the details of the scenarios`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sortOptions := stats.NewSortOptions()
+			opts, err := createOptions(cmd.Parent(), sortOptions)
+			if err != nil {
+				return err
+			}
+
+			// the log is read to the end
+			opts.Follow = false
+
+			prof := profiler.NewProfiler(os.Stdout, os.Stderr, opts)
+
+			f, err := prof.Open(opts.File)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			parser, err := newParser(opts, f)
+			if err != nil {
+				return err
+			}
+
+			err = prof.Explore(sortOptions, parser)
+
+			return err
+		},
+	}
+
+	return tuiCmd
+}
@@ -62,7 +62,7 @@ Loop:
 		if err != nil {
 			if err == io.EOF {
 				break
-			} else if err == errors.SkipReadLineErr {
+			} else if errors.IsSkipReadLine(err) {
 				continue Loop
 			}
 
@@ -199,7 +199,7 @@ func (p *Printer) printHTML(groups) {
 			}
 		}
 	}
-	content, _ := html.RenderTableWithGridJS("alp", p.headers, data, p.printOptions.paginationLimit)
+	content, _ := html.RenderTable("alp", p.headers, data, p.printOptions.paginationLimit)
 	fmt.Println(content)
 }
 */
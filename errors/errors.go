@@ -1,7 +1,36 @@
 package errors
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	SkipReadLineErr = errors.New("Skip read line")
 )
+
+// SkipReadLineError is a SkipReadLineErr with the reason why the line was skipped
+type SkipReadLineError struct {
+	Reason string
+	Err    error
+}
+
+func (e *SkipReadLineError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("%s: %s", SkipReadLineErr, e.Reason)
+	}
+
+	return fmt.Sprintf("%s: %s: %s", SkipReadLineErr, e.Reason, e.Err)
+}
+
+func (e *SkipReadLineError) Is(target error) bool {
+	return target == SkipReadLineErr
+}
+
+func (e *SkipReadLineError) Unwrap() error {
+	return e.Err
+}
+
+func IsSkipReadLine(err error) bool {
+	return errors.Is(err, SkipReadLineErr)
+}
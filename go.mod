@@ -4,23 +4,24 @@ require (
 	github.com/Songmu/go-ltsv v0.1.0
 	github.com/antonmedv/expr v1.8.9
 	github.com/google/gopacket v1.1.19
+	github.com/google/pprof v0.0.0-20211214055906-6f57359322fd
 	github.com/kylelemons/godebug v1.1.0
+	github.com/mattn/go-runewidth v0.0.13
 	github.com/olekukonko/tablewriter v0.0.5
 	github.com/spaolacci/murmur3 v1.1.0
 	github.com/spf13/cobra v1.7.0
 	github.com/tkuchiki/parsetime v0.0.0-20210726130428-dd24a7b526ea
+	golang.org/x/sys v0.5.0
 	gopkg.in/yaml.v2 v2.4.0
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
-	github.com/mattn/go-runewidth v0.0.13 // indirect
 	github.com/rivo/uniseg v0.2.0 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
 	github.com/tkuchiki/go-timezone v0.2.2 // indirect
 	golang.org/x/net v0.7.0 // indirect
-	golang.org/x/sys v0.5.0 // indirect
 	gopkg.in/check.v1 v1.0.0-20180628173108-788fd7840127 // indirect
 )
 
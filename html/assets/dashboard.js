@@ -0,0 +1,154 @@
+// alpDashboard fetches the endpoints or the scenarios from the api with the parameters of the form,
+// and renders them by alpTable, the scenarios can be expanded to their requests
+(function () {
+  "use strict";
+
+  // the number of the trace IDs shown in a scenario
+  var traceIDsLimit = 10;
+
+  function round(v) {
+    return Math.round(v * 1000) / 1000;
+  }
+
+  function summaryColumns(percentiles) {
+    var columns = ["Min", "Max", "Sum", "Avg"];
+    percentiles.forEach(function (p) {
+      columns.push("P" + p);
+    });
+    columns.push("Stddev");
+    return columns;
+  }
+
+  function summaryValues(summary, percentiles) {
+    var values = [round(summary.min), round(summary.max), round(summary.sum), round(summary.avg)];
+    percentiles.forEach(function (p) {
+      var v = summary.percentiles ? summary.percentiles["p" + p] : undefined;
+      values.push(v === undefined ? "" : round(v));
+    });
+    values.push(summary.stddev === undefined ? "" : round(summary.stddev));
+    return values;
+  }
+
+  function statusValues(status) {
+    return [status["1xx"], status["2xx"], status["3xx"], status["4xx"], status["5xx"]];
+  }
+
+  function endpointsTable(report) {
+    var groups = [];
+    report.endpoints.forEach(function (e) {
+      Object.keys(e.groups || {}).forEach(function (g) {
+        if (groups.indexOf(g) < 0) {
+          groups.push(g);
+        }
+      });
+    });
+
+    var columns = ["Count"]
+      .concat(statusColumns())
+      .concat(["Method", "Uri"])
+      .concat(groups)
+      .concat(summaryColumns(report.percentiles))
+      .concat(["Min(Body)", "Max(Body)", "Sum(Body)", "Avg(Body)"]);
+
+    var rows = report.endpoints.map(function (e) {
+      var body = e.response_body_bytes;
+      return [e.count]
+        .concat(statusValues(e.status))
+        .concat([e.method, e.uri])
+        .concat(groups.map(function (g) {
+          return (e.groups || {})[g] || "";
+        }))
+        .concat(summaryValues(e.response_time, report.percentiles))
+        .concat([round(body.min), round(body.max), round(body.sum), round(body.avg)]);
+    });
+
+    return { columns: columns, rows: rows, details: null };
+  }
+
+  function scenariosTable(report) {
+    var columns = ["Scenario ID", "Count", "Error Rate", "Requests"]
+      .concat(statusColumns())
+      .concat(summaryColumns(report.percentiles))
+      .concat(["Avg(Body)"]);
+
+    var stepColumns = ["Method", "Uri", "Status", "Count", "Error Rate"]
+      .concat(summaryColumns(report.percentiles))
+      .concat(["Avg(Body)"]);
+
+    var rows = [];
+    var details = [];
+    report.scenarios.forEach(function (s) {
+      rows.push([s.id, s.count, round(s.error_rate), s.steps.length]
+        .concat(statusValues(s.status))
+        .concat(summaryValues(s.response_time, report.percentiles))
+        .concat([round(s.response_body_bytes.avg)]));
+
+      details.push({
+        columns: stepColumns,
+        rows: s.steps.map(function (step) {
+          return [step.method, step.uri, step.status_code || "*", step.count, round(step.error_rate)]
+            .concat(summaryValues(step.response_time, report.percentiles))
+            .concat([round(step.response_body_bytes.avg)]);
+        }),
+        trace_ids: (s.trace_ids || []).slice(0, traceIDsLimit),
+      });
+    });
+
+    return { columns: columns, rows: rows, details: details };
+  }
+
+  function statusColumns() {
+    return ["1xx", "2xx", "3xx", "4xx", "5xx"];
+  }
+
+  function alpDashboard(form, container, message, options) {
+    options = options || {};
+
+    function load() {
+      var params = [];
+      Array.prototype.forEach.call(form.elements, function (el) {
+        if (!el.name || el.name === "view") {
+          return;
+        }
+        if (el.type === "checkbox") {
+          params.push(el.name + "=" + el.checked);
+        } else if (el.value !== "") {
+          params.push(encodeURIComponent(el.name) + "=" + encodeURIComponent(el.value));
+        }
+      });
+      var view = form.elements.view.value;
+
+      message.textContent = "Loading...";
+      fetch("api/" + view + "?" + params.join("&"))
+        .then(function (res) {
+          return res.text().then(function (text) {
+            if (!res.ok) {
+              throw new Error(text);
+            }
+            return JSON.parse(text);
+          });
+        })
+        .then(function (report) {
+          var t = view === "scenarios" ? scenariosTable(report) : endpointsTable(report);
+          container.textContent = "";
+          alpTable(container, t.columns, t.rows, { details: t.details, pageLimit: options.pageLimit });
+          message.textContent = t.rows.length + " " + view + " at " + new Date().toLocaleTimeString();
+        })
+        .catch(function (err) {
+          message.textContent = err.message;
+        });
+    }
+
+    form.addEventListener("submit", function (e) {
+      e.preventDefault();
+      load();
+    });
+    form.elements.view.forEach(function (el) {
+      el.addEventListener("change", load);
+    });
+
+    load();
+  }
+
+  window.alpDashboard = alpDashboard;
+})();
@@ -0,0 +1,182 @@
+// alpTable renders a sortable and searchable table without any dependencies,
+// the rows that have the details can be expanded to the nested table
+(function () {
+  "use strict";
+
+  function compare(a, b) {
+    if (typeof a === "number" && typeof b === "number") {
+      return a - b;
+    }
+    return String(a).localeCompare(String(b));
+  }
+
+  function element(tag, className, text) {
+    var el = document.createElement(tag);
+    if (className) {
+      el.className = className;
+    }
+    if (text !== undefined) {
+      el.textContent = text;
+    }
+    return el;
+  }
+
+  function alpTable(container, columns, rows, options) {
+    options = options || {};
+    var details = options.details || null;
+    var pageLimit = options.pageLimit || 0;
+    var state = { column: -1, desc: false, query: "", page: 0, open: {} };
+
+    var table = element("table", "alp-table");
+    var pager = element("div", "alp-pager");
+
+    if (options.search !== false) {
+      var search = element("input", "alp-search");
+      search.type = "search";
+      search.placeholder = "Search...";
+      search.addEventListener("input", function () {
+        state.query = search.value.toLowerCase();
+        state.page = 0;
+        render();
+      });
+      container.appendChild(search);
+    }
+    container.appendChild(table);
+    container.appendChild(pager);
+
+    function visibleRows() {
+      var indexes = [];
+      for (var i = 0; i < rows.length; i++) {
+        if (matches(i)) {
+          indexes.push(i);
+        }
+      }
+
+      if (state.column >= 0) {
+        indexes.sort(function (a, b) {
+          var c = compare(rows[a][state.column], rows[b][state.column]);
+          return state.desc ? -c : c;
+        });
+      }
+      return indexes;
+    }
+
+    function matches(i) {
+      if (!state.query) {
+        return true;
+      }
+      return rows[i].some(function (v) {
+        return String(v).toLowerCase().indexOf(state.query) >= 0;
+      });
+    }
+
+    function renderHeader() {
+      var tr = element("tr");
+      if (details) {
+        tr.appendChild(element("th"));
+      }
+      columns.forEach(function (column, c) {
+        var mark = "";
+        if (state.column === c) {
+          mark = state.desc ? " ▼" : " ▲";
+        }
+        var th = element("th", "", column + mark);
+        th.addEventListener("click", function () {
+          if (state.column === c) {
+            state.desc = !state.desc;
+          } else {
+            state.column = c;
+            state.desc = false;
+          }
+          render();
+        });
+        tr.appendChild(th);
+      });
+
+      var thead = element("thead");
+      thead.appendChild(tr);
+      return thead;
+    }
+
+    function renderDetail(detail) {
+      var div = element("div", "alp-detail");
+      alpTable(div, detail.columns, detail.rows, { search: false });
+      if (detail.trace_ids && detail.trace_ids.length > 0) {
+        div.appendChild(element("p", "alp-trace-ids", "Trace IDs: " + detail.trace_ids.join(", ")));
+      }
+      return div;
+    }
+
+    function renderRow(tbody, i) {
+      var tr = element("tr");
+      if (details) {
+        tr.className = "alp-expandable";
+        tr.appendChild(element("td", "alp-toggle", state.open[i] ? "▾" : "▸"));
+        tr.addEventListener("click", function () {
+          state.open[i] = !state.open[i];
+          render();
+        });
+      }
+      rows[i].forEach(function (v) {
+        tr.appendChild(element("td", typeof v === "number" ? "alp-number" : "", v));
+      });
+      tbody.appendChild(tr);
+
+      if (details && details[i] && state.open[i]) {
+        var detailRow = element("tr");
+        var td = element("td");
+        td.colSpan = columns.length + 1;
+        td.appendChild(renderDetail(details[i]));
+        detailRow.appendChild(td);
+        tbody.appendChild(detailRow);
+      }
+    }
+
+    function renderPager(total, pages) {
+      pager.textContent = "";
+      if (pageLimit <= 0 || pages <= 1) {
+        return;
+      }
+
+      var prev = element("button", "", "Previous");
+      prev.disabled = state.page === 0;
+      prev.addEventListener("click", function () {
+        state.page--;
+        render();
+      });
+      var next = element("button", "", "Next");
+      next.disabled = state.page >= pages - 1;
+      next.addEventListener("click", function () {
+        state.page++;
+        render();
+      });
+
+      pager.appendChild(prev);
+      pager.appendChild(element("span", "", "Page " + (state.page + 1) + " of " + pages + " (" + total + " rows)"));
+      pager.appendChild(next);
+    }
+
+    function render() {
+      var indexes = visibleRows();
+      var pages = 1;
+      if (pageLimit > 0) {
+        pages = Math.max(1, Math.ceil(indexes.length / pageLimit));
+        state.page = Math.min(state.page, pages - 1);
+        indexes = indexes.slice(state.page * pageLimit, (state.page + 1) * pageLimit);
+      }
+
+      table.textContent = "";
+      table.appendChild(renderHeader());
+      var tbody = element("tbody");
+      indexes.forEach(function (i) {
+        renderRow(tbody, i);
+      });
+      table.appendChild(tbody);
+      renderPager(visibleRows().length, pages);
+    }
+
+    render();
+  }
+
+  window.alpTable = alpTable;
+})();
@@ -0,0 +1,98 @@
+package html
+
+import (
+	"bytes"
+	"text/template"
+)
+
+const tplDashboard = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="UTF-8">
+<title>{{.Title}}</title>
+<style>
+{{ .CSS }}
+.alp-controls {
+  display: flex;
+  flex-wrap: wrap;
+  gap: 12px;
+  align-items: center;
+  margin-bottom: 12px;
+}
+.alp-controls input[type="text"] {
+  width: 240px;
+  padding: 4px 6px;
+}
+.alp-message {
+  margin-bottom: 8px;
+  color: #6b7280;
+}
+</style>
+<script>
+{{ .TableJS }}
+</script>
+<script>
+{{ .DashboardJS }}
+</script>
+</head>
+<body>
+<form id="controls" class="alp-controls">
+  <label><input type="radio" name="view" value="endpoints" checked> Endpoints</label>
+  <label><input type="radio" name="view" value="scenarios"> Scenarios</label>
+  <label>Sort <input type="text" name="sort" placeholder="count, sum, p95, ..." style="width: 120px"></label>
+  <label><input type="checkbox" name="reverse"> Reverse</label>
+  <label>Limit <input type="number" name="limit" min="1" style="width: 64px"></label>
+  <label>Matching groups <input type="text" name="matching_groups" placeholder="/users/.+,/items/.+"></label>
+  <label>Filters <input type="text" name="filters" placeholder="Status >= 500"></label>
+  <button type="submit">Reload</button>
+</form>
+<div id="message" class="alp-message"></div>
+<div id="tableContainer"></div>
+<script>
+alpDashboard(document.getElementById("controls"), document.getElementById("tableContainer"), document.getElementById("message"), {
+  pageLimit: {{ .PageLimit }},
+});
+</script>
+</body>
+</html>`
+
+// RenderDashboard renders the page that shows the profiles fetched from the api of the serve command
+func RenderDashboard(title string, paginationLimit int) (string, error) {
+	t, err := template.New("dashboard").Parse(tplDashboard)
+	if err != nil {
+		return "", err
+	}
+
+	css, err := assets.ReadFile("assets/table.css")
+	if err != nil {
+		return "", err
+	}
+
+	tableJS, err := assets.ReadFile("assets/table.js")
+	if err != nil {
+		return "", err
+	}
+
+	dashboardJS, err := assets.ReadFile("assets/dashboard.js")
+	if err != nil {
+		return "", err
+	}
+
+	data := struct {
+		Title       string
+		CSS         string
+		TableJS     string
+		DashboardJS string
+		PageLimit   int
+	}{
+		Title:       title,
+		CSS:         string(css),
+		TableJS:     string(tableJS),
+		DashboardJS: string(dashboardJS),
+		PageLimit:   paginationLimit,
+	}
+
+	var buf bytes.Buffer
+	err = t.Execute(&buf, data)
+	return buf.String(), err
+}
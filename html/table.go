@@ -2,40 +2,37 @@ package html
 
 import (
 	"bytes"
+	"embed"
 	"encoding/json"
-	"fmt"
+	"math"
 	"strconv"
-	"strings"
 	"text/template"
 )
 
-const tplTableWithGridJS = `<!DOCTYPE html>
+// the assets are inlined, so that the report can be opened without network access
+//
+//go:embed assets
+var assets embed.FS
+
+const tplTable = `<!DOCTYPE html>
 <html>
 <head>
 <meta charset="UTF-8">
 <title>{{.Title}}</title>
-<link href="https://unpkg.com/gridjs/dist/theme/mermaid.min.css" rel="stylesheet" />
-<script src="https://unpkg.com/gridjs/dist/gridjs.umd.js"></script>
+<style>
+{{ .CSS }}
+</style>
+<script>
+{{ .JS }}
+</script>
 </head>
 <body>
-<div id="gridContainer"></div>
+<div id="tableContainer"></div>
 <script>
-new gridjs.Grid({
-  {{ .Pagination }}
-  columns: [{{range .Columns}}"{{ . }}",{{end}}],
-  autoWidth: true,
-  style: {
-    table: {
-      'width': '100%'
-    }
-  },
-  data: {{ .Rows }},
-  resizable: true,
-  sort: {
-    multiColumn: true
-  },
-  search: true,
-}).render(document.getElementById("gridContainer"));
+alpTable(document.getElementById("tableContainer"), {{ .Columns }}, {{ .Rows }}, {
+  details: {{ .Details }},
+  pageLimit: {{ .PageLimit }},
+});
 </script>
 </body>
 </html>`
@@ -45,59 +42,106 @@ type ColumnValue struct {
 	Value      string
 }
 
-func RenderTableWithGridJS(title string, columns []string, rows [][]string, paginationLimit int) (string, error) {
-	var pagination string
-	if paginationLimit > 0 {
-		pagination = fmt.Sprintf(`pagination: { limit: %d },`, paginationLimit)
-	}
-	funcMap := template.FuncMap{
-		"escape": func(val string) string { return strings.Replace(val, `"`, `\"`, -1) },
+// Detail is the nested table that is shown by expanding a row
+type Detail struct {
+	Columns  []string   `json:"columns"`
+	Rows     [][]string `json:"-"`
+	TraceIDs []string   `json:"trace_ids,omitempty"`
+}
+
+func (d *Detail) MarshalJSON() ([]byte, error) {
+	type detail Detail
+	return json.Marshal(&struct {
+		*detail
+		Rows [][]interface{} `json:"rows"`
+	}{
+		detail: (*detail)(d),
+		Rows:   columnValues(d.Rows),
+	})
+}
+
+func RenderTable(title string, columns []string, rows [][]string, paginationLimit int) (string, error) {
+	return RenderTableWithDetails(title, columns, rows, nil, paginationLimit)
+}
+
+// RenderTableWithDetails renders the table whose rows can be expanded to the details of the same index
+func RenderTableWithDetails(title string, columns []string, rows [][]string, details []*Detail, paginationLimit int) (string, error) {
+	t, err := template.New("query stats").Parse(tplTable)
+	if err != nil {
+		return "", err
 	}
 
-	t, err := template.New("query stats").Funcs(funcMap).Parse(tplTableWithGridJS)
+	css, err := assets.ReadFile("assets/table.css")
 	if err != nil {
 		return "", err
 	}
 
-	var columnValues [][]interface{}
-	for _, row := range rows {
-		var values []interface{}
-		for _, col := range row {
-			vf, err := strconv.ParseFloat(col, 64)
-			if err == nil {
-				values = append(values, vf)
-				continue
-			}
+	js, err := assets.ReadFile("assets/table.js")
+	if err != nil {
+		return "", err
+	}
 
-			vi, err := strconv.ParseInt(col, 10, 64)
-			if err == nil {
-				values = append(values, vi)
-				continue
-			}
+	c, err := json.Marshal(columns)
+	if err != nil {
+		return "", err
+	}
 
-			values = append(values, col)
-		}
-		columnValues = append(columnValues, values)
+	r, err := json.Marshal(columnValues(rows))
+	if err != nil {
+		return "", err
 	}
 
-	s, err := json.Marshal(columnValues)
+	d, err := json.Marshal(details)
 	if err != nil {
 		return "", err
 	}
 
 	data := struct {
-		Title      string
-		Columns    []string
-		Rows       string
-		Pagination string
+		Title     string
+		CSS       string
+		JS        string
+		Columns   string
+		Rows      string
+		Details   string
+		PageLimit int
 	}{
-		Title:      title,
-		Columns:    columns,
-		Rows:       string(s),
-		Pagination: pagination,
+		Title:     title,
+		CSS:       string(css),
+		JS:        string(js),
+		Columns:   string(c),
+		Rows:      string(r),
+		Details:   string(d),
+		PageLimit: paginationLimit,
 	}
 
 	var buf bytes.Buffer
 	err = t.Execute(&buf, data)
 	return buf.String(), err
 }
+
+// columnValues converts the numbers, so that they are sorted numerically
+func columnValues(rows [][]string) [][]interface{} {
+	values := make([][]interface{}, 0, len(rows))
+	for _, row := range rows {
+		var vs []interface{}
+		for _, col := range row {
+			vf, err := strconv.ParseFloat(col, 64)
+			// NaN and Inf cannot be encoded to json
+			if err == nil && !math.IsNaN(vf) && !math.IsInf(vf, 0) {
+				vs = append(vs, vf)
+				continue
+			}
+
+			vi, err := strconv.ParseInt(col, 10, 64)
+			if err == nil {
+				vs = append(vs, vi)
+				continue
+			}
+
+			vs = append(vs, col)
+		}
+		values = append(values, vs)
+	}
+
+	return values
+}
@@ -3,6 +3,7 @@ package options
 import (
 	"io"
 	"net"
+	"time"
 
 	"github.com/tetsuzawa/alp-trace/helpers"
 	"gopkg.in/yaml.v2"
@@ -20,6 +21,7 @@ const (
 	DefaultReqtimeLabelOption = "reqtime"
 	DefaultStatusLabelOption  = "status"
 	DefaultSizeLabelOption    = "size"
+	DefaultReqSizeLabelOption = "reqsize"
 	DefaultMethodLabelOption  = "method"
 	DefaultUriLabelOption     = "uri"
 	DefaultTimeLabelOption    = "time"
@@ -31,10 +33,11 @@ const (
 	DefaultResponseTimeKeyOption = "response_time"
 	DefaultRequestTimeKeyOption  = "request_time"
 	DefaultBodyBytesKeyOption    = "body_bytes"
+	DefaultReqBodyBytesKeyOption = "request_body_bytes"
 	DefaultStatusKeyOption       = "status"
 	DefaultTraceIDKeyOption      = "trace_id"
 	// regexp
-	DefaultPatternOption = `^(\S+)\s` + // remote host
+	DefaultPatternOption = `^(?P<remote_addr>\S+)\s` + // remote host
 		`\S+\s+` +
 		`(\S+\s+)+` + // user
 		`\[(?P<time>[^]]+)\]\s` + // time
@@ -44,7 +47,7 @@ const (
 		`(?P<status>\S+)\s` + // status code
 		`(?P<body_bytes>\S+)\s` + // bytes
 		`"((?:[^"]*(?:\\")?)*)"\s` + // referer
-		`"(?:.+)"` + // user agent
+		`"(?P<user_agent>.+)"` + // user agent
 		`\s(?P<response_time>\S+)(?:\s(?P<request_time>\S+))?$`
 	DefaultUriSubexpOption          = "uri"
 	DefaultMethodSubexpOption       = "method"
@@ -52,9 +55,19 @@ const (
 	DefaultResponseTimeSubexpOption = "response_time"
 	DefaultRequestTimeSubexpOption  = "request_time"
 	DefaultBodyBytesSubexpOption    = "body_bytes"
+	DefaultReqBodyBytesSubexpOption = "request_body_bytes"
 	DefaultStatusSubexpOption       = "status"
+	DefaultTraceIDSubexpOption      = "trace_id"
 	// pcap
 	DefaultPcapServerPortOption = 80
+	// session
+	DefaultSessionGapOption = 30 * time.Minute
+	// trace
+	ScenarioKeyUriMethodStatus = "uri_method_status"
+	ScenarioKeyUriMethod       = "uri_method"
+	DefaultScenarioKeyOption   = ScenarioKeyUriMethodStatus
+	// follow
+	DefaultIntervalOption = 5 * time.Second
 )
 
 var DefaultPercentilesOption = []int{90, 95, 99}
@@ -64,7 +77,7 @@ var DefaultPcapServerIPsOption = getDefaultPcapServerIPsOption()
 type Options struct {
 	File                    string         `yaml:"file"`
 	Dump                    string         `yaml:"dump"`
-	Load                    string         `yaml:"dump"`
+	Load                    string         `yaml:"load"`
 	Sort                    string         `yaml:"sort"`
 	Reverse                 bool           `yaml:"reverse"`
 	QueryString             bool           `yaml:"query_string"`
@@ -83,6 +96,17 @@ type Options struct {
 	Percentiles             []int          `yaml:"percentiles"`
 	PaginationLimit         int            `yaml:"pagination_limit"`
 	Trace                   bool           `yaml:"trace"`
+	Strict                  bool           `yaml:"strict"`
+	SessionKeys             []string       `yaml:"session_keys"`
+	SessionGap              time.Duration  `yaml:"session_gap"`
+	GroupBy                 []string       `yaml:"group_by"`
+	ScenarioKey             string         `yaml:"scenario_key"`
+	Bucket                  time.Duration  `yaml:"bucket"`
+	Histogram               string         `yaml:"histogram"`
+	TraceIDs                []string       `yaml:"trace_ids"`
+	Follow                  bool           `yaml:"follow"`
+	Interval                time.Duration  `yaml:"interval"`
+	Window                  time.Duration  `yaml:"window"`
 	LTSV                    *LTSVOptions   `yaml:"ltsv"`
 	Regexp                  *RegexpOptions `yaml:"regexp"`
 	JSON                    *JSONOptions   `yaml:"json"`
@@ -94,6 +118,7 @@ type LTSVOptions struct {
 	ReqtimeLabel string `yaml:"reqtime_label"`
 	StatusLabel  string `yaml:"status_label"`
 	SizeLabel    string `yaml:"size_label"`
+	ReqSizeLabel string `yaml:"req_size_label"`
 	MethodLabel  string `yaml:"method_label"`
 	UriLabel     string `yaml:"uri_label"`
 	TimeLabel    string `yaml:"time_label"`
@@ -108,7 +133,9 @@ type RegexpOptions struct {
 	ResponseTimeSubexp string `yaml:"response_time_subexp"`
 	RequestTimeSubexp  string `yaml:"request_time_subexp"`
 	BodyBytesSubexp    string `yaml:"body_bytes_subexp"`
+	ReqBodyBytesSubexp string `yaml:"req_body_bytes_subexp"`
 	StatusSubexp       string `yaml:"status_subexp"`
+	TraceIDSubexp      string `yaml:"trace_id_subexp"`
 }
 
 type JSONOptions struct {
@@ -118,13 +145,17 @@ type JSONOptions struct {
 	ResponseTimeKey string `yaml:"response_time_key"`
 	RequestTimeKey  string `yaml:"request_time_key"`
 	BodyBytesKey    string `yaml:"body_bytes_key"`
+	ReqBodyBytesKey string `yaml:"req_body_bytes_key"`
 	StatusKey       string `yaml:"status_key"`
 	TraceIDKey      string `yaml:"trace_id_key"`
 }
 
 type PcapOptions struct {
-	ServerIPs  []string `yaml:"server_ips"`
-	ServerPort uint16   `yaml:"server_port"`
+	ServerIPs   []string `yaml:"server_ips"`
+	ServerPort  uint16   `yaml:"server_port"`
+	ServerPorts []string `yaml:"server_ports"`
+	ServerAddrs []string `yaml:"server_addrs"`
+	Services    []string `yaml:"services"`
 }
 
 type Option func(*Options)
@@ -306,6 +337,121 @@ func Trace(b bool) Option {
 	}
 }
 
+func Strict(b bool) Option {
+	return func(opts *Options) {
+		if b {
+			opts.Strict = b
+		}
+	}
+}
+
+func SessionKeys(values []string) Option {
+	return func(opts *Options) {
+		if len(values) > 0 {
+			opts.SessionKeys = values
+		}
+	}
+}
+
+func CSVSessionKeys(csv string) Option {
+	return func(opts *Options) {
+		a := helpers.SplitCSV(csv)
+		if len(a) > 0 {
+			opts.SessionKeys = a
+		}
+	}
+}
+
+func SessionGap(d time.Duration) Option {
+	return func(opts *Options) {
+		if d > 0 {
+			opts.SessionGap = d
+		}
+	}
+}
+
+func GroupBy(values []string) Option {
+	return func(opts *Options) {
+		if len(values) > 0 {
+			opts.GroupBy = values
+		}
+	}
+}
+
+func CSVGroupBy(csv string) Option {
+	return func(opts *Options) {
+		a := helpers.SplitCSV(csv)
+		if len(a) > 0 {
+			opts.GroupBy = a
+		}
+	}
+}
+
+func ScenarioKey(s string) Option {
+	return func(opts *Options) {
+		if s != "" {
+			opts.ScenarioKey = s
+		}
+	}
+}
+
+func Bucket(d time.Duration) Option {
+	return func(opts *Options) {
+		if d > 0 {
+			opts.Bucket = d
+		}
+	}
+}
+
+func Histogram(s string) Option {
+	return func(opts *Options) {
+		if s != "" {
+			opts.Histogram = s
+		}
+	}
+}
+
+func TraceIDs(values []string) Option {
+	return func(opts *Options) {
+		if len(values) > 0 {
+			opts.TraceIDs = values
+		}
+	}
+}
+
+func CSVTraceIDs(csv string) Option {
+	return func(opts *Options) {
+		a := helpers.SplitCSV(csv)
+		if len(a) > 0 {
+			opts.TraceIDs = a
+		}
+	}
+}
+
+func Follow(b bool) Option {
+	return func(opts *Options) {
+		if b {
+			opts.Follow = b
+		}
+	}
+}
+
+func Interval(d time.Duration) Option {
+	return func(opts *Options) {
+		if d > 0 {
+			opts.Interval = d
+		}
+	}
+}
+
+func Window(d time.Duration) Option {
+	return func(opts *Options) {
+		if d > 0 {
+			opts.Window = d
+		}
+	}
+}
+
 // ltsv
 func ApptimeLabel(s string) Option {
 	return func(opts *Options) {
@@ -347,6 +493,14 @@ func SizeLabel(s string) Option {
 	}
 }
 
+func ReqSizeLabel(s string) Option {
+	return func(opts *Options) {
+		if s != "" {
+			opts.LTSV.ReqSizeLabel = s
+		}
+	}
+}
+
 func MethodLabel(s string) Option {
 	return func(opts *Options) {
 		if s != "" {
@@ -428,6 +582,14 @@ func BodyBytesSubexp(s string) Option {
 	}
 }
 
+func ReqBodyBytesSubexp(s string) Option {
+	return func(opts *Options) {
+		if s != "" {
+			opts.Regexp.ReqBodyBytesSubexp = s
+		}
+	}
+}
+
 func StatusSubexp(s string) Option {
 	return func(opts *Options) {
 		if s != "" {
@@ -436,6 +598,14 @@ func StatusSubexp(s string) Option {
 	}
 }
 
+func TraceIDSubexp(s string) Option {
+	return func(opts *Options) {
+		if s != "" {
+			opts.Regexp.TraceIDSubexp = s
+		}
+	}
+}
+
 // json
 func UriKey(s string) Option {
 	return func(opts *Options) {
@@ -485,6 +655,14 @@ func BodyBytesKey(s string) Option {
 	}
 }
 
+func ReqBodyBytesKey(s string) Option {
+	return func(opts *Options) {
+		if s != "" {
+			opts.JSON.ReqBodyBytesKey = s
+		}
+	}
+}
+
 func StatusKey(s string) Option {
 	return func(opts *Options) {
 		if s != "" {
@@ -518,15 +696,41 @@ func PcapServerPort(n uint16) Option {
 	}
 }
 
+func PcapServerPorts(ss []string) Option {
+	return func(opts *Options) {
+		if len(ss) > 0 {
+			opts.Pcap.ServerPorts = ss
+		}
+	}
+}
+
+func PcapServerAddrs(ss []string) Option {
+	return func(opts *Options) {
+		if len(ss) > 0 {
+			opts.Pcap.ServerAddrs = ss
+		}
+	}
+}
+
+func PcapServices(ss []string) Option {
+	return func(opts *Options) {
+		if len(ss) > 0 {
+			opts.Pcap.Services = ss
+		}
+	}
+}
+
 func NewOptions(opt ...Option) *Options {
 	ltsv := &LTSVOptions{
 		ApptimeLabel: DefaultApptimeLabelOption,
 		ReqtimeLabel: DefaultReqtimeLabelOption,
 		StatusLabel:  DefaultStatusLabelOption,
 		SizeLabel:    DefaultSizeLabelOption,
+		ReqSizeLabel: DefaultReqSizeLabelOption,
 		MethodLabel:  DefaultMethodLabelOption,
 		UriLabel:     DefaultUriLabelOption,
 		TimeLabel:    DefaultTimeLabelOption,
+		TraceIDLabel: DefaultTraceIDLabelOption,
 	}
 
 	regexp := &RegexpOptions{
@@ -537,7 +741,9 @@ func NewOptions(opt ...Option) *Options {
 		ResponseTimeSubexp: DefaultResponseTimeSubexpOption,
 		RequestTimeSubexp:  DefaultRequestTimeSubexpOption,
 		BodyBytesSubexp:    DefaultBodyBytesSubexpOption,
+		ReqBodyBytesSubexp: DefaultReqBodyBytesSubexpOption,
 		StatusSubexp:       DefaultStatusSubexpOption,
+		TraceIDSubexp:      DefaultTraceIDSubexpOption,
 	}
 
 	json := &JSONOptions{
@@ -547,7 +753,9 @@ func NewOptions(opt ...Option) *Options {
 		ResponseTimeKey: DefaultResponseTimeKeyOption,
 		RequestTimeKey:  DefaultRequestTimeKeyOption,
 		BodyBytesKey:    DefaultBodyBytesKeyOption,
+		ReqBodyBytesKey: DefaultReqBodyBytesKeyOption,
 		StatusKey:       DefaultStatusKeyOption,
+		TraceIDKey:      DefaultTraceIDKeyOption,
 	}
 
 	pcap := &PcapOptions{
@@ -563,6 +771,9 @@ func NewOptions(opt ...Option) *Options {
 		Output:          DefaultOutputOption,
 		Percentiles:     DefaultPercentilesOption,
 		PaginationLimit: DefaultPaginationLimit,
+		SessionGap:      DefaultSessionGapOption,
+		ScenarioKey:     DefaultScenarioKeyOption,
+		Interval:        DefaultIntervalOption,
 		LTSV:            ltsv,
 		Regexp:          regexp,
 		JSON:            json,
@@ -616,34 +827,54 @@ func LoadOptionsFromReader(r io.Reader) (*Options, error) {
 		Percentiles(configs.Percentiles),
 		PaginationLimit(configs.PaginationLimit),
 		Trace(configs.Trace),
+		Strict(configs.Strict),
+		SessionKeys(configs.SessionKeys),
+		SessionGap(configs.SessionGap),
+		GroupBy(configs.GroupBy),
+		ScenarioKey(configs.ScenarioKey),
+		Bucket(configs.Bucket),
+		Histogram(configs.Histogram),
+		TraceIDs(configs.TraceIDs),
+		Follow(configs.Follow),
+		Interval(configs.Interval),
+		Window(configs.Window),
 		// ltsv
 		ApptimeLabel(configs.LTSV.ApptimeLabel),
 		ReqtimeLabel(configs.LTSV.ReqtimeLabel),
 		StatusLabel(configs.LTSV.StatusLabel),
 		SizeLabel(configs.LTSV.SizeLabel),
+		ReqSizeLabel(configs.LTSV.ReqSizeLabel),
 		MethodLabel(configs.LTSV.MethodLabel),
 		UriLabel(configs.LTSV.UriLabel),
 		TimeLabel(configs.LTSV.TimeLabel),
+		TraceIDLabel(configs.LTSV.TraceIDLabel),
 		// json
 		ResponseTimeKey(configs.JSON.ResponseTimeKey),
 		RequestTimeKey(configs.JSON.RequestTimeKey),
 		StatusKey(configs.JSON.StatusKey),
 		BodyBytesKey(configs.JSON.BodyBytesKey),
+		ReqBodyBytesKey(configs.JSON.ReqBodyBytesKey),
 		MethodKey(configs.JSON.MethodKey),
 		UriKey(configs.JSON.UriKey),
 		TimeKey(configs.JSON.TimeKey),
+		TraceIDKey(configs.JSON.TraceIDKey),
 		// regexp
 		Pattern(configs.Regexp.Pattern),
 		ResponseTimeSubexp(configs.Regexp.ResponseTimeSubexp),
 		RequestTimeSubexp(configs.Regexp.RequestTimeSubexp),
 		StatusSubexp(configs.Regexp.StatusSubexp),
 		BodyBytesSubexp(configs.Regexp.BodyBytesSubexp),
+		ReqBodyBytesSubexp(configs.Regexp.ReqBodyBytesSubexp),
 		MethodSubexp(configs.Regexp.MethodSubexp),
 		UriSubexp(configs.Regexp.UriSubexp),
 		TimeSubexp(configs.Regexp.TimeSubexp),
+		TraceIDSubexp(configs.Regexp.TraceIDSubexp),
 		// pcap
 		PcapServerIPs(configs.Pcap.ServerIPs),
 		PcapServerPort(configs.Pcap.ServerPort),
+		PcapServerPorts(configs.Pcap.ServerPorts),
+		PcapServerAddrs(configs.Pcap.ServerAddrs),
+		PcapServices(configs.Pcap.Services),
 	)
 
 	return opts, err
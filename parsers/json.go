@@ -2,9 +2,12 @@ package parsers
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
+	"strconv"
+	"strings"
 )
 
 type JSONParser struct {
@@ -16,7 +19,7 @@ type JSONParser struct {
 	readBytes      int
 }
 
-func NewJSONKeys(uri, method, time, responseTime, requestTime, size, status, traceID string) *statKeys {
+func NewJSONKeys(uri, method, time, responseTime, requestTime, size, reqSize, status, traceID string) *statKeys {
 	return newStatKeys(
 		uriKey(uri),
 		methodKey(method),
@@ -24,15 +27,17 @@ func NewJSONKeys(uri, method, time, responseTime, requestTime, size, status, tra
 		responseTimeKey(responseTime),
 		requestTimeKey(requestTime),
 		bodyBytesKey(size),
+		requestBodyBytesKey(reqSize),
 		statusKey(status),
 		traceIDKey(traceID),
 	)
 }
 
-func NewJSONParser(r io.Reader, keys *statKeys, query, qsIgnoreValues bool) Parser {
+func NewJSONParser(r io.Reader, keys *statKeys, query, qsIgnoreValues, strictMode bool) Parser {
 	return &JSONParser{
 		reader:         bufio.NewReader(r),
 		keys:           keys,
+		strictMode:     strictMode,
 		queryString:    query,
 		qsIgnoreValues: qsIgnoreValues,
 	}
@@ -46,12 +51,14 @@ func (j *JSONParser) Parse() (*ParsedHTTPStat, error) {
 	j.readBytes += i
 
 	var tmp map[string]interface{}
-	err = json.Unmarshal(b, &tmp)
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	err = d.Decode(&tmp)
 	if err != nil {
-		return nil, err
+		return nil, errSkipReadLine(j.strictMode, reasonInvalidJSON, err)
 	}
 
-	keys := make([]string, 8)
+	keys := make([]string, 9)
 	keys = []string{
 		j.keys.uri,
 		j.keys.method,
@@ -59,17 +66,18 @@ func (j *JSONParser) Parse() (*ParsedHTTPStat, error) {
 		j.keys.responseTime,
 		j.keys.requestTime,
 		j.keys.bodyBytes,
+		j.keys.requestBodyBytes,
 		j.keys.status,
 		j.keys.traceID,
 	}
-	parsedValue := make(map[string]string, 8)
+	parsedValue := make(map[string]string, 9)
 	for _, key := range keys {
-		val, ok := tmp[key]
+		val, ok := lookupJSONPath(tmp, key)
 		if !ok {
 			continue
 		}
 
-		parsedValue[key] = fmt.Sprintf("%v", val)
+		parsedValue[key] = jsonValueToString(val)
 	}
 
 	parsedHTTPStat, err := toStats(parsedValue, j.keys, j.strictMode, j.queryString, j.qsIgnoreValues)
@@ -78,11 +86,10 @@ func (j *JSONParser) Parse() (*ParsedHTTPStat, error) {
 	}
 
 	logEntries := make(LogEntries)
-	for key, val := range tmp {
-		logEntries[key] = fmt.Sprintf("%v", val)
-	}
+	flattenJSON(logEntries, "", tmp)
 
 	parsedHTTPStat.Entries = logEntries
+	parsedHTTPStat.Raw = b
 
 	return parsedHTTPStat, nil
 }
@@ -99,3 +106,83 @@ func (j *JSONParser) Seek(n int) error {
 	_, err := j.reader.Discard(n)
 	return err
 }
+
+// lookupJSONPath looks up the value by the top-level key, the dotted path (e.g. request.uri)
+// or the JSON Pointer (e.g. /request/uri)
+func lookupJSONPath(obj map[string]interface{}, path string) (interface{}, bool) {
+	if val, ok := obj[path]; ok {
+		return val, true
+	}
+
+	var tokens []string
+	if strings.HasPrefix(path, "/") {
+		tokens = strings.Split(path[1:], "/")
+		for i, token := range tokens {
+			tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+		}
+	} else {
+		tokens = strings.Split(path, ".")
+	}
+
+	var val interface{} = obj
+	for _, token := range tokens {
+		switch v := val.(type) {
+		case map[string]interface{}:
+			child, ok := v[token]
+			if !ok {
+				return nil, false
+			}
+			val = child
+		case []interface{}:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+			val = v[i]
+		default:
+			return nil, false
+		}
+	}
+
+	return val, true
+}
+
+func jsonValueToString(val interface{}) string {
+	switch v := val.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	case map[string]interface{}, []interface{}:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(b)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// flattenJSON stores the leaves of the nested objects with their dotted path names
+func flattenJSON(entries LogEntries, prefix string, val interface{}) {
+	switch v := val.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			flattenJSON(entries, key, child)
+		}
+	case []interface{}:
+		for i, child := range v {
+			flattenJSON(entries, fmt.Sprintf("%s.%d", prefix, i), child)
+		}
+	default:
+		entries[prefix] = jsonValueToString(v)
+	}
+}
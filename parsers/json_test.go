@@ -0,0 +1,114 @@
+package parsers
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func decodeJSON(t *testing.T, s string) map[string]interface{} {
+	t.Helper()
+
+	var obj map[string]interface{}
+	d := json.NewDecoder(bytes.NewReader([]byte(s)))
+	d.UseNumber()
+	if err := d.Decode(&obj); err != nil {
+		t.Fatal(err)
+	}
+
+	return obj
+}
+
+func TestLookupJSONPath(t *testing.T) {
+	obj := decodeJSON(t, `{
+		"uri": "/top",
+		"request.uri": "/dotted",
+		"request": {"uri": "/nested", "method": "GET", "headers": {"x-trace-id": "abc"}},
+		"upstreams": [{"time": 0.1}, {"time": 0.2}],
+		"a/b": {"~c": 1},
+		"status": 200,
+		"cached": false,
+		"referer": null
+	}`)
+
+	tests := []struct {
+		path   string
+		want   string
+		wantOK bool
+	}{
+		{path: "uri", want: "/top", wantOK: true},
+		{path: "request.uri", want: "/dotted", wantOK: true},
+		{path: "/request/uri", want: "/nested", wantOK: true},
+		{path: "request.headers.x-trace-id", want: "abc", wantOK: true},
+		{path: "upstreams.1.time", want: "0.2", wantOK: true},
+		{path: "/upstreams/0/time", want: "0.1", wantOK: true},
+		{path: "/a~1b/~0c", want: "1", wantOK: true},
+		{path: "status", want: "200", wantOK: true},
+		{path: "cached", want: "false", wantOK: true},
+		{path: "referer", want: "", wantOK: true},
+		{path: "request.headers", want: `{"x-trace-id":"abc"}`, wantOK: true},
+		{path: "request.status", wantOK: false},
+		{path: "upstreams.2.time", wantOK: false},
+		{path: "upstreams.-1.time", wantOK: false},
+		{path: "upstreams.first", wantOK: false},
+		{path: "status.code", wantOK: false},
+		{path: "/missing", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			val, ok := lookupJSONPath(obj, tt.path)
+			if ok != tt.wantOK {
+				t.Fatalf("want ok: %v, got: %v", tt.wantOK, ok)
+			}
+			if !ok {
+				return
+			}
+
+			if got := jsonValueToString(val); got != tt.want {
+				t.Errorf("want: %q, got: %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFlattenJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want LogEntries
+	}{
+		{
+			name: "flat",
+			json: `{"uri": "/a", "status": 200}`,
+			want: LogEntries{"uri": "/a", "status": "200"},
+		},
+		{
+			name: "nested",
+			json: `{"request": {"uri": "/a", "headers": {"host": "example.com"}}, "cached": true}`,
+			want: LogEntries{"request.uri": "/a", "request.headers.host": "example.com", "cached": "true"},
+		},
+		{
+			name: "array",
+			json: `{"upstreams": [{"addr": "10.0.0.1"}, "10.0.0.2"], "empty": []}`,
+			want: LogEntries{"upstreams.0.addr": "10.0.0.1", "upstreams.1": "10.0.0.2"},
+		},
+		{
+			name: "null",
+			json: `{"referer": null}`,
+			want: LogEntries{"referer": ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make(LogEntries)
+			flattenJSON(got, "", decodeJSON(t, tt.json))
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("want: %v, got: %v", tt.want, got)
+			}
+		})
+	}
+}
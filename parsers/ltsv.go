@@ -16,7 +16,7 @@ type LTSVParser struct {
 	readBytes      int
 }
 
-func NewLTSVLabel(uri, method, time, responseTime, requestTime, size, status, traceID string) *statKeys {
+func NewLTSVLabel(uri, method, time, responseTime, requestTime, size, reqSize, status, traceID string) *statKeys {
 	return newStatKeys(
 		uriKey(uri),
 		methodKey(method),
@@ -24,15 +24,17 @@ func NewLTSVLabel(uri, method, time, responseTime, requestTime, size, status, tr
 		responseTimeKey(responseTime),
 		requestTimeKey(requestTime),
 		bodyBytesKey(size),
+		requestBodyBytesKey(reqSize),
 		statusKey(status),
 		traceIDKey(traceID),
 	)
 }
 
-func NewLTSVParser(r io.Reader, l *statKeys, query, qsIgnoreValues bool) Parser {
+func NewLTSVParser(r io.Reader, l *statKeys, query, qsIgnoreValues, strictMode bool) Parser {
 	return &LTSVParser{
 		reader:         bufio.NewReader(r),
 		label:          l,
+		strictMode:     strictMode,
 		queryString:    query,
 		qsIgnoreValues: qsIgnoreValues,
 	}
@@ -48,7 +50,7 @@ func (l *LTSVParser) Parse() (*ParsedHTTPStat, error) {
 	parsedValue := make(map[string]string, 0)
 	err2 := ltsv.Unmarshal(b, &parsedValue)
 	if err2 != nil && l.strictMode {
-		return nil, err
+		return nil, errSkipReadLine(l.strictMode, reasonInvalidLTSV, err2)
 	}
 
 	parsedHTTPStat, err := toStats(parsedValue, l.label, l.strictMode, l.queryString, l.qsIgnoreValues)
@@ -60,6 +62,7 @@ func (l *LTSVParser) Parse() (*ParsedHTTPStat, error) {
 	logEntries = parsedValue
 
 	parsedHTTPStat.Entries = logEntries
+	parsedHTTPStat.Raw = b
 
 	return parsedHTTPStat, nil
 }
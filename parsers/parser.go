@@ -3,8 +3,10 @@ package parsers
 import (
 	"bufio"
 	"bytes"
+	"fmt"
 	"io"
 	"net/url"
+	"strings"
 
 	"github.com/tetsuzawa/alp-trace/errors"
 	"github.com/tetsuzawa/alp-trace/helpers"
@@ -18,27 +20,65 @@ type Parser interface {
 }
 
 type ParsedHTTPStat struct {
-	Uri          string
-	Method       string
-	Time         string
-	ResponseTime float64
-	BodyBytes    float64
-	Status       int
-	TraceID      string
-	Entries      LogEntries
+	Uri              string
+	Method           string
+	Time             string
+	ResponseTime     float64
+	BodyBytes        float64
+	RequestBodyBytes float64 // 0 if the log has no request body size
+	Status           int
+	TraceID          string
+	Service          string
+	TCP              *TCPMetrics
+	TimeBreakdown    *TimeBreakdown
+	Entries          LogEntries
+	Raw              []byte // the line of the log without the line break, nil for pcap
+}
+
+// TimeBreakdown holds both the upstream response time and the total request time,
+// only available if the log has both of them (e.g. nginx $upstream_response_time and $request_time)
+type TimeBreakdown struct {
+	UpstreamTime float64
+	RequestTime  float64
+}
+
+// TCPMetrics holds the network level timings of a request, only available for pcap
+type TCPMetrics struct {
+	HandshakeRTT    float64 // 0 if the request was sent over a reused connection
+	FirstByteTime   float64
+	LastByteTime    float64
+	Retransmissions int
+	Reused          bool
 }
 
 type LogEntries map[string]string
 
+// the reasons why the lines are skipped
+const (
+	reasonInvalidURI          = "uri parse failure"
+	reasonInvalidResponseTime = "non-numeric response time"
+	reasonInvalidBodyBytes    = "non-numeric body bytes"
+	reasonInvalidReqBodyBytes = "non-numeric request body bytes"
+	reasonInvalidStatus       = "non-numeric status"
+	reasonPatternNotMatched   = "regex mismatch"
+	reasonInvalidJSON         = "invalid json"
+	reasonInvalidLTSV         = "invalid ltsv"
+)
+
+// ReasonMissingTraceID is the reason why the line without trace ID is skipped in the trace mode,
+// the parsers keep the line and the profiler skips it
+const ReasonMissingTraceID = "missing trace ID"
+
 type statKeys struct {
-	uri          string
-	method       string
-	time         string
-	responseTime string
-	requestTime  string
-	bodyBytes    string
-	status       string
-	traceID      string
+	uri              string
+	method           string
+	time             string
+	responseTime     string
+	requestTime      string
+	bodyBytes        string
+	requestBodyBytes string
+	status           string
+	traceID          string
 }
 
 type statKey func(*statKeys)
@@ -91,6 +131,14 @@ func bodyBytesKey(s string) statKey {
 	}
 }
 
+func requestBodyBytesKey(s string) statKey {
+	return func(sk *statKeys) {
+		if s != "" {
+			sk.requestBodyBytes = s
+		}
+	}
+}
+
 func statusKey(s string) statKey {
 	return func(sk *statKeys) {
 		if s != "" {
@@ -173,41 +221,87 @@ func NewParsedHTTPStat(uri, method, time string, resTime, bodyBytes float64, sta
 func toStats(parsedValue map[string]string, keys *statKeys, strictMode, queryString, qsIgnoreValues bool) (*ParsedHTTPStat, error) {
 	u, err := url.Parse(parsedValue[keys.uri])
 	if err != nil {
-		return nil, errSkipReadLine(strictMode, err)
+		return nil, errSkipReadLine(strictMode, reasonInvalidURI, err)
 	}
 
 	uri := normalizeURL(u, queryString, qsIgnoreValues)
 	if uri == "" {
-		return nil, errSkipReadLine(strictMode, err)
+		return nil, errSkipReadLine(strictMode, reasonInvalidURI, fmt.Errorf("empty uri"))
 	}
 
-	resTime, err := helpers.StringToFloat64(parsedValue[keys.responseTime])
+	var timeBreakdown *TimeBreakdown
+	resTime, err := parseUpstreamTime(parsedValue[keys.responseTime])
+	reqTime, reqTimeErr := helpers.StringToFloat64(parsedValue[keys.requestTime])
 	if err != nil {
-		resTime, err = helpers.StringToFloat64(parsedValue[keys.requestTime])
-		if err != nil {
-			return nil, errSkipReadLine(strictMode, err)
+		if reqTimeErr != nil {
+			return nil, errSkipReadLine(strictMode, reasonInvalidResponseTime, reqTimeErr)
+		}
+		resTime = reqTime
+	} else if reqTimeErr == nil {
+		timeBreakdown = &TimeBreakdown{
+			UpstreamTime: resTime,
+			RequestTime:  reqTime,
 		}
 	}
 
 	bodyBytes, err := helpers.StringToFloat64(parsedValue[keys.bodyBytes])
 	if err != nil {
-		return nil, errSkipReadLine(strictMode, err)
+		return nil, errSkipReadLine(strictMode, reasonInvalidBodyBytes, err)
 	}
 
 	status, err := helpers.StringToInt(parsedValue[keys.status])
 	if err != nil {
-		return nil, errSkipReadLine(strictMode, err)
+		return nil, errSkipReadLine(strictMode, reasonInvalidStatus, err)
 	}
 
-	traceID := parsedValue[keys.traceID]
-	if traceID == "" {
-		return nil, errSkipReadLine(strictMode, err)
+	var reqBodyBytes float64
+	if v := parsedValue[keys.requestBodyBytes]; v != "" && v != "-" {
+		reqBodyBytes, err = helpers.StringToFloat64(v)
+		if err != nil {
+			return nil, errSkipReadLine(strictMode, reasonInvalidReqBodyBytes, err)
+		}
 	}
 
+	traceID := parsedValue[keys.traceID]
+
 	method := parsedValue[keys.method]
 	timestr := parsedValue[keys.time]
 
-	return NewParsedHTTPStat(uri, method, timestr, resTime, bodyBytes, status, traceID), nil
+	stat := NewParsedHTTPStat(uri, method, timestr, resTime, bodyBytes, status, traceID)
+	stat.RequestBodyBytes = reqBodyBytes
+	stat.TimeBreakdown = timeBreakdown
+
+	return stat, nil
+}
+
+// parseUpstreamTime sums the times of the upstreams,
+// nginx logs them separated by commas and colons if the request is passed to multiple servers (e.g. "0.010, 0.020 : 0.005")
+func parseUpstreamTime(s string) (float64, error) {
+	if !strings.ContainsAny(s, ",:") {
+		return helpers.StringToFloat64(s)
+	}
+
+	var sum float64
+	found := false
+	for _, v := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ':' }) {
+		v = strings.TrimSpace(v)
+		if v == "-" {
+			continue
+		}
+
+		t, err := helpers.StringToFloat64(v)
+		if err != nil {
+			return 0, err
+		}
+		sum += t
+		found = true
+	}
+
+	if !found {
+		return 0, fmt.Errorf("no upstream time: %s", s)
+	}
+
+	return sum, nil
 }
 
 func normalizeURL(src *url.URL, queryString, qsIgnoreValues bool) string {
@@ -233,10 +327,13 @@ func normalizeURL(src *url.URL, queryString, qsIgnoreValues bool) string {
 	return u.String()
 }
 
-func errSkipReadLine(strictMode bool, err error) error {
+func errSkipReadLine(strictMode bool, reason string, err error) error {
 	if strictMode {
-		return err
+		return fmt.Errorf("%s: %w", reason, err)
 	}
 
-	return errors.SkipReadLineErr
+	return &errors.SkipReadLineError{
+		Reason: reason,
+		Err:    err,
+	}
 }
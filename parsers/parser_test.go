@@ -0,0 +1,43 @@
+package parsers
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseUpstreamTime(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    float64
+		wantErr bool
+	}{
+		{s: "0.010", want: 0.010},
+		{s: "0.010, 0.020", want: 0.030},
+		{s: "0.010 : 0.005", want: 0.015},
+		{s: "0.010, 0.020 : 0.005", want: 0.035},
+		{s: "-, 0.020", want: 0.020},
+		{s: "0.010, - : 0.005", want: 0.015},
+		{s: "-", wantErr: true},
+		{s: "- : -", wantErr: true},
+		{s: "", wantErr: true},
+		{s: "0.010, abc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, err := parseUpstreamTime(tt.s)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("want error, got: %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("want: %v, got: %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -6,12 +6,13 @@ import (
 	"encoding/base64"
 	"encoding/binary"
 	"errors"
-	"fmt"
 	"io"
 	"log"
 	"math"
 	"net"
 	"net/http"
+	"sort"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -24,41 +25,35 @@ import (
 )
 
 const (
-	conjoinPcapKeyHeader   = "Internal-ALP-Pcap-Conjoin-Key"
-	timestampPcapKeyHeader = "Internal-ALP-Pcap-Timestamp-Key"
+	conjoinPcapKeyHeader      = "Internal-ALP-Pcap-Conjoin-Key"
+	timestampPcapKeyHeader    = "Internal-ALP-Pcap-Timestamp-Key"
+	endTimestampPcapKeyHeader = "Internal-ALP-Pcap-End-Timestamp-Key"
+	connSeqPcapKeyHeader      = "Internal-ALP-Pcap-Conn-Seq-Key"
+	servicePcapKeyHeader      = "Internal-ALP-Pcap-Service-Key"
 )
 
 type PcapParser struct {
 	queryString    bool
 	qsIgnoreValues bool
+	conns          *pcapTCPConnTracker
 
 	resCh chan *http.Response // conjoined *http.Response
 }
 
-func NewPcapParser(r io.Reader, rawServerIPs []string, serverPort uint16, query, qsIgnoreValues bool) (Parser, error) {
+func NewPcapParser(r io.Reader, servers *PcapServers, query, qsIgnoreValues bool) (Parser, error) {
 	h, err := pcapgo.NewReader(r)
 	if err != nil {
 		return nil, err
 	}
 
-	serverIPs := make([]net.IP, len(rawServerIPs))
-	for i, rawServerIP := range rawServerIPs {
-		serverIP := net.ParseIP(rawServerIP)
-		if serverIP == nil {
-			return nil, fmt.Errorf("failed to parse ip: %s", rawServerIP)
-		}
-
-		serverIPs[i] = serverIP
-	}
-
 	reqCh := make(chan *http.Request)
 	resCh := make(chan *http.Response)
+	sf := newPcapHttpStreamFactory(reqCh, resCh, servers)
 	go func() {
 		ps := gopacket.NewPacketSource(h, h.LinkType())
-		sf := newPcapHttpStreamFactory(reqCh, resCh, serverIPs, serverPort)
 		sp := tcpassembly.NewStreamPool(sf)
 		asmblr := tcpassembly.NewAssembler(sp)
-		readAndAssembleAllPackets(ps, asmblr)
+		readAndAssembleAllPackets(ps, asmblr, sf)
 		sf.gracefulShutdown()
 	}()
 
@@ -68,6 +63,7 @@ func NewPcapParser(r io.Reader, rawServerIPs []string, serverPort uint16, query,
 	return &PcapParser{
 		queryString:    query,
 		qsIgnoreValues: qsIgnoreValues,
+		conns:          sf.conns,
 
 		resCh: conjoinedResCh,
 	}, nil
@@ -96,9 +92,45 @@ func (j *PcapParser) Parse() (*ParsedHTTPStat, error) {
 	resBodyBytes := res.ContentLength
 	// TODO trace IDに対応する
 	stat := NewParsedHTTPStat(uri, req.Method, reqTimestamp.Format(time.RFC3339), math.Abs(resTime.Seconds()), float64(resBodyBytes), res.StatusCode, "")
+	stat.TCP = j.tcpMetrics(req, res, reqTimestamp, resTimestamp)
+	stat.Service = req.Header.Get(servicePcapKeyHeader)
+	if req.ContentLength > 0 {
+		stat.RequestBodyBytes = float64(req.ContentLength)
+	}
+	remoteAddr, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		remoteAddr = req.RemoteAddr
+	}
+	stat.Entries = LogEntries{
+		"host":        req.Host,
+		"remote_addr": remoteAddr,
+		"user_agent":  req.UserAgent(),
+	}
 	return stat, nil
 }
 
+func (j *PcapParser) tcpMetrics(req *http.Request, res *http.Response, reqTimestamp, resTimestamp time.Time) *TCPMetrics {
+	resEndTimestamp, err := unixNanoStrToTime(res.Header.Get(endTimestampPcapKeyHeader))
+	if err != nil || resEndTimestamp.Before(resTimestamp) {
+		resEndTimestamp = resTimestamp
+	}
+
+	connSeq, _ := strconv.Atoi(req.Header.Get(connSeqPcapKeyHeader))
+	handshakeRTT, retransmissions := j.conns.metrics(req.RemoteAddr, reqTimestamp, resEndTimestamp)
+
+	m := &TCPMetrics{
+		FirstByteTime:   math.Abs(resTimestamp.Sub(reqTimestamp).Seconds()),
+		LastByteTime:    math.Abs(resEndTimestamp.Sub(reqTimestamp).Seconds()),
+		Retransmissions: retransmissions,
+		Reused:          connSeq > 0,
+	}
+	if !m.Reused {
+		m.HandshakeRTT = handshakeRTT.Seconds()
+	}
+
+	return m
+}
+
 func (j *PcapParser) ReadBytes() int {
 	return 0
 }
@@ -157,20 +189,20 @@ func (s *pcapHttpStreamStat) waitForCompleteAll() {
 }
 
 type pcapHttpStreamFactory struct {
-	reqCh      chan *http.Request
-	resCh      chan *http.Response
-	serverIPs  []net.IP
-	serverPort uint16
+	reqCh   chan *http.Request
+	resCh   chan *http.Response
+	servers *PcapServers
+	conns   *pcapTCPConnTracker
 
 	stat pcapHttpStreamStat
 }
 
-func newPcapHttpStreamFactory(reqCh chan *http.Request, resCh chan *http.Response, serverIPs []net.IP, serverPort uint16) *pcapHttpStreamFactory {
+func newPcapHttpStreamFactory(reqCh chan *http.Request, resCh chan *http.Response, servers *PcapServers) *pcapHttpStreamFactory {
 	f := &pcapHttpStreamFactory{
-		reqCh:      reqCh,
-		resCh:      resCh,
-		serverIPs:  serverIPs,
-		serverPort: serverPort,
+		reqCh:   reqCh,
+		resCh:   resCh,
+		servers: servers,
+		conns:   newPcapTCPConnTracker(),
 	}
 	f.stat.waiting.Store(false)
 	f.stat.cond = sync.NewCond(&sync.Mutex{})
@@ -180,21 +212,22 @@ func newPcapHttpStreamFactory(reqCh chan *http.Request, resCh chan *http.Respons
 func (h *pcapHttpStreamFactory) New(nf, tf gopacket.Flow) tcpassembly.Stream {
 	rs := newTCPReaderStream()
 
-	clientAddr, isReq, unknown := h.detectTrafficDirection(nf, tf)
+	clientAddr, serverAddr, isReq, unknown := h.detectTrafficDirection(nf, tf)
 	if unknown {
-		go tcpreader.DiscardBytesToEOF(&rs)
-		return &rs
+		go tcpreader.DiscardBytesToEOF(rs)
+		return rs
 	}
 
 	if isReq {
-		go parseHTTPRequest(&rs, clientAddr, h.reqCh, &h.stat)
+		go parseHTTPRequest(rs, clientAddr, h.servers.service(serverAddr), h.reqCh, &h.stat)
 	} else {
-		go parseHTTPResponse(&rs, clientAddr, h.resCh, &h.stat)
+		rs.trackOffsets = true
+		go parseHTTPResponse(rs, clientAddr, h.resCh, &h.stat)
 	}
-	return &rs
+	return rs
 }
 
-func (h *pcapHttpStreamFactory) detectTrafficDirection(nf, tf gopacket.Flow) (clientAddr *net.TCPAddr, isReq bool, unknown bool) {
+func (h *pcapHttpStreamFactory) detectTrafficDirection(nf, tf gopacket.Flow) (clientAddr, serverAddr *net.TCPAddr, isReq bool, unknown bool) {
 	if nf.EndpointType() != layers.EndpointIPv4 && nf.EndpointType() != layers.EndpointIPv6 {
 		unknown = true
 		return
@@ -208,35 +241,44 @@ func (h *pcapHttpStreamFactory) detectTrafficDirection(nf, tf gopacket.Flow) (cl
 	srcPort := binary.BigEndian.Uint16(tf.Src().Raw())
 	dstIP := net.IP(nf.Dst().Raw())
 	dstPort := binary.BigEndian.Uint16(tf.Dst().Raw())
-	for _, serverIP := range h.serverIPs {
-		if srcIP.Equal(serverIP) && srcPort == h.serverPort {
-			clientAddr = &net.TCPAddr{
-				IP:   dstIP,
-				Port: int(dstPort),
-			}
-			isReq = false
-			return
-		} else if dstIP.Equal(serverIP) && dstPort == h.serverPort {
-			clientAddr = &net.TCPAddr{
-				IP:   srcIP,
-				Port: int(srcPort),
-			}
-			isReq = true
-			return
-		}
+	srcAddr := &net.TCPAddr{
+		IP:   srcIP,
+		Port: int(srcPort),
+	}
+	dstAddr := &net.TCPAddr{
+		IP:   dstIP,
+		Port: int(dstPort),
+	}
+	if h.servers.match(srcIP, srcPort) {
+		clientAddr, serverAddr = dstAddr, srcAddr
+		isReq = false
+		return
+	} else if h.servers.match(dstIP, dstPort) {
+		clientAddr, serverAddr = srcAddr, dstAddr
+		isReq = true
+		return
 	}
 
 	unknown = true
 	return
 }
 
+func (h *pcapHttpStreamFactory) observePacket(nf gopacket.Flow, tcp *layers.TCP, timestamp time.Time) {
+	clientAddr, _, isReq, unknown := h.detectTrafficDirection(nf, tcp.TransportFlow())
+	if unknown {
+		return
+	}
+
+	h.conns.observe(clientAddr.String(), isReq, tcp, timestamp)
+}
+
 func (h *pcapHttpStreamFactory) gracefulShutdown() {
 	h.stat.waitForCompleteAll()
 	close(h.reqCh)
 	close(h.resCh)
 }
 
-func readAndAssembleAllPackets(packetSource *gopacket.PacketSource, assembler *tcpassembly.Assembler) {
+func readAndAssembleAllPackets(packetSource *gopacket.PacketSource, assembler *tcpassembly.Assembler, factory *pcapHttpStreamFactory) {
 	defer assembler.FlushAll()
 	for {
 		p, err := packetSource.NextPacket()
@@ -253,6 +295,7 @@ func readAndAssembleAllPackets(packetSource *gopacket.PacketSource, assembler *t
 		}
 
 		tcp := p.TransportLayer().(*layers.TCP)
+		factory.observePacket(p.NetworkLayer().NetworkFlow(), tcp, p.Metadata().Timestamp)
 		assembler.AssembleWithTimestamp(p.NetworkLayer().NetworkFlow(), tcp, p.Metadata().Timestamp)
 	}
 }
@@ -344,10 +387,18 @@ func conjoinRequestAndResponse(reqCh chan *http.Request, resCh chan *http.Respon
 type tcpReaderStream struct {
 	tcpreader.ReaderStream
 	timestamps []time.Time
+
+	// offsets[i] is the stream offset just past the i-th reassembled chunk, seen at seens[i],
+	// they are recorded only for the responses to find the time of the last byte
+	mu           sync.Mutex
+	trackOffsets bool
+	offsets      []int64
+	seens        []time.Time
+	total        int64
 }
 
-func newTCPReaderStream() tcpReaderStream {
-	return tcpReaderStream{
+func newTCPReaderStream() *tcpReaderStream {
+	return &tcpReaderStream{
 		ReaderStream: tcpreader.NewReaderStream(),
 	}
 }
@@ -358,6 +409,14 @@ func (s *tcpReaderStream) Reassembled(rs []tcpassembly.Reassembly) {
 			continue
 		}
 
+		s.mu.Lock()
+		s.total += int64(len(r.Bytes))
+		if s.trackOffsets {
+			s.offsets = append(s.offsets, s.total)
+			s.seens = append(s.seens, r.Seen)
+		}
+		s.mu.Unlock()
+
 		// HTTP messages aren't started in the middle of a packet generally.
 		if bytes.HasPrefix(r.Bytes, []byte("HTTP/1.")) {
 			s.timestamps = append(s.timestamps, r.Seen)
@@ -386,11 +445,41 @@ func (s *tcpReaderStream) consumeTimestamp() (timestamp time.Time) {
 	return
 }
 
-func parseHTTPRequest(rs *tcpReaderStream, clientAddr *net.TCPAddr, reqCh chan *http.Request, stat *pcapHttpStreamStat) {
+// timestampAt returns the time the byte at the stream offset was seen, and forgets the earlier chunks
+func (s *tcpReaderStream) timestampAt(offset int64) (timestamp time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := sort.Search(len(s.offsets), func(i int) bool {
+		return s.offsets[i] > offset
+	})
+	if i == len(s.offsets) {
+		return // zero time.Time
+	}
+
+	timestamp = s.seens[i]
+	s.offsets = s.offsets[i:]
+	s.seens = s.seens[i:]
+	return
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func parseHTTPRequest(rs *tcpReaderStream, clientAddr *net.TCPAddr, service string, reqCh chan *http.Request, stat *pcapHttpStreamStat) {
 	stat.startReq()
 	defer stat.completeReq()
 
 	var copyBuf [4096]byte
+	var connSeq int
 	bufr := bufio.NewReader(rs)
 	for {
 		// check EOF
@@ -421,6 +510,9 @@ func parseHTTPRequest(rs *tcpReaderStream, clientAddr *net.TCPAddr, reqCh chan *
 		req.RemoteAddr = clientAddr.String()
 		req.Header.Set(conjoinPcapKeyHeader, req.RemoteAddr)
 		req.Header.Set(timestampPcapKeyHeader, timeToUnixNanoStr(timestamp))
+		req.Header.Set(connSeqPcapKeyHeader, strconv.Itoa(connSeq))
+		req.Header.Set(servicePcapKeyHeader, service)
+		connSeq++
 
 		// discard body
 		if req.ContentLength > 0 && req.Body != http.NoBody {
@@ -442,7 +534,8 @@ func parseHTTPResponse(rs *tcpReaderStream, clientAddr *net.TCPAddr, resCh chan
 	defer stat.completeRes()
 
 	var copyBuf [4096]byte
-	bufr := bufio.NewReader(rs)
+	cr := &countingReader{r: rs}
+	bufr := bufio.NewReader(cr)
 	for {
 		// check EOF
 		if _, err := bufr.ReadByte(); err == io.EOF {
@@ -486,6 +579,10 @@ func parseHTTPResponse(rs *tcpReaderStream, clientAddr *net.TCPAddr, resCh chan
 			res.Body = io.NopCloser(&bb)
 		}
 
+		// the last byte of the response is just before the unread part of the stream
+		endTimestamp := rs.timestampAt(cr.n - int64(bufr.Buffered()) - 1)
+		res.Header.Set(endTimestampPcapKeyHeader, timeToUnixNanoStr(endTimestamp))
+
 		// send parsed response
 		resCh <- res
 	}
@@ -510,3 +607,172 @@ func unixNanoStrToTime(s string) (time.Time, error) {
 	unixNano := binary.LittleEndian.Uint64(b[:])
 	return time.Unix(0, int64(unixNano)), nil
 }
+
+const (
+	// the connections are forgotten after they are idle or closed for the time of the packets,
+	// the closed connections are kept for a while until the metrics of their last responses are read
+	pcapConnIdleTimeout   = 5 * time.Minute
+	pcapConnClosedTimeout = time.Minute
+	pcapConnSweepInterval = 10 * time.Second
+)
+
+type pcapTCPConn struct {
+	synAt        time.Time
+	synAckSeen   bool
+	handshakeRTT time.Duration
+	lastSeen     time.Time
+	closedAt     time.Time
+
+	// the sequence numbers seen in each direction: 0 = client to server, 1 = server to client
+	seqs        [2]pcapSeqRanges
+	retransmits []time.Time
+}
+
+// pcapSeqRanges is the merged ranges of the sequence numbers seen in a direction,
+// the offsets are relative to the first sequence number seen, so the wraparound of the sequence numbers doesn't matter
+type pcapSeqRanges struct {
+	base   uint32
+	seen   bool
+	ranges [][2]int64
+}
+
+// add records the segment, and returns true if the whole segment has been seen, i.e. it is retransmitted,
+// the segments that arrive out of order fill the gaps and are not counted
+func (r *pcapSeqRanges) add(seq uint32, n int) bool {
+	if !r.seen {
+		r.base = seq
+		r.seen = true
+	}
+
+	start := int64(int32(seq - r.base))
+	end := start + int64(n)
+
+	merged := make([][2]int64, 0, len(r.ranges)+1)
+	inserted := false
+	for _, rg := range r.ranges {
+		if rg[0] <= start && end <= rg[1] {
+			return true
+		}
+
+		switch {
+		case rg[1] < start:
+			merged = append(merged, rg)
+		case end < rg[0]:
+			if !inserted {
+				merged = append(merged, [2]int64{start, end})
+				inserted = true
+			}
+			merged = append(merged, rg)
+		default:
+			// overlapping or adjacent
+			if rg[0] < start {
+				start = rg[0]
+			}
+			if rg[1] > end {
+				end = rg[1]
+			}
+		}
+	}
+	if !inserted {
+		merged = append(merged, [2]int64{start, end})
+	}
+	r.ranges = merged
+
+	return false
+}
+
+type pcapTCPConnTracker struct {
+	mu        sync.Mutex
+	conns     map[string]*pcapTCPConn // client address: connection
+	lastSweep time.Time
+}
+
+func newPcapTCPConnTracker() *pcapTCPConnTracker {
+	return &pcapTCPConnTracker{
+		conns: make(map[string]*pcapTCPConn),
+	}
+}
+
+func (t *pcapTCPConnTracker) observe(clientAddr string, isReq bool, tcp *layers.TCP, timestamp time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.sweep(timestamp)
+
+	// a new SYN from the client starts a new connection even if the client port is reused
+	if isReq && tcp.SYN && !tcp.ACK {
+		t.conns[clientAddr] = &pcapTCPConn{
+			synAt:    timestamp,
+			lastSeen: timestamp,
+		}
+		return
+	}
+
+	conn, ok := t.conns[clientAddr]
+	if !ok {
+		conn = &pcapTCPConn{}
+		t.conns[clientAddr] = conn
+	}
+	conn.lastSeen = timestamp
+
+	if (tcp.FIN || tcp.RST) && conn.closedAt.IsZero() {
+		conn.closedAt = timestamp
+	}
+
+	switch {
+	case !isReq && tcp.SYN && tcp.ACK:
+		conn.synAckSeen = true
+		return
+	case isReq && tcp.ACK && conn.synAckSeen && conn.handshakeRTT == 0 && !conn.synAt.IsZero():
+		conn.handshakeRTT = timestamp.Sub(conn.synAt)
+	}
+
+	if len(tcp.Payload) == 0 {
+		return
+	}
+
+	dir := 0
+	if !isReq {
+		dir = 1
+	}
+
+	if conn.seqs[dir].add(tcp.Seq, len(tcp.Payload)) {
+		conn.retransmits = append(conn.retransmits, timestamp)
+	}
+}
+
+// sweep forgets the connections that are idle or closed before the time of the packet
+func (t *pcapTCPConnTracker) sweep(timestamp time.Time) {
+	if timestamp.Sub(t.lastSweep) < pcapConnSweepInterval {
+		return
+	}
+	t.lastSweep = timestamp
+
+	for clientAddr, conn := range t.conns {
+		closed := !conn.closedAt.IsZero() && timestamp.Sub(conn.closedAt) > pcapConnClosedTimeout
+		idle := timestamp.Sub(conn.lastSeen) > pcapConnIdleTimeout
+		if closed || idle {
+			delete(t.conns, clientAddr)
+		}
+	}
+}
+
+// metrics returns the handshake RTT of the connection and the number of retransmitted segments between from and to
+func (t *pcapTCPConnTracker) metrics(clientAddr string, from, to time.Time) (time.Duration, int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	conn, ok := t.conns[clientAddr]
+	if !ok {
+		return 0, 0
+	}
+
+	var retransmissions int
+	for _, ts := range conn.retransmits {
+		if !ts.Before(from) && !ts.After(to) {
+			retransmissions++
+		}
+	}
+
+	return conn.handshakeRTT, retransmissions
+}
@@ -0,0 +1,179 @@
+package parsers
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// PcapServers is the set of HTTP server endpoints to profile in the captured packets
+type PcapServers struct {
+	endpoints []pcapEndpoint
+	services  []pcapService
+}
+
+type pcapPortRange struct {
+	min uint16
+	max uint16
+}
+
+// pcapEndpoint matches the server side of a TCP flow, a nil ip matches any address
+type pcapEndpoint struct {
+	ip    net.IP
+	ports pcapPortRange
+}
+
+type pcapService struct {
+	name string
+	pcapEndpoint
+}
+
+// NewPcapServers builds the server endpoints from
+// the cartesian product of rawServerIPs and rawServerPorts (e.g. 80, 3000-3010),
+// the paired endpoints rawServerAddrs (e.g. 192.168.1.10:8080, [::1]:3000-3010),
+// and the service mappings rawServices (e.g. web=80, api=192.168.1.10:8080)
+func NewPcapServers(rawServerIPs, rawServerPorts, rawServerAddrs, rawServices []string) (*PcapServers, error) {
+	servers := &PcapServers{}
+
+	for _, rawServerIP := range rawServerIPs {
+		serverIP := net.ParseIP(rawServerIP)
+		if serverIP == nil {
+			return nil, fmt.Errorf("failed to parse ip: %s", rawServerIP)
+		}
+
+		for _, rawServerPort := range rawServerPorts {
+			ports, err := parsePcapPortRange(rawServerPort)
+			if err != nil {
+				return nil, err
+			}
+
+			servers.endpoints = append(servers.endpoints, pcapEndpoint{
+				ip:    serverIP,
+				ports: ports,
+			})
+		}
+	}
+
+	for _, rawServerAddr := range rawServerAddrs {
+		endpoint, err := parsePcapEndpoint(rawServerAddr)
+		if err != nil {
+			return nil, err
+		}
+
+		servers.endpoints = append(servers.endpoints, endpoint)
+	}
+
+	for _, rawService := range rawServices {
+		service, err := parsePcapService(rawService)
+		if err != nil {
+			return nil, err
+		}
+
+		servers.services = append(servers.services, service)
+	}
+
+	return servers, nil
+}
+
+func (s *PcapServers) match(ip net.IP, port uint16) bool {
+	for _, endpoint := range s.endpoints {
+		if endpoint.match(ip, port) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// service returns the name of the first service that matches the server address
+func (s *PcapServers) service(addr *net.TCPAddr) string {
+	for _, service := range s.services {
+		if service.match(addr.IP, uint16(addr.Port)) {
+			return service.name
+		}
+	}
+
+	return ""
+}
+
+func (e pcapEndpoint) match(ip net.IP, port uint16) bool {
+	if e.ip != nil && !e.ip.Equal(ip) {
+		return false
+	}
+
+	return e.ports.min <= port && port <= e.ports.max
+}
+
+func parsePcapPortRange(s string) (pcapPortRange, error) {
+	min, max := s, s
+	if i := strings.Index(s, "-"); i >= 0 {
+		min, max = s[:i], s[i+1:]
+	}
+
+	minPort, err := strconv.ParseUint(strings.TrimSpace(min), 10, 16)
+	if err != nil {
+		return pcapPortRange{}, fmt.Errorf("failed to parse port: %s", s)
+	}
+
+	maxPort, err := strconv.ParseUint(strings.TrimSpace(max), 10, 16)
+	if err != nil {
+		return pcapPortRange{}, fmt.Errorf("failed to parse port: %s", s)
+	}
+
+	if minPort > maxPort {
+		return pcapPortRange{}, fmt.Errorf("invalid port range: %s", s)
+	}
+
+	return pcapPortRange{
+		min: uint16(minPort),
+		max: uint16(maxPort),
+	}, nil
+}
+
+func parsePcapEndpoint(s string) (pcapEndpoint, error) {
+	host, port, err := net.SplitHostPort(s)
+	if err != nil {
+		return pcapEndpoint{}, fmt.Errorf("failed to parse address: %s", s)
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return pcapEndpoint{}, fmt.Errorf("failed to parse ip: %s", host)
+	}
+
+	ports, err := parsePcapPortRange(port)
+	if err != nil {
+		return pcapEndpoint{}, err
+	}
+
+	return pcapEndpoint{
+		ip:    ip,
+		ports: ports,
+	}, nil
+}
+
+// parsePcapService parses name=port, name=port-port or name=ip:port
+func parsePcapService(s string) (pcapService, error) {
+	i := strings.Index(s, "=")
+	if i <= 0 {
+		return pcapService{}, fmt.Errorf("failed to parse service: %s", s)
+	}
+	name, spec := s[:i], s[i+1:]
+
+	var endpoint pcapEndpoint
+	var err error
+	if strings.Contains(spec, ":") {
+		endpoint, err = parsePcapEndpoint(spec)
+	} else {
+		endpoint.ports, err = parsePcapPortRange(spec)
+	}
+	if err != nil {
+		return pcapService{}, err
+	}
+
+	return pcapService{
+		name:         name,
+		pcapEndpoint: endpoint,
+	}, nil
+}
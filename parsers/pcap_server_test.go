@@ -0,0 +1,166 @@
+package parsers
+
+import (
+	"net"
+	"testing"
+)
+
+func TestParsePcapPortRange(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    pcapPortRange
+		wantErr bool
+	}{
+		{
+			name: "port",
+			s:    "80",
+			want: pcapPortRange{min: 80, max: 80},
+		},
+		{
+			name: "port range",
+			s:    "3000-3010",
+			want: pcapPortRange{min: 3000, max: 3010},
+		},
+		{
+			name: "port range with spaces",
+			s:    "3000 - 3010",
+			want: pcapPortRange{min: 3000, max: 3010},
+		},
+		{
+			name: "max port",
+			s:    "65535",
+			want: pcapPortRange{min: 65535, max: 65535},
+		},
+		{
+			name:    "empty",
+			s:       "",
+			wantErr: true,
+		},
+		{
+			name:    "not a number",
+			s:       "http",
+			wantErr: true,
+		},
+		{
+			name:    "out of range",
+			s:       "65536",
+			wantErr: true,
+		},
+		{
+			name:    "open range",
+			s:       "3000-",
+			wantErr: true,
+		},
+		{
+			name:    "reversed range",
+			s:       "3010-3000",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePcapPortRange(tt.s)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parsePcapPortRange(%q) = %+v, want error", tt.s, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("parsePcapPortRange(%q) = %+v, want %+v", tt.s, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePcapEndpoint(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		ip      string
+		ports   pcapPortRange
+		wantErr bool
+	}{
+		{
+			name:  "ipv4",
+			s:     "192.168.1.10:8080",
+			ip:    "192.168.1.10",
+			ports: pcapPortRange{min: 8080, max: 8080},
+		},
+		{
+			name:  "ipv6 port range",
+			s:     "[::1]:3000-3010",
+			ip:    "::1",
+			ports: pcapPortRange{min: 3000, max: 3010},
+		},
+		{
+			name:    "no port",
+			s:       "192.168.1.10",
+			wantErr: true,
+		},
+		{
+			name:    "ipv6 without brackets",
+			s:       "::1:8080",
+			wantErr: true,
+		},
+		{
+			name:    "host name",
+			s:       "localhost:8080",
+			wantErr: true,
+		},
+		{
+			name:    "invalid port",
+			s:       "192.168.1.10:http",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePcapEndpoint(tt.s)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parsePcapEndpoint(%q) = %+v, want error", tt.s, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.ip.Equal(net.ParseIP(tt.ip)) || got.ports != tt.ports {
+				t.Errorf("parsePcapEndpoint(%q) = %v %+v, want %v %+v", tt.s, got.ip, got.ports, tt.ip, tt.ports)
+			}
+		})
+	}
+}
+
+func TestPcapServersMatch(t *testing.T) {
+	servers, err := NewPcapServers([]string{"10.0.0.1"}, []string{"80", "3000-3010"}, []string{"[::1]:8080"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		ip   string
+		port uint16
+		want bool
+	}{
+		{ip: "10.0.0.1", port: 80, want: true},
+		{ip: "10.0.0.1", port: 3005, want: true},
+		{ip: "10.0.0.1", port: 8080, want: false},
+		{ip: "10.0.0.2", port: 80, want: false},
+		{ip: "::1", port: 8080, want: true},
+		{ip: "::1", port: 80, want: false},
+	}
+
+	for _, tt := range tests {
+		if got := servers.match(net.ParseIP(tt.ip), tt.port); got != tt.want {
+			t.Errorf("match(%s, %d) = %v, want %v", tt.ip, tt.port, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package parsers
+
+import "testing"
+
+func TestPcapSeqRangesAdd(t *testing.T) {
+	type segment struct {
+		seq  uint32
+		n    int
+		want bool
+	}
+
+	tests := []struct {
+		name     string
+		segments []segment
+	}{
+		{
+			name: "in order",
+			segments: []segment{
+				{seq: 1000, n: 100, want: false},
+				{seq: 1100, n: 100, want: false},
+				{seq: 1200, n: 100, want: false},
+			},
+		},
+		{
+			name: "retransmitted",
+			segments: []segment{
+				{seq: 1000, n: 100, want: false},
+				{seq: 1100, n: 100, want: false},
+				{seq: 1000, n: 100, want: true},
+				{seq: 1050, n: 100, want: true},
+			},
+		},
+		{
+			name: "out of order",
+			segments: []segment{
+				{seq: 1000, n: 100, want: false},
+				{seq: 1200, n: 100, want: false},
+				{seq: 1100, n: 100, want: false},
+				{seq: 1100, n: 200, want: true},
+			},
+		},
+		{
+			name: "partially new",
+			segments: []segment{
+				{seq: 1000, n: 100, want: false},
+				{seq: 1050, n: 100, want: false},
+				{seq: 1000, n: 150, want: true},
+			},
+		},
+		{
+			name: "sequence number wraparound",
+			segments: []segment{
+				{seq: 0xffffff00, n: 0x100, want: false},
+				{seq: 0, n: 100, want: false},
+				{seq: 0xffffff80, n: 0x80, want: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var r pcapSeqRanges
+			for i, s := range tt.segments {
+				if got := r.add(s.seq, s.n); got != s.want {
+					t.Errorf("segment %d: want retransmitted: %v, got: %v", i, s.want, got)
+				}
+			}
+		})
+	}
+}
@@ -19,7 +19,7 @@ type RegexpParser struct {
 
 var errPatternNotMatched = errors.New("pattern not matched")
 
-func NewSubexpNames(uri, method, time, responseTime, requestTime, size, status string) *statKeys {
+func NewSubexpNames(uri, method, time, responseTime, requestTime, size, reqSize, status, traceID string) *statKeys {
 	return newStatKeys(
 		uriKey(uri),
 		methodKey(method),
@@ -27,11 +27,13 @@ func NewSubexpNames(uri, method, time, responseTime, requestTime, size, status s
 		responseTimeKey(responseTime),
 		requestTimeKey(requestTime),
 		bodyBytesKey(size),
+		requestBodyBytesKey(reqSize),
 		statusKey(status),
+		traceIDKey(traceID),
 	)
 }
 
-func NewRegexpParser(r io.Reader, expr string, names *statKeys, query, qsIgnoreValues bool) (Parser, error) {
+func NewRegexpParser(r io.Reader, expr string, names *statKeys, query, qsIgnoreValues, strictMode bool) (Parser, error) {
 	re, err := regexp.Compile(expr)
 	if err != nil {
 		return nil, err
@@ -41,6 +43,7 @@ func NewRegexpParser(r io.Reader, expr string, names *statKeys, query, qsIgnoreV
 		reader:         bufio.NewReader(r),
 		re:             re,
 		subexpNames:    names,
+		strictMode:     strictMode,
 		queryString:    query,
 		qsIgnoreValues: qsIgnoreValues,
 	}, nil
@@ -55,7 +58,7 @@ func (rp *RegexpParser) Parse() (*ParsedHTTPStat, error) {
 
 	groups := rp.re.FindStringSubmatch(string(b))
 	if len(groups) == 0 {
-		return nil, errSkipReadLine(rp.strictMode, errPatternNotMatched)
+		return nil, errSkipReadLine(rp.strictMode, reasonPatternNotMatched, errPatternNotMatched)
 	}
 
 	parsedValue := make(map[string]string, len(groups))
@@ -76,6 +79,7 @@ func (rp *RegexpParser) Parse() (*ParsedHTTPStat, error) {
 	logEntries = parsedValue
 
 	parsedHTTPStat.Entries = logEntries
+	parsedHTTPStat.Raw = b
 
 	return parsedHTTPStat, nil
 }
@@ -0,0 +1,171 @@
+package parsers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/tkuchiki/parsetime"
+)
+
+// SessionParser synthesizes the trace IDs of the requests that have no trace ID.
+// The requests are grouped by the session key, and a session is split after an inactivity gap.
+type SessionParser struct {
+	parser    Parser
+	keys      []sessionKey
+	gap       time.Duration
+	parseTime parsetime.ParseTime
+	sessions  map[string]*session
+	seq       int
+	latest    time.Time
+	lastSweep time.Time
+}
+
+// sessionKey is an entry of the log, or a cookie in the entry
+type sessionKey struct {
+	entry  string
+	cookie string
+}
+
+type session struct {
+	id       string
+	lastSeen time.Time
+}
+
+// NewSessionParser wraps the parser, rawKeys are the names of the log entries (e.g. remote_addr,user_agent)
+// or the cookies in the entries (e.g. http_cookie:SESSIONID)
+func NewSessionParser(p Parser, rawKeys []string, gap time.Duration, location string) (Parser, error) {
+	pt, err := parsetime.NewParseTime(location)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]sessionKey, 0, len(rawKeys))
+	for _, rawKey := range rawKeys {
+		var key sessionKey
+		if i := strings.Index(rawKey, ":"); i >= 0 {
+			key.entry, key.cookie = rawKey[:i], rawKey[i+1:]
+		} else {
+			key.entry = rawKey
+		}
+
+		if key.entry == "" {
+			return nil, fmt.Errorf("invalid session key: %s", rawKey)
+		}
+
+		keys = append(keys, key)
+	}
+
+	return &SessionParser{
+		parser:    p,
+		keys:      keys,
+		gap:       gap,
+		parseTime: pt,
+		sessions:  make(map[string]*session),
+	}, nil
+}
+
+func (sp *SessionParser) Parse() (*ParsedHTTPStat, error) {
+	stat, err := sp.parser.Parse()
+	if err != nil {
+		return nil, err
+	}
+
+	if stat.TraceID != "" {
+		return stat, nil
+	}
+
+	key, ok := sp.sessionKey(stat.Entries)
+	if !ok {
+		return stat, nil
+	}
+
+	// the requests whose time cannot be parsed stay in the current session
+	t, err := sp.parseTime.Parse(stat.Time)
+	if err != nil {
+		t = time.Time{}
+	}
+
+	s, ok := sp.sessions[key]
+	if !ok || (!t.IsZero() && !s.lastSeen.IsZero() && t.Sub(s.lastSeen) > sp.gap) {
+		sp.seq++
+		s = &session{
+			id: fmt.Sprintf("session-%d", sp.seq),
+		}
+		sp.sessions[key] = s
+	}
+
+	if t.After(s.lastSeen) {
+		s.lastSeen = t
+	}
+	if t.After(sp.latest) {
+		sp.latest = t
+		sp.sweep()
+	}
+
+	stat.TraceID = s.id
+
+	return stat, nil
+}
+
+// sweep forgets the sessions inactive longer than the gap, at most once per gap of the log time.
+// The sessions whose requests have no parsable time are kept, they are never split.
+func (sp *SessionParser) sweep() {
+	if sp.latest.Sub(sp.lastSweep) <= sp.gap {
+		return
+	}
+	sp.lastSweep = sp.latest
+
+	for key, s := range sp.sessions {
+		if !s.lastSeen.IsZero() && sp.latest.Sub(s.lastSeen) > sp.gap {
+			delete(sp.sessions, key)
+		}
+	}
+}
+
+// sessionKey returns false if the log has none of the session keys
+func (sp *SessionParser) sessionKey(entries LogEntries) (string, bool) {
+	values := make([]string, len(sp.keys))
+	found := false
+	for i, key := range sp.keys {
+		val := entries[key.entry]
+		if key.cookie != "" {
+			val = cookieValue(val, key.cookie)
+		}
+
+		if val != "" && val != "-" {
+			found = true
+		}
+		values[i] = val
+	}
+
+	return strings.Join(values, "\x00"), found
+}
+
+func cookieValue(header, name string) string {
+	req := &http.Request{
+		Header: http.Header{
+			"Cookie": []string{header},
+		},
+	}
+
+	c, err := req.Cookie(name)
+	if err != nil {
+		return ""
+	}
+
+	return c.Value
+}
+
+func (sp *SessionParser) ReadBytes() int {
+	return sp.parser.ReadBytes()
+}
+
+func (sp *SessionParser) SetReadBytes(n int) {
+	sp.parser.SetReadBytes(n)
+}
+
+func (sp *SessionParser) Seek(n int) error {
+	return sp.parser.Seek(n)
+}
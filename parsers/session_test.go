@@ -0,0 +1,186 @@
+package parsers
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sliceParser returns the stats in order, then io.EOF
+type sliceParser struct {
+	stats []*ParsedHTTPStat
+}
+
+func (p *sliceParser) Parse() (*ParsedHTTPStat, error) {
+	if len(p.stats) == 0 {
+		return nil, io.EOF
+	}
+
+	stat := p.stats[0]
+	p.stats = p.stats[1:]
+
+	return stat, nil
+}
+
+func (p *sliceParser) ReadBytes() int     { return 0 }
+func (p *sliceParser) SetReadBytes(n int) {}
+func (p *sliceParser) Seek(n int) error   { return nil }
+
+func TestSessionParser(t *testing.T) {
+	type request struct {
+		time    string
+		traceID string
+		entries LogEntries
+	}
+
+	tests := []struct {
+		name     string
+		keys     []string
+		requests []request
+		want     []string
+	}{
+		{
+			name: "entry key",
+			keys: []string{"remote_addr", "user_agent"},
+			requests: []request{
+				{time: "2024-01-01T00:00:00Z", entries: LogEntries{"remote_addr": "10.0.0.1", "user_agent": "curl"}},
+				{time: "2024-01-01T00:00:01Z", entries: LogEntries{"remote_addr": "10.0.0.1", "user_agent": "wget"}},
+				{time: "2024-01-01T00:00:02Z", entries: LogEntries{"remote_addr": "10.0.0.2", "user_agent": "curl"}},
+				{time: "2024-01-01T00:00:03Z", entries: LogEntries{"remote_addr": "10.0.0.1", "user_agent": "curl"}},
+			},
+			want: []string{"session-1", "session-2", "session-3", "session-1"},
+		},
+		{
+			name: "auth header field",
+			keys: []string{"http_authorization"},
+			requests: []request{
+				{time: "2024-01-01T00:00:00Z", entries: LogEntries{"http_authorization": "Bearer a"}},
+				{time: "2024-01-01T00:00:01Z", entries: LogEntries{"http_authorization": "Bearer b"}},
+				{time: "2024-01-01T00:00:02Z", entries: LogEntries{"http_authorization": "Bearer a"}},
+				{time: "2024-01-01T00:00:03Z", entries: LogEntries{"http_authorization": "-"}},
+			},
+			want: []string{"session-1", "session-2", "session-1", ""},
+		},
+		{
+			name: "cookie key",
+			keys: []string{"http_cookie:SESSIONID"},
+			requests: []request{
+				{time: "2024-01-01T00:00:00Z", entries: LogEntries{"http_cookie": "lang=ja; SESSIONID=a"}},
+				{time: "2024-01-01T00:00:01Z", entries: LogEntries{"http_cookie": "SESSIONID=b"}},
+				{time: "2024-01-01T00:00:02Z", entries: LogEntries{"http_cookie": "SESSIONID=a; lang=en"}},
+				{time: "2024-01-01T00:00:03Z", entries: LogEntries{"http_cookie": "lang=ja"}},
+			},
+			want: []string{"session-1", "session-2", "session-1", ""},
+		},
+		{
+			name: "gap",
+			keys: []string{"remote_addr"},
+			requests: []request{
+				{time: "2024-01-01T00:00:00Z", entries: LogEntries{"remote_addr": "10.0.0.1"}},
+				{time: "2024-01-01T00:00:50Z", entries: LogEntries{"remote_addr": "10.0.0.1"}},
+				{time: "2024-01-01T00:01:40Z", entries: LogEntries{"remote_addr": "10.0.0.1"}},
+				{time: "2024-01-01T00:03:00Z", entries: LogEntries{"remote_addr": "10.0.0.1"}},
+				{time: "2024-01-01T00:03:30Z", entries: LogEntries{"remote_addr": "10.0.0.1"}},
+			},
+			want: []string{"session-1", "session-1", "session-1", "session-2", "session-2"},
+		},
+		{
+			name: "trace id",
+			keys: []string{"remote_addr"},
+			requests: []request{
+				{time: "2024-01-01T00:00:00Z", traceID: "abc", entries: LogEntries{"remote_addr": "10.0.0.1"}},
+				{time: "2024-01-01T00:00:01Z", entries: LogEntries{"remote_addr": "10.0.0.1"}},
+			},
+			want: []string{"abc", "session-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &sliceParser{}
+			for _, r := range tt.requests {
+				stat := NewParsedHTTPStat("/", "GET", r.time, 0.1, 10, 200, r.traceID)
+				stat.Entries = r.entries
+				p.stats = append(p.stats, stat)
+			}
+
+			sp, err := NewSessionParser(p, tt.keys, time.Minute, "UTC")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := make([]string, 0, len(tt.requests))
+			for {
+				stat, err := sp.Parse()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, stat.TraceID)
+			}
+
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("want: %v, got: %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSessionParserSweep(t *testing.T) {
+	p := &sliceParser{}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 100; i++ {
+		stat := NewParsedHTTPStat("/", "GET", start.Add(time.Duration(i)*time.Minute).Format(time.RFC3339), 0.1, 10, 200, "")
+		stat.Entries = LogEntries{"remote_addr": "10.0.0." + string(rune('0'+i%10)) + string(rune('0'+i/10))}
+		p.stats = append(p.stats, stat)
+	}
+
+	parser, err := NewSessionParser(p, []string{"remote_addr"}, 5*time.Minute, "UTC")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sp := parser.(*SessionParser)
+	for {
+		if _, err := sp.Parse(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the sessions inactive longer than the gap are forgotten, at most once per gap
+	if len(sp.sessions) > 11 {
+		t.Errorf("want at most 11 sessions, got: %d", len(sp.sessions))
+	}
+}
+
+func TestKeepUntracedLines(t *testing.T) {
+	log := `{"uri":"/a","method":"GET","status":200,"response_time":0.1,"body_bytes":10,"trace_id":"abc"}
+{"uri":"/b","method":"GET","status":200,"response_time":0.1,"body_bytes":10}
+`
+	keys := NewJSONKeys("uri", "method", "time", "response_time", "request_time", "body_bytes", "", "status", "trace_id")
+
+	// the lines without trace ID are skipped by the profiler only in the trace mode
+	p := NewJSONParser(strings.NewReader(log), keys, false, false, true)
+
+	got := make([]string, 0)
+	for {
+		stat, err := p.Parse()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, stat.Uri+" "+stat.TraceID)
+	}
+
+	want := []string{"/a abc", "/b "}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("want: %q, got: %q", want, got)
+	}
+}
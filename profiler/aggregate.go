@@ -0,0 +1,97 @@
+package profiler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tetsuzawa/alp-trace/options"
+	"github.com/tetsuzawa/alp-trace/stats"
+)
+
+// aggregatesLimit is the number of the stats kept for the matching groups and the filters
+const aggregatesLimit = 16
+
+// aggregate is the stats of the requests from the dropped requests to next
+type aggregate struct {
+	profiler *Profiler
+	sts      *stats.HTTPStats
+	tsts     *stats.TraceStats
+	dropped  int
+	next     int
+}
+
+// aggregates keeps the stats of the requests for each matching groups and filters,
+// the stats are updated by the requests appended after the last use, and made again when the requests are dropped out of the window
+type aggregates struct {
+	entries *followEntries
+	stats   map[string]*aggregate
+}
+
+func newAggregates(entries *followEntries) *aggregates {
+	return &aggregates{
+		entries: entries,
+		stats:   make(map[string]*aggregate),
+	}
+}
+
+// aggregateKey is the options that change the aggregation of the requests
+func aggregateKey(opts *options.Options) string {
+	return fmt.Sprintf("%s\n%s", strings.Join(opts.MatchingGroups, ","), opts.Filters)
+}
+
+// get returns the stats of the matching groups and the filters of the options,
+// the options and the sort options are set to the stats, which are shared by the other sort, reverse and limit
+func (a *aggregates) get(p *Profiler, sortOptions *stats.SortOptions) (*aggregate, error) {
+	key := aggregateKey(p.options)
+
+	agg, ok := a.stats[key]
+	if !ok || agg.dropped != a.entries.dropped {
+		// the requests of the both modes are aggregated to share the stats between the endpoints and the scenarios
+		opts := *p.options
+		opts.Trace = true
+		opts.Bucket = 0
+		ap := &Profiler{options: &opts}
+		sts, tsts, err := ap.newStats(sortOptions)
+		if err != nil {
+			return nil, err
+		}
+
+		if !ok && len(a.stats) >= aggregatesLimit {
+			a.stats = make(map[string]*aggregate)
+		}
+
+		agg = &aggregate{
+			profiler: ap,
+			sts:      sts,
+			tsts:     tsts,
+			dropped:  a.entries.dropped,
+			next:     a.entries.dropped,
+		}
+		a.stats[key] = agg
+	}
+
+	// the lines are already reported when they are read
+	discard := newParseReport()
+	for _, e := range a.entries.entries[agg.next-agg.dropped:] {
+		// the filters of the command line are applied when the requests are read, and the filters of the API request here
+		ok, err := agg.sts.DoFilter(e.stat)
+		if err != nil {
+			delete(a.stats, key)
+			return nil, fmt.Errorf("line %d: %w", e.line, err)
+		}
+		agg.next++
+
+		if !ok {
+			continue
+		}
+
+		agg.profiler.set(agg.sts, agg.tsts, e.stat, e.pos, e.line, discard)
+	}
+
+	agg.sts.SetOptions(p.options)
+	agg.sts.SetSortOptions(sortOptions)
+	agg.tsts.SetOptions(p.options)
+	agg.tsts.SetSortOptions(sortOptions)
+
+	return agg, nil
+}
@@ -0,0 +1,299 @@
+package profiler
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/tetsuzawa/alp-trace/parsers"
+	"github.com/tetsuzawa/alp-trace/stats"
+	"github.com/tkuchiki/parsetime"
+)
+
+const followPollInterval = 200 * time.Millisecond
+
+// clearScreen moves the cursor to the top and clears the terminal
+const clearScreen = "\033[H\033[2J"
+
+// followReader reads the file as it grows like tail -F,
+// it reopens the file when the file is rotated and reads it from the start when the file is truncated
+type followReader struct {
+	filename string
+	file     *os.File
+	offset   int64
+}
+
+func newFollowReader(filename string) (*followReader, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return &followReader{
+		filename: filename,
+		file:     f,
+	}, nil
+}
+
+// Read waits for the data instead of returning io.EOF
+func (r *followReader) Read(b []byte) (int, error) {
+	for {
+		n, err := r.file.Read(b)
+		r.offset += int64(n)
+		if n > 0 {
+			return n, nil
+		}
+
+		if err != nil && err != io.EOF {
+			return 0, err
+		}
+
+		if err = r.reopen(); err != nil {
+			return 0, err
+		}
+
+		time.Sleep(followPollInterval)
+	}
+}
+
+// reopen switches to the new file after the current file is read to the end
+func (r *followReader) reopen() error {
+	st, err := os.Stat(r.filename)
+	// the new file may not be created yet during the rotation
+	if err != nil {
+		return nil
+	}
+
+	cur, err := r.file.Stat()
+	if err != nil {
+		return err
+	}
+
+	if !os.SameFile(st, cur) {
+		f, err := os.Open(r.filename)
+		if err != nil {
+			return nil
+		}
+
+		r.file.Close()
+		r.file = f
+		r.offset = 0
+
+		return nil
+	}
+
+	if st.Size() < r.offset {
+		if _, err = r.file.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		r.offset = 0
+	}
+
+	return nil
+}
+
+func (r *followReader) Close() error {
+	return r.file.Close()
+}
+
+// followEntry is a request kept to drop it out of the window by the time of the log
+type followEntry struct {
+	time time.Time
+	stat *parsers.ParsedHTTPStat
+	pos  int
+	line int
+}
+
+// followEntries keeps the requests in the order of reading, and drops them out of the window by the time of the log,
+// the window ends at the latest time read so far
+type followEntries struct {
+	parseTime parsetime.ParseTime
+	window    time.Duration
+	entries   []*followEntry
+	latest    time.Time
+	// dropped is the number of the requests dropped out of the window
+	dropped int
+}
+
+func newFollowEntries(location string, window time.Duration) (*followEntries, error) {
+	pt, err := parsetime.NewParseTime(location)
+	if err != nil {
+		return nil, err
+	}
+
+	return &followEntries{
+		parseTime: pt,
+		window:    window,
+		entries:   make([]*followEntry, 0),
+	}, nil
+}
+
+// append keeps the request, all the requests are kept without the window
+func (fe *followEntries) append(s *parsers.ParsedHTTPStat, pos, line int) {
+	// the line without the time is in the window with the previous line
+	t, err := fe.parseTime.Parse(s.Time)
+	if s.Time == "" || s.Time == "-" || err != nil {
+		t = fe.latest
+	}
+	if t.After(fe.latest) {
+		fe.latest = t
+	}
+
+	fe.entries = append(fe.entries, &followEntry{
+		time: t,
+		stat: s,
+		pos:  pos,
+		line: line,
+	})
+
+	if fe.window > 0 {
+		n := len(fe.entries)
+		fe.entries = pruneFollowEntries(fe.entries, fe.latest.Add(-fe.window))
+		fe.dropped += n - len(fe.entries)
+	}
+}
+
+// read returns the number of the requests appended so far, including the dropped requests
+func (fe *followEntries) read() int {
+	return fe.dropped + len(fe.entries)
+}
+
+type followLine struct {
+	stat *parsers.ParsedHTTPStat
+	pos  int
+	err  error
+}
+
+// follow outputs the results every interval until the log ends or it is interrupted,
+// the stats are updated by each request, or made from the requests in the window of the log time with the window
+func (p *Profiler) follow(sortOptions *stats.SortOptions, sts *stats.HTTPStats, tsts *stats.TraceStats, parser parsers.Parser, printer *stats.Printer, tracePrinter *stats.TracePrinter) error {
+	// the stats of the window are made from the entries, sts only filters the requests
+	entries, err := newFollowEntries(p.options.Location, p.options.Window)
+	if err != nil {
+		return err
+	}
+
+	lines := make(chan *followLine)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			s, err := parser.Parse()
+			select {
+			case lines <- &followLine{stat: s, pos: parser.ReadBytes(), err: err}:
+			case <-done:
+				return
+			}
+
+			if err == io.EOF {
+				return
+			}
+		}
+	}()
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+	defer signal.Stop(sig)
+
+	ticker := time.NewTicker(p.options.Interval)
+	defer ticker.Stop()
+
+	report := newParseReport()
+	defer report.print(p.errWriter)
+
+	windowStats := func() (*stats.HTTPStats, *stats.TraceStats, error) {
+		wsts, wtsts, err := p.newStats(sortOptions)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// the lines are already reported when they are read
+		discard := newParseReport()
+		for _, e := range entries.entries {
+			p.set(wsts, wtsts, e.stat, e.pos, e.line, discard)
+		}
+
+		return wsts, wtsts, nil
+	}
+
+	render := func() error {
+		if p.options.Window <= 0 {
+			return p.render(sts, tsts, printer, tracePrinter)
+		}
+
+		wsts, wtsts, err := windowStats()
+		if err != nil {
+			return err
+		}
+
+		return p.render(wsts, wtsts, printer, tracePrinter)
+	}
+
+	for {
+		select {
+		case l := <-lines:
+			if l.err == io.EOF {
+				return render()
+			}
+
+			err = p.profile(l.stat, l.err, sts, report, p.options.Trace, func(s *parsers.ParsedHTTPStat) {
+				if p.options.Window <= 0 {
+					p.set(sts, tsts, s, l.pos, report.read, report)
+					return
+				}
+
+				entries.append(s, l.pos, report.read)
+			})
+			if err != nil {
+				return err
+			}
+		case <-ticker.C:
+			if err = render(); err != nil {
+				return err
+			}
+		case <-sig:
+			return nil
+		}
+	}
+}
+
+// pruneFollowEntries drops the entries before the time from the start, the entries are in the order of reading
+func pruneFollowEntries(entries []*followEntry, t time.Time) []*followEntry {
+	i := 0
+	for i < len(entries) && entries[i].time.Before(t) {
+		i++
+	}
+
+	// the dropped entries are freed when the slice grows
+	return entries[i:]
+}
+
+// render outputs the stats, the screen is cleared before the output if it is a terminal
+func (p *Profiler) render(sts *stats.HTTPStats, tsts *stats.TraceStats, printer *stats.Printer, tracePrinter *stats.TracePrinter) error {
+	// the scenario of a trace changes while its requests are read
+	if p.options.Trace {
+		tsts.Reaggregate()
+	}
+
+	if isTerminal(p.outWriter) {
+		fmt.Fprint(p.outWriter, clearScreen)
+	}
+
+	return p.print(sts, tsts, printer, tracePrinter)
+}
+
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	st, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return st.Mode()&os.ModeCharDevice != 0
+}
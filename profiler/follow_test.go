@@ -0,0 +1,229 @@
+package profiler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tetsuzawa/alp-trace/options"
+	"github.com/tetsuzawa/alp-trace/parsers"
+	"github.com/tetsuzawa/alp-trace/stats"
+)
+
+// readFollow reads from the follow reader, and fails if nothing is read in time
+func readFollow(t *testing.T, r *followReader) string {
+	t.Helper()
+
+	type result struct {
+		s   string
+		err error
+	}
+
+	ch := make(chan result, 1)
+	go func() {
+		b := make([]byte, 64)
+		n, err := r.Read(b)
+		ch <- result{s: string(b[:n]), err: err}
+	}()
+
+	select {
+	case res := <-ch:
+		if res.err != nil {
+			t.Fatal(res.err)
+		}
+		return res.s
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out reading the file")
+	}
+
+	return ""
+}
+
+func TestFollowReader(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(t *testing.T, filename string)
+		want   string
+	}{
+		{
+			name: "append",
+			change: func(t *testing.T, filename string) {
+				f, err := os.OpenFile(filename, os.O_APPEND|os.O_WRONLY, 0644)
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer f.Close()
+
+				if _, err = f.WriteString("appended\n"); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: "appended\n",
+		},
+		{
+			name: "truncate",
+			change: func(t *testing.T, filename string) {
+				if err := os.WriteFile(filename, []byte("new\n"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: "new\n",
+		},
+		{
+			name: "rotate",
+			change: func(t *testing.T, filename string) {
+				if err := os.Rename(filename, filename+".1"); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(filename, []byte("rotated\n"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: "rotated\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "access.log")
+			if err := os.WriteFile(filename, []byte("the first line\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			r, err := newFollowReader(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer r.Close()
+
+			if got := readFollow(t, r); got != "the first line\n" {
+				t.Fatalf("want the first line, got: %q", got)
+			}
+
+			tt.change(t, filename)
+
+			if got := readFollow(t, r); got != tt.want {
+				t.Errorf("want: %q, got: %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestPruneFollowEntries(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	entries := make([]*followEntry, 0)
+	for _, sec := range []int{0, 1, 2, 1, 3} {
+		entries = append(entries, &followEntry{time: start.Add(time.Duration(sec) * time.Second), line: len(entries) + 1})
+	}
+
+	tests := []struct {
+		name string
+		t    time.Time
+		want []int
+	}{
+		{name: "none", t: start, want: []int{1, 2, 3, 4, 5}},
+		{name: "first", t: start.Add(time.Second), want: []int{2, 3, 4, 5}},
+		// the entries are dropped from the start in the order of reading
+		{name: "out of order", t: start.Add(2 * time.Second), want: []int{3, 4, 5}},
+		{name: "all", t: start.Add(time.Minute), want: []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make([]int, 0)
+			for _, e := range pruneFollowEntries(entries, tt.t) {
+				got = append(got, e.line)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("want lines: %v, got: %v", tt.want, got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("want lines: %v, got: %v", tt.want, got)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestFollow(t *testing.T) {
+	log := `{"time":"2024-01-01T00:00:00Z","uri":"/a","method":"GET","status":200,"response_time":0.1,"body_bytes":10,"trace_id":"a"}
+{"time":"2024-01-01T00:00:30Z","uri":"/b","method":"GET","status":200,"response_time":0.1,"body_bytes":10,"trace_id":"b"}
+{"time":"2024-01-01T00:01:00Z","uri":"/a","method":"GET","status":200,"response_time":0.1,"body_bytes":10,"trace_id":"c"}
+{"time":"-","uri":"/c","method":"GET","status":200,"response_time":0.1,"body_bytes":10,"trace_id":"d"}
+{"uri":"/d","method":"GET","status":200,"response_time":0.1,"body_bytes":10}
+`
+
+	tests := []struct {
+		name   string
+		window time.Duration
+		want   []string
+	}{
+		{
+			name: "all",
+			want: []string{"/a 2", "/b 1", "/c 1", "/d 1"},
+		},
+		{
+			// the window is from the latest time of the log, and the line without the time is with the previous line
+			name:   "window",
+			window: 20 * time.Second,
+			want:   []string{"/a 1", "/c 1", "/d 1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := options.NewOptions(
+				options.Follow(true),
+				options.Format("json"),
+				options.Interval(time.Hour),
+				options.Window(tt.window),
+				options.Location("UTC"),
+			)
+
+			var out, errOut bytes.Buffer
+			prof := NewProfiler(&out, &errOut, opts)
+
+			sortOptions := stats.NewSortOptions()
+			if err := sortOptions.SetAndValidate("uri"); err != nil {
+				t.Fatal(err)
+			}
+
+			parser := parsers.NewJSONParser(strings.NewReader(log), testJSONKeys, false, false, false)
+			if err := prof.Run(sortOptions, parser); err != nil {
+				t.Fatal(err)
+			}
+
+			var result struct {
+				Endpoints []struct {
+					Uri   string `json:"uri"`
+					Count int    `json:"count"`
+				} `json:"endpoints"`
+			}
+			if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+				t.Fatalf("%v: %s", err, out.String())
+			}
+
+			got := make([]string, 0, len(result.Endpoints))
+			for _, e := range result.Endpoints {
+				got = append(got, fmt.Sprintf("%s %d", e.Uri, e.Count))
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("want: %v, got: %v", tt.want, got)
+			}
+
+			// the line without trace ID is profiled without --trace
+			want := "5 lines read, 5 profiled, 0 filtered, 0 skipped\n"
+			if errOut.String() != want {
+				t.Errorf("want the report once: %q, got: %q", want, errOut.String())
+			}
+		})
+	}
+}
@@ -34,17 +34,17 @@ func (p *Profiler) SetInReader(f *os.File) {
 	p.inReader = f
 }
 
-func (p *Profiler) Open(filename string) (*os.File, error) {
-	var f *os.File
-	var err error
+// Open opens the log, it keeps reading the file as it grows with --follow
+func (p *Profiler) Open(filename string) (io.ReadCloser, error) {
+	if filename == "" {
+		return p.inReader, nil
+	}
 
-	if filename != "" {
-		f, err = os.Open(filename)
-	} else {
-		f = p.inReader
+	if p.options.Follow {
+		return newFollowReader(filename)
 	}
 
-	return f, err
+	return os.Open(filename)
 }
 
 func (p *Profiler) OpenPosFile(filename string) (*os.File, error) {
@@ -62,28 +62,26 @@ func (p *Profiler) ReadPosFile(f *os.File) (int, error) {
 }
 
 func (p *Profiler) Run(sortOptions *stats.SortOptions, parser parsers.Parser) error {
-	sts := stats.NewHTTPStats(true, false, false)
-	tsts := stats.NewTraceStats(true, false, false)
-
-	err := sts.InitFilter(p.options)
-	if err != nil {
-		return err
+	if p.options.Bucket > 0 {
+		if err := stats.ValidateSeriesFormat(p.options.Format); err != nil {
+			return err
+		}
 	}
 
-	err = tsts.InitFilter(p.options)
+	sts, tsts, parser, err := p.setup(sortOptions, parser)
 	if err != nil {
 		return err
 	}
 
-	sts.SetOptions(p.options)
-	sts.SetSortOptions(sortOptions)
-	tsts.SetOptions(p.options)
-	tsts.SetSortOptions(sortOptions)
-
 	tracePrintOptions := stats.NewTracePrintOptions(p.options.NoHeaders, p.options.ShowFooters, p.options.DecodeUri, p.options.PaginationLimit)
 	tracePrinter := stats.NewTracePrinter(p.outWriter, p.options.Output, p.options.Format, p.options.Percentiles, tracePrintOptions)
+	tracePrinter.SetTraceIDs(p.options.TraceIDs)
+	if err = tracePrinter.SetLocation(p.options.Location); err != nil {
+		return err
+	}
 	printOptions := stats.NewPrintOptions(p.options.NoHeaders, p.options.ShowFooters, p.options.DecodeUri, p.options.PaginationLimit)
 	printer := stats.NewPrinter(p.outWriter, p.options.Output, p.options.Format, p.options.Percentiles, printOptions)
+	printer.SetGroupBy(p.options.GroupBy)
 	if p.options.Trace {
 		if err = tracePrinter.Validate(); err != nil {
 			return err
@@ -94,6 +92,12 @@ func (p *Profiler) Run(sortOptions *stats.SortOptions, parser parsers.Parser) er
 		}
 	}
 
+	if p.options.Histogram != "" {
+		if err = stats.ValidateHistogramFormat(p.options.Format); err != nil {
+			return err
+		}
+	}
+
 	// TODO traceは現在loadに非対応
 	if p.options.Load != "" {
 		lf, err := os.Open(p.options.Load)
@@ -107,18 +111,19 @@ func (p *Profiler) Run(sortOptions *stats.SortOptions, parser parsers.Parser) er
 		defer lf.Close()
 
 		sts.SortWithOptions()
-		printer.Print(sts, nil)
-		return nil
+		if p.options.Histogram != "" {
+			return p.printHistogram(sts, tsts)
+		}
+		return printer.Print(sts, nil)
 	}
 
-	if len(p.options.MatchingGroups) > 0 {
-		err = sts.SetURIMatchingGroups(p.options.MatchingGroups)
-		if err != nil {
-			return err
+	if p.options.Follow {
+		if p.options.Dump != "" {
+			return fmt.Errorf("--dump cannot be used with --follow")
 		}
-		err = tsts.SetURIMatchingGroups(p.options.MatchingGroups)
-		if err != nil {
-			return err
+
+		if p.options.PosFile != "" {
+			return fmt.Errorf("--pos cannot be used with --follow")
 		}
 	}
 
@@ -143,49 +148,31 @@ func (p *Profiler) Run(sortOptions *stats.SortOptions, parser parsers.Parser) er
 		parser.SetReadBytes(pos)
 	}
 
-Loop:
-	for {
-		s, err := parser.Parse()
-		if err != nil {
-			if err == io.EOF {
-				break
-			} else if err == errors.SkipReadLineErr {
-				continue Loop
-			}
-
-			return err
-		}
-
-		var b bool
-		b, err = sts.DoFilter(s)
-		if err != nil {
-			return err
-		}
-		// 2回filterする理由がない
-		//b, err = tsts.DoFilter(s)
-		//if err != nil {
-		//	return err
-		//}
-
-		if !b {
-			continue Loop
-		}
-
-		sts.Set(s.Uri, s.Method, s.Status, s.ResponseTime, s.BodyBytes, 0)
-
-		if p.options.Trace {
-			tsts.AppendTrace(s.TraceID, s.Uri, s.Method, s.Status, s.ResponseTime, s.BodyBytes, 0, parser.ReadBytes())
-		}
+	if p.options.Follow {
+		return p.follow(sortOptions, sts, tsts, parser, printer, tracePrinter)
+	}
 
-		//if sts.CountUris() > p.options.Limit {
-		//	return fmt.Errorf("Too many URI's (%d or less)", p.options.Limit)
-		//}
+	report := newParseReport()
+	err = p.parse(parser, sts, report, p.options.Trace, func(s *parsers.ParsedHTTPStat) {
+		p.set(sts, tsts, s, parser.ReadBytes(), report.read, report)
+	})
+	if err != nil {
+		return err
 	}
 
+	report.print(p.errWriter)
+
 	if p.options.Trace {
 		tsts.AggregateTrace()
 	}
 
+	// the series are printed before the pos file is saved, so that the lines are read again on failure
+	if p.options.Bucket > 0 {
+		if err = p.printSeries(sts, tsts); err != nil {
+			return err
+		}
+	}
+
 	if p.options.Dump != "" {
 		df, err := os.OpenFile(p.options.Dump, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
 		defer df.Close()
@@ -210,16 +197,314 @@ Loop:
 		}
 	}
 
+	if p.options.Bucket > 0 {
+		return nil
+	}
+
+	return p.print(sts, tsts, printer, tracePrinter)
+}
+
+// parse reads the log to the end, and calls fn with each request that passes the filters,
+// the lines without trace ID are skipped if traced
+func (p *Profiler) parse(parser parsers.Parser, sts *stats.HTTPStats, report *parseReport, traced bool, fn func(s *parsers.ParsedHTTPStat)) error {
+	for {
+		s, err := parser.Parse()
+		if err == io.EOF {
+			return nil
+		}
+
+		if err = p.profile(s, err, sts, report, traced, fn); err != nil {
+			return err
+		}
+	}
+}
+
+// profile counts the parsed line in the report, and calls fn if the request passes the filters
+func (p *Profiler) profile(s *parsers.ParsedHTTPStat, parseErr error, sts *stats.HTTPStats, report *parseReport, traced bool, fn func(s *parsers.ParsedHTTPStat)) error {
+	report.read++
+	if parseErr == nil && traced && s.TraceID == "" {
+		parseErr = errMissingTraceID(p.options.Strict)
+	}
+
+	if parseErr != nil {
+		if errors.IsSkipReadLine(parseErr) {
+			report.skip(report.read, parseErr)
+			return nil
+		}
+
+		return fmt.Errorf("line %d: %w", report.read, parseErr)
+	}
+
+	b, err := sts.DoFilter(s)
+	if err != nil {
+		return err
+	}
+
+	if !b {
+		report.filtered++
+		return nil
+	}
+	report.profiled++
+
+	fn(s)
+
+	return nil
+}
+
+// Show prints the waterfall of the requests of the traces
+func (p *Profiler) Show(parser parsers.Parser, traceIDs []string) error {
+	sts, tsts, parser, err := p.setup(stats.NewSortOptions(), parser)
+	if err != nil {
+		return err
+	}
+
+	targets := make(map[string]struct{}, len(traceIDs))
+	for _, traceID := range traceIDs {
+		targets[traceID] = struct{}{}
+	}
+
+	report := newParseReport()
+	err = p.parse(parser, sts, report, true, func(s *parsers.ParsedHTTPStat) {
+		if _, ok := targets[s.TraceID]; ok {
+			tsts.AppendTrace(s.TraceID, s.Uri, s.Method, s.Time, s.Status, s.ResponseTime, s.BodyBytes, s.RequestBodyBytes, s.TimeBreakdown, parser.ReadBytes())
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	report.print(p.errWriter)
+
+	tsts.AggregateTrace()
+
+	return tsts.PrintWaterfall(p.outWriter, traceIDs, p.options.Location)
+}
+
+// Extract writes the lines of the traces that belong to the scenario and are in the trace IDs,
+// the empty scenario ID and trace IDs match all the traces.
+// newParser opens the log for each read, the log is read twice with the scenario ID,
+// first to select the traces of the scenario, then to write their lines
+func (p *Profiler) Extract(newParser func() (parsers.Parser, error), scenarioID string, traceIDs []string, w io.Writer) error {
+	targets := make(map[string]struct{}, len(traceIDs))
+	for _, traceID := range traceIDs {
+		targets[traceID] = struct{}{}
+	}
+
+	selected := func(traceID string) bool {
+		_, ok := targets[traceID]
+		return len(targets) == 0 || ok
+	}
+
+	report := newParseReport()
+	if scenarioID != "" {
+		parser, err := newParser()
+		if err != nil {
+			return err
+		}
+
+		sts, tsts, parser, err := p.setup(stats.NewSortOptions(), parser)
+		if err != nil {
+			return err
+		}
+
+		err = p.parse(parser, sts, report, true, func(s *parsers.ParsedHTTPStat) {
+			if selected(s.TraceID) {
+				tsts.AppendTrace(s.TraceID, s.Uri, s.Method, s.Time, s.Status, s.ResponseTime, s.BodyBytes, s.RequestBodyBytes, s.TimeBreakdown, parser.ReadBytes())
+			}
+		})
+		if err != nil {
+			return err
+		}
+
+		tsts.AggregateTrace()
+		scenarioIDs := tsts.TraceScenarioIDs()
+		selected = func(traceID string) bool {
+			return scenarioIDs[traceID] == scenarioID
+		}
+	}
+
+	parser, err := newParser()
+	if err != nil {
+		return err
+	}
+
+	sts, _, parser, err := p.setup(stats.NewSortOptions(), parser)
+	if err != nil {
+		return err
+	}
+
+	// the lines are already reported in the first read
+	streamReport := report
+	if scenarioID != "" {
+		streamReport = newParseReport()
+	}
+
+	var writeErr error
+	bw := bufio.NewWriter(w)
+	err = p.parse(parser, sts, streamReport, true, func(s *parsers.ParsedHTTPStat) {
+		if writeErr != nil || !selected(s.TraceID) {
+			return
+		}
+
+		if _, writeErr = bw.Write(s.Raw); writeErr != nil {
+			return
+		}
+		writeErr = bw.WriteByte('\n')
+	})
+	if err != nil {
+		return err
+	}
+	if writeErr != nil {
+		return writeErr
+	}
+
+	report.print(p.errWriter)
+
+	return bw.Flush()
+}
+
+// setup makes the stats, and wraps the parser to use the sessions as the traces with --session-key
+func (p *Profiler) setup(sortOptions *stats.SortOptions, parser parsers.Parser) (*stats.HTTPStats, *stats.TraceStats, parsers.Parser, error) {
+	sts, tsts, err := p.newStats(sortOptions)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if len(p.options.SessionKeys) > 0 {
+		parser, err = parsers.NewSessionParser(parser, p.options.SessionKeys, p.options.SessionGap, p.options.Location)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	return sts, tsts, parser, nil
+}
+
+// newStats makes the stats with the options, the filters and the matching groups
+func (p *Profiler) newStats(sortOptions *stats.SortOptions) (*stats.HTTPStats, *stats.TraceStats, error) {
+	sts := stats.NewHTTPStats(true, false, false)
+	tsts := stats.NewTraceStats(true, false, false)
+
+	err := sts.InitFilter(p.options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = tsts.InitFilter(p.options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sts.SetOptions(p.options)
+	sts.SetSortOptions(sortOptions)
+	sts.SetGroupBy(p.options.GroupBy)
+	tsts.SetOptions(p.options)
+	tsts.SetSortOptions(sortOptions)
+
+	if len(p.options.MatchingGroups) > 0 {
+		err = sts.SetURIMatchingGroups(p.options.MatchingGroups)
+		if err != nil {
+			return nil, nil, err
+		}
+		err = tsts.SetURIMatchingGroups(p.options.MatchingGroups)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if p.options.Histogram != "" {
+		if p.options.Bucket > 0 {
+			return nil, nil, fmt.Errorf("--histogram cannot be used with --bucket")
+		}
+
+		bounds, err := stats.ParseHistogramBuckets(p.options.Histogram)
+		if err != nil {
+			return nil, nil, err
+		}
+		sts.SetHistogram(bounds)
+		tsts.SetHistogram(bounds)
+	}
+
+	if p.options.Bucket > 0 {
+		if p.options.Trace {
+			err = tsts.SetBucket(p.options.Bucket, p.options.Location)
+		} else {
+			err = sts.SetBucket(p.options.Bucket, p.options.Location)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return sts, tsts, nil
+}
+
+// set adds the request at the line and the position of the log to the stats
+func (p *Profiler) set(sts *stats.HTTPStats, tsts *stats.TraceStats, s *parsers.ParsedHTTPStat, pos, line int, report *parseReport) {
+	groups := sts.Groups(s.Entries)
+	sts.SetWithService(s.Service, s.Uri, s.Method, groups, s.Status, s.ResponseTime, s.BodyBytes, s.RequestBodyBytes)
+	if s.TCP != nil {
+		sts.SetTCPMetrics(s.Service, s.Uri, s.Method, groups, s.TCP)
+	}
+	if s.TimeBreakdown != nil {
+		sts.SetTimeBreakdown(s.Service, s.Uri, s.Method, groups, s.TimeBreakdown)
+	}
+	if p.options.Bucket > 0 && !p.options.Trace {
+		if err := sts.AppendSeries(s.Time, s.Uri, s.Method, groups, s.Status, s.ResponseTime); err != nil {
+			report.unbucketed(line)
+		}
+	}
+
+	// the requests without trace ID are skipped while parsing, unless the trace mode is switched afterwards
+	if p.options.Trace && s.TraceID != "" {
+		if p.options.Bucket > 0 {
+			if err := tsts.AppendTraceTime(s.TraceID, s.Time); err != nil {
+				report.unbucketed(line)
+			}
+		}
+		tsts.AppendTrace(s.TraceID, s.Uri, s.Method, s.Time, s.Status, s.ResponseTime, s.BodyBytes, s.RequestBodyBytes, s.TimeBreakdown, pos)
+	}
+}
+
+// print outputs the stats in the format, the trace stats must be aggregated
+func (p *Profiler) print(sts *stats.HTTPStats, tsts *stats.TraceStats, printer *stats.Printer, tracePrinter *stats.TracePrinter) error {
+	if p.options.Bucket > 0 {
+		return p.printSeries(sts, tsts)
+	}
+
 	sts.SortWithOptions()
 	tsts.SortWithOptions()
 	tsts.TrimAfterLimit()
 
 	// limitを適用
 	tsts.SortWithOptions()
+	if p.options.Histogram != "" {
+		return p.printHistogram(sts, tsts)
+	}
 	if p.options.Trace {
-		tracePrinter.Print(tsts, nil)
+		return tracePrinter.Print(tsts, nil)
+	}
+	return printer.Print(sts, nil)
+}
+
+func (p *Profiler) printSeries(sts *stats.HTTPStats, tsts *stats.TraceStats) error {
+	if p.options.Trace {
+		return tsts.PrintSeries(p.outWriter, p.options.Format)
+	}
+	return sts.PrintSeries(p.outWriter, p.options.Format)
+}
+
+func (p *Profiler) printHistogram(sts *stats.HTTPStats, tsts *stats.TraceStats) error {
+	var h *stats.Histogram
+	var err error
+	if p.options.Trace {
+		h, err = tsts.Histogram()
 	} else {
-		printer.Print(sts, nil)
+		h, err = sts.Histogram()
 	}
-	return nil
+	if err != nil {
+		return err
+	}
+
+	return h.Print(p.outWriter, p.options.Format)
 }
@@ -0,0 +1,159 @@
+package profiler
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/tetsuzawa/alp-trace/options"
+	"github.com/tetsuzawa/alp-trace/parsers"
+	"github.com/tetsuzawa/alp-trace/stats"
+)
+
+const testLog = `{"uri":"/login","method":"POST","status":200,"response_time":0.1,"body_bytes":10,"trace_id":"a"}
+{"uri":"/login","method":"POST","status":200,"response_time":0.1,"body_bytes":10,"trace_id":"b"}
+{"uri":"/home","method":"GET","status":200,"response_time":0.2,"body_bytes":10,"trace_id":"a"}
+{"uri":"/home","method":"GET","status":500,"response_time":0.3,"body_bytes":10,"trace_id":"b"}
+{"uri":"/login","method":"POST","status":200,"response_time":0.1,"body_bytes":10,"trace_id":"c"}
+{"uri":"/home","method":"GET","status":200,"response_time":0.2,"body_bytes":10,"trace_id":"c"}
+{"uri":"/favicon.ico","method":"GET","status":200,"response_time":0.1,"body_bytes":10}
+`
+
+var testJSONKeys = parsers.NewJSONKeys("uri", "method", "time", "response_time", "request_time", "body_bytes", "", "status", "trace_id")
+
+// testLogParser opens the log for each read, and counts the reads
+type testLogParser struct {
+	log   string
+	reads int
+}
+
+func (tp *testLogParser) newParser() (parsers.Parser, error) {
+	tp.reads++
+	return parsers.NewJSONParser(strings.NewReader(tp.log), testJSONKeys, false, false, false), nil
+}
+
+func TestExtract(t *testing.T) {
+	lines := strings.Split(strings.TrimSuffix(testLog, "\n"), "\n")
+
+	tests := []struct {
+		name       string
+		opts       []options.Option
+		scenarioID string
+		traceIDs   []string
+		want       []string
+		wantReads  int
+	}{
+		{
+			name:      "trace ids",
+			traceIDs:  []string{"c", "a"},
+			want:      []string{lines[0], lines[2], lines[4], lines[5]},
+			wantReads: 1,
+		},
+		{
+			name:       "scenario",
+			scenarioID: "20101606",
+			want:       []string{lines[0], lines[2], lines[4], lines[5]},
+			wantReads:  2,
+		},
+		{
+			name:       "scenario and trace ids",
+			scenarioID: "20101606",
+			traceIDs:   []string{"b", "c"},
+			want:       []string{lines[4], lines[5]},
+			wantReads:  2,
+		},
+		{
+			name:       "unknown scenario",
+			scenarioID: "00000000",
+			want:       []string{},
+			wantReads:  2,
+		},
+		{
+			name:      "filters",
+			opts:      []options.Option{options.Filters("Status == 200")},
+			traceIDs:  []string{"b"},
+			want:      []string{lines[1]},
+			wantReads: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out, errOut bytes.Buffer
+			prof := NewProfiler(&out, &errOut, options.NewOptions(tt.opts...))
+
+			tp := &testLogParser{log: testLog}
+			var w bytes.Buffer
+			if err := prof.Extract(tp.newParser, tt.scenarioID, tt.traceIDs, &w); err != nil {
+				t.Fatal(err)
+			}
+
+			want := strings.Join(tt.want, "\n")
+			if len(tt.want) > 0 {
+				want += "\n"
+			}
+			if w.String() != want {
+				t.Errorf("want:\n%s\ngot:\n%s", want, w.String())
+			}
+			if tp.reads != tt.wantReads {
+				t.Errorf("want %d reads, got: %d", tt.wantReads, tp.reads)
+			}
+
+			// the lines are reported once, and the line without trace ID is skipped
+			if got := strings.Count(errOut.String(), "lines read"); got != 1 {
+				t.Errorf("want the report once, got: %q", errOut.String())
+			}
+			if !strings.Contains(errOut.String(), "7 lines read") {
+				t.Errorf("want 7 lines read, got: %q", errOut.String())
+			}
+		})
+	}
+}
+
+func TestRunMissingTraceID(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       []options.Option
+		wantReport string
+		wantErr    string
+	}{
+		{
+			name:       "endpoints",
+			opts:       []options.Option{options.Strict(true)},
+			wantReport: "7 lines read, 7 profiled, 0 filtered, 0 skipped\n",
+		},
+		{
+			name:       "trace",
+			opts:       []options.Option{options.Trace(true)},
+			wantReport: "7 lines read, 6 profiled, 0 filtered, 1 skipped\n  missing trace ID: 1 (lines 7)\n",
+		},
+		{
+			name:    "trace strict",
+			opts:    []options.Option{options.Trace(true), options.Strict(true)},
+			wantErr: "line 7: missing trace ID",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out, errOut bytes.Buffer
+			prof := NewProfiler(&out, &errOut, options.NewOptions(append(tt.opts, options.Format("json"))...))
+
+			parser := parsers.NewJSONParser(strings.NewReader(testLog), testJSONKeys, false, false, prof.options.Strict)
+			err := prof.Run(stats.NewSortOptions(), parser)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("want error: %s, got: %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if errOut.String() != tt.wantReport {
+				t.Errorf("want report: %q, got: %q", tt.wantReport, errOut.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package profiler
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/tetsuzawa/alp-trace/errors"
+	"github.com/tetsuzawa/alp-trace/parsers"
+)
+
+const (
+	maxReportSampleLines  = 5
+	reasonInvalidTime     = "unparsable time"
+	reasonUnknownSkipLine = "unknown"
+)
+
+// parseReport counts the lines read and skipped while profiling
+type parseReport struct {
+	read     int
+	profiled int
+	filtered int
+	skipped  int
+	reasons  []string
+	lines    map[string]*reportLines
+}
+
+type reportLines struct {
+	count   int
+	samples []int
+}
+
+func newParseReport() *parseReport {
+	return &parseReport{
+		lines: make(map[string]*reportLines),
+	}
+}
+
+// errMissingTraceID is the error of the line that cannot be traced, it aborts the profiling in the strict mode
+func errMissingTraceID(strictMode bool) error {
+	if strictMode {
+		return fmt.Errorf("%s", parsers.ReasonMissingTraceID)
+	}
+
+	return &errors.SkipReadLineError{
+		Reason: parsers.ReasonMissingTraceID,
+	}
+}
+
+func (r *parseReport) skip(line int, err error) {
+	reason := reasonUnknownSkipLine
+	if se, ok := err.(*errors.SkipReadLineError); ok {
+		reason = se.Reason
+	}
+
+	r.skipped++
+	r.add(reason, line)
+}
+
+// unbucketed counts the lines that are profiled but not in the time series
+func (r *parseReport) unbucketed(line int) {
+	r.add(reasonInvalidTime, line)
+}
+
+func (r *parseReport) add(reason string, line int) {
+	l, ok := r.lines[reason]
+	if !ok {
+		l = &reportLines{}
+		r.lines[reason] = l
+		r.reasons = append(r.reasons, reason)
+	}
+
+	l.count++
+	if len(l.samples) < maxReportSampleLines {
+		l.samples = append(l.samples, line)
+	}
+}
+
+func (r *parseReport) print(w io.Writer) {
+	fmt.Fprintf(w, "%d lines read, %d profiled, %d filtered, %d skipped\n", r.read, r.profiled, r.filtered, r.skipped)
+
+	for _, reason := range r.reasons {
+		l := r.lines[reason]
+
+		samples := make([]string, len(l.samples))
+		for i, line := range l.samples {
+			samples[i] = fmt.Sprint(line)
+		}
+		if l.count > len(l.samples) {
+			samples = append(samples, "...")
+		}
+
+		label := reason
+		switch reason {
+		case reasonInvalidTime:
+			label = fmt.Sprintf("%s (not bucketed)", reason)
+		}
+
+		fmt.Fprintf(w, "  %s: %d (lines %s)\n", label, l.count, strings.Join(samples, ", "))
+	}
+}
@@ -0,0 +1,277 @@
+package profiler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/tetsuzawa/alp-trace/html"
+	"github.com/tetsuzawa/alp-trace/options"
+	"github.com/tetsuzawa/alp-trace/parsers"
+	"github.com/tetsuzawa/alp-trace/stats"
+)
+
+const (
+	serverReadTimeout     = 10 * time.Second
+	serverWriteTimeout    = 30 * time.Second
+	serverIdleTimeout     = 60 * time.Second
+	serverShutdownTimeout = 5 * time.Second
+)
+
+// server keeps the requests of the log, and the stats for each matching groups and filters of the API requests,
+// the responses are cached until a request is read
+type server struct {
+	profiler    *Profiler
+	sortOptions *stats.SortOptions
+
+	mu         sync.Mutex
+	entries    *followEntries
+	aggregates *aggregates
+	cache      map[string][]byte
+	// cacheRead is the number of the requests read when the responses are cached
+	cacheRead int
+}
+
+func newServer(p *Profiler, sortOptions *stats.SortOptions) (*server, error) {
+	entries, err := newFollowEntries(p.options.Location, p.options.Window)
+	if err != nil {
+		return nil, err
+	}
+
+	return &server{
+		profiler:    p,
+		sortOptions: sortOptions,
+		entries:     entries,
+		aggregates:  newAggregates(entries),
+		cache:       make(map[string][]byte),
+	}, nil
+}
+
+// Serve serves the dashboard and the JSON API of the endpoints and the scenarios until it is interrupted,
+// the log is read in the background and kept read as it grows with --follow
+func (p *Profiler) Serve(listen string, sortOptions *stats.SortOptions, parser parsers.Parser) error {
+	// the stats only to filter the requests
+	filter, _, parser, err := p.setup(sortOptions, parser)
+	if err != nil {
+		return err
+	}
+
+	srv, err := newServer(p, sortOptions)
+	if err != nil {
+		return err
+	}
+
+	ln, err := net.Listen("tcp", listen)
+	if err != nil {
+		return err
+	}
+
+	httpServer := &http.Server{
+		Handler:      srv.handler(),
+		ReadTimeout:  serverReadTimeout,
+		WriteTimeout: serverWriteTimeout,
+		IdleTimeout:  serverIdleTimeout,
+	}
+
+	errCh := make(chan error, 2)
+	go func() {
+		report := newParseReport()
+		err := p.parse(parser, filter, report, p.options.Trace, func(s *parsers.ParsedHTTPStat) {
+			srv.add(s, parser.ReadBytes(), report.read)
+		})
+		if err != nil {
+			errCh <- err
+			return
+		}
+
+		report.print(p.errWriter)
+	}()
+
+	go func() {
+		if err := httpServer.Serve(ln); err != http.ErrServerClosed {
+			errCh <- err
+		}
+	}()
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+	defer signal.Stop(sig)
+
+	fmt.Fprintf(p.errWriter, "Serving on http://%s\n", ln.Addr())
+
+	select {
+	case err = <-errCh:
+		httpServer.Close()
+		return err
+	case <-sig:
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), serverShutdownTimeout)
+	defer cancel()
+
+	return httpServer.Shutdown(ctx)
+}
+
+func (srv *server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", srv.handleDashboard)
+	mux.HandleFunc("/api/endpoints", srv.handleEndpoints)
+	mux.HandleFunc("/api/scenarios", srv.handleScenarios)
+
+	return mux
+}
+
+// add keeps the request read from the log
+func (srv *server) add(s *parsers.ParsedHTTPStat, pos, line int) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	srv.entries.append(s, pos, line)
+}
+
+func (srv *server) handleDashboard(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+
+	content, err := html.RenderDashboard("alp-trace", srv.profiler.options.PaginationLimit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, content)
+}
+
+func (srv *server) handleEndpoints(w http.ResponseWriter, r *http.Request) {
+	srv.serveJSON(w, r, false)
+}
+
+func (srv *server) handleScenarios(w http.ResponseWriter, r *http.Request) {
+	srv.serveJSON(w, r, true)
+}
+
+// serveJSON outputs the stats in the json format, the parameters override the options
+func (srv *server) serveJSON(w http.ResponseWriter, r *http.Request, trace bool) {
+	p, sortOptions, err := srv.requestProfiler(r, trace)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	cacheKey := fmt.Sprintf("%t\n%s\n%t\n%d\n%s", trace, r.URL.Query().Get("sort"), p.options.Reverse, p.options.Limit, aggregateKey(p.options))
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	if read := srv.entries.read(); srv.cacheRead != read {
+		srv.cache = make(map[string][]byte)
+		srv.cacheRead = read
+	}
+
+	body, ok := srv.cache[cacheKey]
+	if !ok {
+		body, err = srv.render(p, sortOptions, trace)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		srv.cache[cacheKey] = body
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
+
+// render outputs the stats of the matching groups and the filters in the json format, srv.mu must be locked
+func (srv *server) render(p *Profiler, sortOptions *stats.SortOptions, trace bool) ([]byte, error) {
+	agg, err := srv.aggregates.get(p, sortOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if trace {
+		// the scenarios are trimmed by the limit of the last API request
+		agg.tsts.Reaggregate()
+		agg.tsts.SortWithOptions()
+		agg.tsts.TrimAfterLimit()
+
+		printOptions := stats.NewTracePrintOptions(false, false, p.options.DecodeUri, p.options.PaginationLimit)
+		if err = stats.NewTracePrinter(&buf, p.options.Output, "json", p.options.Percentiles, printOptions).Print(agg.tsts, nil); err != nil {
+			return nil, err
+		}
+	} else {
+		agg.sts.SortWithOptions()
+
+		printOptions := stats.NewPrintOptions(false, false, p.options.DecodeUri, p.options.PaginationLimit)
+		printer := stats.NewPrinter(&buf, p.options.Output, "json", p.options.Percentiles, printOptions)
+		printer.SetGroupBy(p.options.GroupBy)
+		if err = printer.Print(agg.sts, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// requestProfiler returns the profiler with the options of the sort, reverse, limit, matching_groups and filters parameters
+func (srv *server) requestProfiler(r *http.Request, trace bool) (*Profiler, *stats.SortOptions, error) {
+	q := r.URL.Query()
+
+	opts := *srv.profiler.options
+	opts.Trace = trace
+	opts.Bucket = 0
+
+	limit := 0
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid limit: %s", v)
+		}
+		limit = n
+	}
+
+	if v := q.Get("reverse"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid reverse: %s", v)
+		}
+		opts.Reverse = b
+	}
+
+	options.SetOptions(&opts,
+		options.Limit(limit),
+		options.CSVGroups(q.Get("matching_groups")),
+		options.Filters(q.Get("filters")),
+	)
+
+	if opts.Filters != "" {
+		if err := stats.NewFilter(&opts).Validate(); err != nil {
+			return nil, nil, fmt.Errorf("invalid filters: %w", err)
+		}
+	}
+
+	sortOptions := *srv.sortOptions
+	if v := q.Get("sort"); v != "" {
+		if err := sortOptions.SetAndValidate(v); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return &Profiler{
+		options:   &opts,
+		outWriter: srv.profiler.outWriter,
+		errWriter: srv.profiler.errWriter,
+		inReader:  srv.profiler.inReader,
+	}, &sortOptions, nil
+}
@@ -0,0 +1,340 @@
+package profiler
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tetsuzawa/alp-trace/options"
+	"github.com/tetsuzawa/alp-trace/parsers"
+	"github.com/tetsuzawa/alp-trace/stats"
+)
+
+// newTestServer makes the server with the requests of the log
+func newTestServer(t *testing.T, log string, opts ...options.Option) *server {
+	t.Helper()
+
+	prof := NewProfiler(io.Discard, io.Discard, options.NewOptions(append([]options.Option{options.Location("UTC")}, opts...)...))
+
+	sortOptions := stats.NewSortOptions()
+	if err := sortOptions.SetAndValidate("uri"); err != nil {
+		t.Fatal(err)
+	}
+
+	srv, err := newServer(prof, sortOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addTestLog(t, srv, log)
+
+	return srv
+}
+
+// addTestLog reads the requests of the log to the server as Serve,
+// the lines without trace ID are skipped in the trace mode
+func addTestLog(t *testing.T, srv *server, log string) {
+	t.Helper()
+
+	filter, _, err := srv.profiler.newStats(srv.sortOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parser := parsers.NewJSONParser(strings.NewReader(log), testJSONKeys, false, false, false)
+	report := newParseReport()
+	err = srv.profiler.parse(parser, filter, report, srv.profiler.options.Trace, func(s *parsers.ParsedHTTPStat) {
+		srv.add(s, parser.ReadBytes(), report.read)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// getTestServer requests the path, and returns the status code and the body
+func getTestServer(srv *server, path string) (int, string) {
+	rec := httptest.NewRecorder()
+	srv.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+	return rec.Code, rec.Body.String()
+}
+
+// endpointCounts returns the uri and the count of the endpoints of the response
+func endpointCounts(t *testing.T, body string) []string {
+	t.Helper()
+
+	var result struct {
+		Endpoints []struct {
+			Uri   string `json:"uri"`
+			Count int    `json:"count"`
+		} `json:"endpoints"`
+	}
+	if err := json.Unmarshal([]byte(body), &result); err != nil {
+		t.Fatalf("%v: %s", err, body)
+	}
+
+	counts := make([]string, 0, len(result.Endpoints))
+	for _, e := range result.Endpoints {
+		counts = append(counts, fmt.Sprintf("%s %d", e.Uri, e.Count))
+	}
+
+	return counts
+}
+
+// scenarioCounts returns the count of the scenarios of the response
+func scenarioCounts(t *testing.T, body string) []string {
+	t.Helper()
+
+	var result struct {
+		Scenarios []struct {
+			Count int `json:"count"`
+		} `json:"scenarios"`
+	}
+	if err := json.Unmarshal([]byte(body), &result); err != nil {
+		t.Fatalf("%v: %s", err, body)
+	}
+
+	counts := make([]string, 0, len(result.Scenarios))
+	for _, s := range result.Scenarios {
+		counts = append(counts, fmt.Sprint(s.Count))
+	}
+
+	return counts
+}
+
+func TestServerAPI(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		want       []string
+	}{
+		{
+			name:       "endpoints",
+			path:       "/api/endpoints",
+			wantStatus: http.StatusOK,
+			want:       []string{"/home 3", "/login 3"},
+		},
+		{
+			name:       "reverse",
+			path:       "/api/endpoints?reverse=true",
+			wantStatus: http.StatusOK,
+			want:       []string{"/login 3", "/home 3"},
+		},
+		{
+			name:       "matching groups",
+			path:       "/api/endpoints?matching_groups=/log.%2B",
+			wantStatus: http.StatusOK,
+			want:       []string{"/home 3", "/log.+ 3"},
+		},
+		{
+			name:       "filters",
+			path:       "/api/endpoints?filters=" + strings.ReplaceAll("Status >= 500", " ", "+"),
+			wantStatus: http.StatusOK,
+			want:       []string{"/home 1"},
+		},
+		{
+			name:       "scenarios",
+			path:       "/api/scenarios?sort=count",
+			wantStatus: http.StatusOK,
+			want:       []string{"1", "2"},
+		},
+		{
+			name:       "scenarios limit",
+			path:       "/api/scenarios?sort=count&reverse=true&limit=1",
+			wantStatus: http.StatusOK,
+			want:       []string{"2"},
+		},
+		{
+			name:       "invalid filters",
+			path:       "/api/endpoints?filters=Status+%3E%3D",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			// the argument is only found invalid when the filters are run
+			name:       "invalid filters argument",
+			path:       "/api/scenarios?filters=" + strings.ReplaceAll(`Time > TimeAgo("5x")`, " ", "+"),
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid sort",
+			path:       "/api/endpoints?sort=unknown",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid limit",
+			path:       "/api/scenarios?limit=many",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid reverse",
+			path:       "/api/scenarios?reverse=maybe",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	srv := newTestServer(t, testLog, options.Trace(true))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := getTestServer(srv, tt.path)
+			if status != tt.wantStatus {
+				t.Fatalf("want status: %d, got: %d: %s", tt.wantStatus, status, body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var got []string
+			if strings.HasPrefix(tt.path, "/api/scenarios") {
+				got = scenarioCounts(t, body)
+			} else {
+				got = endpointCounts(t, body)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("want: %v, got: %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestServerUpdate(t *testing.T) {
+	srv := newTestServer(t, testLog, options.Trace(true))
+
+	_, body := getTestServer(srv, "/api/endpoints")
+	if _, cached := getTestServer(srv, "/api/endpoints"); cached != body {
+		t.Errorf("want the cached response: %s, got: %s", body, cached)
+	}
+
+	// the trimmed scenarios are aggregated again for the other limit
+	getTestServer(srv, "/api/scenarios?limit=1")
+	if _, body = getTestServer(srv, "/api/scenarios?sort=count"); strings.Join(scenarioCounts(t, body), ",") != "1,2" {
+		t.Errorf("want the all scenarios, got: %s", body)
+	}
+
+	addTestLog(t, srv, `{"uri":"/login","method":"POST","status":200,"response_time":0.1,"body_bytes":10,"trace_id":"d"}
+{"uri":"/logout","method":"POST","status":200,"response_time":0.1,"body_bytes":10,"trace_id":"d"}
+`)
+
+	_, body = getTestServer(srv, "/api/endpoints")
+	want := []string{"/home 3", "/login 4", "/logout 1"}
+	if got := endpointCounts(t, body); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("want: %v, got: %v", want, got)
+	}
+
+	_, body = getTestServer(srv, "/api/scenarios?sort=count")
+	if got := scenarioCounts(t, body); strings.Join(got, ",") != "1,1,2" {
+		t.Errorf("want the new scenario, got: %v", got)
+	}
+
+	// the stats are updated by the new requests, not made again
+	if len(srv.aggregates.stats) != 1 {
+		t.Errorf("want 1 aggregate, got: %d", len(srv.aggregates.stats))
+	}
+	for _, agg := range srv.aggregates.stats {
+		if agg.next != srv.entries.read() {
+			t.Errorf("want the requests aggregated to %d, got: %d", srv.entries.read(), agg.next)
+		}
+	}
+}
+
+func TestServerWindow(t *testing.T) {
+	log := `{"time":"2024-01-01T00:00:00Z","uri":"/a","method":"GET","status":200,"response_time":0.1,"body_bytes":10,"trace_id":"a"}
+{"time":"2024-01-01T00:00:30Z","uri":"/b","method":"GET","status":200,"response_time":0.1,"body_bytes":10,"trace_id":"b"}
+`
+	srv := newTestServer(t, log, options.Window(time.Minute))
+
+	_, body := getTestServer(srv, "/api/endpoints")
+	if got := strings.Join(endpointCounts(t, body), ","); got != "/a 1,/b 1" {
+		t.Errorf("want the all requests, got: %s", got)
+	}
+
+	// the window is from the latest time of the log
+	addTestLog(t, srv, `{"time":"2024-01-01T00:01:10Z","uri":"/a","method":"GET","status":200,"response_time":0.1,"body_bytes":10,"trace_id":"c"}
+`)
+
+	_, body = getTestServer(srv, "/api/endpoints")
+	if got := strings.Join(endpointCounts(t, body), ","); got != "/a 1,/b 1" {
+		t.Errorf("want the requests in the window, got: %s", got)
+	}
+	for _, agg := range srv.aggregates.stats {
+		if agg.dropped != 1 {
+			t.Errorf("want the stats made without the dropped request, got: %d dropped", agg.dropped)
+		}
+	}
+}
+
+func TestServerDashboard(t *testing.T) {
+	srv := newTestServer(t, testLog)
+
+	status, body := getTestServer(srv, "/")
+	if status != http.StatusOK || !strings.Contains(body, "<html") {
+		t.Errorf("want the dashboard, got: %d: %.100s", status, body)
+	}
+
+	if status, _ = getTestServer(srv, "/unknown"); status != http.StatusNotFound {
+		t.Errorf("want status: %d, got: %d", http.StatusNotFound, status)
+	}
+}
+
+func TestServe(t *testing.T) {
+	r, w := io.Pipe()
+	defer r.Close()
+
+	prof := NewProfiler(io.Discard, w, options.NewOptions())
+	parser := parsers.NewJSONParser(strings.NewReader(testLog), testJSONKeys, false, false, false)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- prof.Serve("127.0.0.1:0", stats.NewSortOptions(), parser)
+		w.Close()
+	}()
+
+	// the report of the log is output while serving
+	errOut := bufio.NewScanner(r)
+	url := ""
+	for url == "" && errOut.Scan() {
+		if strings.HasPrefix(errOut.Text(), "Serving on ") {
+			url = strings.TrimPrefix(errOut.Text(), "Serving on ")
+		}
+	}
+	if url == "" {
+		t.Fatal("the server is not started")
+	}
+	go func() {
+		for errOut.Scan() {
+		}
+	}()
+
+	res, err := http.Get(url + "/api/endpoints?filters=Status+%3E%3D")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusBadRequest {
+		t.Errorf("want status: %d, got: %d", http.StatusBadRequest, res.StatusCode)
+	}
+
+	proc, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = proc.Signal(os.Interrupt); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err = <-done:
+		if err != nil {
+			t.Errorf("want the server shut down, got: %v", err)
+		}
+	case <-time.After(serverShutdownTimeout * 2):
+		t.Fatal("the server is not shut down")
+	}
+}
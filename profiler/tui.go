@@ -0,0 +1,144 @@
+package profiler
+
+import (
+	"bytes"
+
+	"github.com/tetsuzawa/alp-trace/parsers"
+	"github.com/tetsuzawa/alp-trace/stats"
+	"github.com/tetsuzawa/alp-trace/tui"
+)
+
+// explorer makes the tables of the tui from the stats of the requests of the log for each filters,
+// the trace stats are kept to show the waterfalls of the traces in the tables
+type explorer struct {
+	profiler    *Profiler
+	sortOptions *stats.SortOptions
+	aggregates  *aggregates
+	tsts        *stats.TraceStats
+}
+
+// Explore reads the log to the end, and explores the endpoints and the scenarios on the terminal
+func (p *Profiler) Explore(sortOptions *stats.SortOptions, parser parsers.Parser) error {
+	// the stats only to filter the requests
+	filter, _, parser, err := p.setup(sortOptions, parser)
+	if err != nil {
+		return err
+	}
+
+	// all the requests are explored without --follow
+	entries, err := newFollowEntries(p.options.Location, 0)
+	if err != nil {
+		return err
+	}
+
+	report := newParseReport()
+	err = p.parse(parser, filter, report, p.options.Trace, func(s *parsers.ParsedHTTPStat) {
+		entries.append(s, parser.ReadBytes(), report.read)
+	})
+	if err != nil {
+		return err
+	}
+
+	report.print(p.errWriter)
+
+	ex := &explorer{
+		profiler:    p,
+		sortOptions: sortOptions,
+		aggregates:  newAggregates(entries),
+	}
+
+	view := 0
+	if p.options.Trace {
+		view = 1
+	}
+
+	return tui.Run(ex, "alp-trace", view)
+}
+
+// Tables makes the stats of the requests that match the filters, in addition to --filters
+func (ex *explorer) Tables(filters string) ([]*tui.Table, error) {
+	opts := *ex.profiler.options
+	opts.Bucket = 0
+	// the requests already passed --filters
+	opts.Filters = filters
+
+	if filters != "" {
+		if err := stats.NewFilter(&opts).Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	p := &Profiler{
+		options:   &opts,
+		outWriter: ex.profiler.outWriter,
+		errWriter: ex.profiler.errWriter,
+		inReader:  ex.profiler.inReader,
+	}
+
+	agg, err := ex.aggregates.get(p, ex.sortOptions)
+	if err != nil {
+		return nil, err
+	}
+	sts, tsts := agg.sts, agg.tsts
+
+	sts.SortWithOptions()
+	// the scenarios trimmed for the last tables are aggregated again
+	tsts.Reaggregate()
+	tsts.SortWithOptions()
+	tsts.TrimAfterLimit()
+
+	// -o is used for the table of --trace, and the other shows all the columns
+	output, traceOutput := opts.Output, "all"
+	if ex.profiler.options.Trace {
+		output, traceOutput = "all", opts.Output
+	}
+
+	printOptions := stats.NewPrintOptions(false, false, opts.DecodeUri, opts.PaginationLimit)
+	printer := stats.NewPrinter(nil, output, "table", opts.Percentiles, printOptions)
+	printer.SetGroupBy(opts.GroupBy)
+	if err = printer.Validate(); err != nil {
+		return nil, err
+	}
+
+	tracePrintOptions := stats.NewTracePrintOptions(false, false, opts.DecodeUri, opts.PaginationLimit)
+	tracePrinter := stats.NewTracePrinter(nil, traceOutput, "table", opts.Percentiles, tracePrintOptions)
+	if err = tracePrinter.Validate(); err != nil {
+		return nil, err
+	}
+
+	columns, rows := printer.Table(sts)
+	traceColumns, traceRows, traceDetails := tracePrinter.Table(tsts)
+
+	details := make([]*tui.Detail, 0, len(traceDetails))
+	for _, d := range traceDetails {
+		details = append(details, &tui.Detail{
+			Columns:  d.Columns,
+			Rows:     d.Rows,
+			TraceIDs: d.TraceIDs,
+		})
+	}
+
+	ex.tsts = tsts
+
+	return []*tui.Table{
+		{
+			Title:   "Endpoints",
+			Columns: columns,
+			Rows:    rows,
+		},
+		{
+			Title:   "Scenarios",
+			Columns: traceColumns,
+			Rows:    traceRows,
+			Details: details,
+		},
+	}, nil
+}
+
+// Waterfall returns the waterfall of the trace in the last tables
+func (ex *explorer) Waterfall(traceID string) (string, error) {
+	var buf bytes.Buffer
+	err := ex.tsts.PrintWaterfall(&buf, []string{traceID}, ex.profiler.options.Location)
+
+	return buf.String(), err
+}
@@ -0,0 +1,57 @@
+package profiler
+
+import (
+	"testing"
+
+	"github.com/tetsuzawa/alp-trace/options"
+	"github.com/tetsuzawa/alp-trace/stats"
+)
+
+func TestExplorerTables(t *testing.T) {
+	// the requests are read as the server
+	srv := newTestServer(t, testLog, options.Trace(true))
+	ex := &explorer{
+		profiler:    srv.profiler,
+		sortOptions: stats.NewSortOptions(),
+		aggregates:  newAggregates(srv.entries),
+	}
+
+	tests := []struct {
+		filters       string
+		wantEndpoints int
+		wantScenarios int
+		wantErr       bool
+	}{
+		{filters: "", wantEndpoints: 2, wantScenarios: 2},
+		{filters: "Status >= 500", wantEndpoints: 1, wantScenarios: 1},
+		// the stats of the filters are used again
+		{filters: "", wantEndpoints: 2, wantScenarios: 2},
+		{filters: `Time > TimeAgo("5x")`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		tables, err := ex.Tables(tt.filters)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%q: want error, got nil", tt.filters)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(tables[0].Rows) != tt.wantEndpoints || len(tables[1].Rows) != tt.wantScenarios {
+			t.Errorf("%q: want %d endpoints and %d scenarios, got: %d and %d", tt.filters, tt.wantEndpoints, tt.wantScenarios, len(tables[0].Rows), len(tables[1].Rows))
+		}
+	}
+
+	// the stats are kept for each filters
+	if len(ex.aggregates.stats) != 2 {
+		t.Errorf("want 2 aggregates, got: %d", len(ex.aggregates.stats))
+	}
+
+	if _, err := ex.Waterfall("a"); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,135 @@
+package stats
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+const (
+	callGraphMinPenWidth = 1.0
+	callGraphMaxPenWidth = 8.0
+)
+
+var dotReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", " ", "\r", " ")
+
+// CallGraph is the graph of the endpoints and the transitions between them in the traces
+type CallGraph struct {
+	nodeHints *hints
+	edgeHints *hints
+	nodes     []*callGraphNode
+	edges     []*callGraphEdge
+}
+
+type callGraphNode struct {
+	method       string
+	uri          string
+	cnt          int
+	responseTime *responseTime
+}
+
+type callGraphEdge struct {
+	from int
+	to   int
+	cnt  int
+}
+
+// CallGraph builds the graph from the requests of each trace in order,
+// the URIs are already normalized by the matching groups
+func (ts *TraceStats) CallGraph() *CallGraph {
+	g := &CallGraph{
+		nodeHints: newHints(),
+		edgeHints: newHints(),
+	}
+
+	// the traces are sorted to keep the order of the nodes and the edges
+	traceIDs := make([]string, 0, len(ts.traceRequestDetailsMap))
+	for traceID := range ts.traceRequestDetailsMap {
+		traceIDs = append(traceIDs, traceID)
+	}
+	sort.Strings(traceIDs)
+
+	for _, traceID := range traceIDs {
+		prev := -1
+		for _, requestDetail := range ts.traceRequestDetailsMap[traceID] {
+			idx := g.node(requestDetail.Method, requestDetail.Uri)
+			g.nodes[idx].cnt++
+			g.nodes[idx].responseTime.Set(requestDetail.ResponseTime)
+			if prev >= 0 {
+				g.edge(prev, idx).cnt++
+			}
+			prev = idx
+		}
+	}
+
+	return g
+}
+
+func (g *CallGraph) node(method, uri string) int {
+	idx := g.nodeHints.loadOrStore(fmt.Sprintf("%s_%s", method, uri))
+	if idx >= len(g.nodes) {
+		g.nodes = append(g.nodes, &callGraphNode{
+			method:       method,
+			uri:          uri,
+			responseTime: newResponseTime(true),
+		})
+	}
+
+	return idx
+}
+
+func (g *CallGraph) edge(from, to int) *callGraphEdge {
+	idx := g.edgeHints.loadOrStore(fmt.Sprintf("%d_%d", from, to))
+	if idx >= len(g.edges) {
+		g.edges = append(g.edges, &callGraphEdge{
+			from: from,
+			to:   to,
+		})
+	}
+
+	return g.edges[idx]
+}
+
+// PrintDot outputs the graph in the DOT language of Graphviz,
+// the edges are thicker and redder as they are more frequent
+func (g *CallGraph) PrintDot(w io.Writer) {
+	maxCnt := 0
+	for _, e := range g.edges {
+		if e.cnt > maxCnt {
+			maxCnt = e.cnt
+		}
+	}
+
+	fmt.Fprintln(w, "digraph alp_trace {")
+	fmt.Fprintln(w, `  rankdir=LR;`)
+	fmt.Fprintln(w, `  node [shape=box, style=rounded, fontname="Helvetica"];`)
+	fmt.Fprintln(w, `  edge [fontname="Helvetica"];`)
+
+	for i, n := range g.nodes {
+		label := fmt.Sprintf(`%s %s\ncount: %d\navg: %s, p95: %s`,
+			dotReplacer.Replace(n.method), dotReplacer.Replace(n.uri), n.cnt,
+			milliseconds(n.responseTime.Avg(n.cnt)), milliseconds(n.responseTime.PN(n.cnt, 95)))
+		fmt.Fprintf(w, "  n%d [label=\"%s\"];\n", i, label)
+	}
+
+	for _, e := range g.edges {
+		ratio := float64(e.cnt) / float64(maxCnt)
+		penWidth := callGraphMinPenWidth + (callGraphMaxPenWidth-callGraphMinPenWidth)*ratio
+		// the hue from blue (0.6) to red (0.0)
+		color := fmt.Sprintf("%.3f 0.800 0.800", 0.6*(1-ratio))
+		fmt.Fprintf(w, "  n%d -> n%d [label=\"%d\", weight=%d, penwidth=%.2f, color=\"%s\"];\n",
+			e.from, e.to, e.cnt, e.cnt, penWidth, color)
+	}
+
+	fmt.Fprintln(w, "}")
+}
+
+func (p *TracePrinter) printTraceDot(tsFrom, tsTo *TraceStats) {
+	ts := tsFrom
+	if tsTo != nil {
+		ts = tsTo
+	}
+
+	ts.CallGraph().PrintDot(p.writer)
+}
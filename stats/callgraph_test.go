@@ -0,0 +1,73 @@
+package stats
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/tetsuzawa/alp-trace/options"
+)
+
+func TestCallGraphPrintDot(t *testing.T) {
+	header := "digraph alp_trace {\n" +
+		"  rankdir=LR;\n" +
+		"  node [shape=box, style=rounded, fontname=\"Helvetica\"];\n" +
+		"  edge [fontname=\"Helvetica\"];\n"
+
+	tests := []struct {
+		name     string
+		requests []traceRequest
+		want     string
+	}{
+		{
+			name: "edges",
+			requests: []traceRequest{
+				{traceID: "a", method: "POST", uri: "/login", status: 200, restime: 0.1},
+				{traceID: "a", method: "GET", uri: "/home", status: 200, restime: 0.2},
+				{traceID: "b", method: "POST", uri: "/login", status: 200, restime: 0.3},
+				{traceID: "b", method: "GET", uri: "/home", status: 200, restime: 0.4},
+				{traceID: "c", method: "POST", uri: "/login", status: 200, restime: 0.1},
+				{traceID: "c", method: "GET", uri: "/logout", status: 200, restime: 0.1},
+			},
+			want: header +
+				"  n0 [label=\"POST /login\\ncount: 3\\navg: 166.7ms, p95: 300.0ms\"];\n" +
+				"  n1 [label=\"GET /home\\ncount: 2\\navg: 300.0ms, p95: 400.0ms\"];\n" +
+				"  n2 [label=\"GET /logout\\ncount: 1\\navg: 100.0ms, p95: 100.0ms\"];\n" +
+				"  n0 -> n1 [label=\"2\", weight=2, penwidth=8.00, color=\"0.000 0.800 0.800\"];\n" +
+				"  n0 -> n2 [label=\"1\", weight=1, penwidth=4.50, color=\"0.300 0.800 0.800\"];\n" +
+				"}\n",
+		},
+		{
+			name: "loop",
+			requests: []traceRequest{
+				{traceID: "a", method: "GET", uri: "/poll", status: 200, restime: 0.1},
+				{traceID: "a", method: "GET", uri: "/poll", status: 200, restime: 0.1},
+			},
+			want: header +
+				"  n0 [label=\"GET /poll\\ncount: 2\\navg: 100.0ms, p95: 100.0ms\"];\n" +
+				"  n0 -> n0 [label=\"1\", weight=1, penwidth=8.00, color=\"0.000 0.800 0.800\"];\n" +
+				"}\n",
+		},
+		{
+			name: "escape",
+			requests: []traceRequest{
+				{traceID: "a", method: "GET", uri: `/q?s="a"\b`, status: 200, restime: 0.1},
+			},
+			want: header +
+				"  n0 [label=\"GET /q?s=\\\"a\\\"\\\\b\\ncount: 1\\navg: 100.0ms, p95: 100.0ms\"];\n" +
+				"}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestTraceStats(options.ScenarioKeyUriMethodStatus, tt.requests)
+
+			var buf bytes.Buffer
+			ts.CallGraph().PrintDot(&buf)
+
+			if buf.String() != tt.want {
+				t.Errorf("want:\n%s\ngot:\n%s", tt.want, buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package stats
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/tkuchiki/parsetime"
+)
+
+// chromeTraceEvent is an event of the Trace Event Format, the times are in microseconds
+type chromeTraceEvent struct {
+	Name string                 `json:"name"`
+	Cat  string                 `json:"cat,omitempty"`
+	Ph   string                 `json:"ph"`
+	Ts   int64                  `json:"ts"`
+	Dur  *int64                 `json:"dur,omitempty"`
+	Pid  int                    `json:"pid"`
+	Tid  int                    `json:"tid"`
+	Args map[string]interface{} `json:"args,omitempty"`
+}
+
+type chromeTrace struct {
+	TraceEvents     []*chromeTraceEvent `json:"traceEvents"`
+	DisplayTimeUnit string              `json:"displayTimeUnit"`
+}
+
+type chromeTraceRequest struct {
+	requestDetail *RequestDetail
+	start         time.Time
+}
+
+// exemplarTraceIDs returns the slowest trace of each scenario
+func (ts *TraceStats) exemplarTraceIDs() []string {
+	traceIDs := make([]string, 0, len(ts.ScenarioStats))
+	for _, s := range ts.ScenarioStats {
+		slowest := ""
+		peak := -1.0
+		for _, traceID := range s.TraceIDs {
+			restime := 0.0
+			for _, requestDetail := range ts.traceRequestDetailsMap[traceID] {
+				restime += requestDetail.ResponseTime
+			}
+
+			if restime > peak {
+				slowest = traceID
+				peak = restime
+			}
+		}
+
+		if slowest != "" {
+			traceIDs = append(traceIDs, slowest)
+		}
+	}
+
+	return traceIDs
+}
+
+// chromeTraceRequests returns the requests with the start times,
+// the time of a log is the end of the request, so the start is the time minus the response time.
+// the requests are laid out sequentially from zero if any time cannot be parsed
+func chromeTraceRequests(pt parsetime.ParseTime, requestDetails []*RequestDetail) ([]*chromeTraceRequest, bool) {
+	requests := make([]*chromeTraceRequest, 0, len(requestDetails))
+	for _, requestDetail := range requestDetails {
+		if requestDetail.Time == "" || requestDetail.Time == "-" {
+			break
+		}
+
+		end, err := pt.Parse(requestDetail.Time)
+		if err != nil {
+			break
+		}
+
+		requests = append(requests, &chromeTraceRequest{
+			requestDetail: requestDetail,
+			start:         end.Add(-secondsToDuration(requestDetail.ResponseTime)),
+		})
+	}
+
+	if len(requests) == len(requestDetails) {
+		return requests, true
+	}
+
+	requests = requests[:0]
+	start := time.Time{}
+	for _, requestDetail := range requestDetails {
+		requests = append(requests, &chromeTraceRequest{
+			requestDetail: requestDetail,
+			start:         start,
+		})
+		start = start.Add(secondsToDuration(requestDetail.ResponseTime))
+	}
+
+	return requests, false
+}
+
+func secondsToDuration(sec float64) time.Duration {
+	return time.Duration(math.Round(sec * float64(time.Second)))
+}
+
+// printTraceChrome outputs the requests of the traces selected by --trace-ids, or the slowest trace of each scenario,
+// in the Trace Event Format that can be opened in chrome://tracing or Perfetto UI, each trace is a track
+func (p *TracePrinter) printTraceChrome(tsFrom, tsTo *TraceStats) error {
+	ts := tsFrom
+	if tsTo != nil {
+		ts = tsTo
+	}
+
+	traceIDs := ts.exemplarTraceIDs()
+	if len(p.traceIDs) > 0 {
+		// the unknown trace IDs are ignored
+		traceIDs = make([]string, 0, len(p.traceIDs))
+		for _, traceID := range p.traceIDs {
+			if _, ok := ts.traceRequestDetailsMap[traceID]; ok {
+				traceIDs = append(traceIDs, traceID)
+			}
+		}
+	}
+
+	scenarioIDs := ts.TraceScenarioIDs()
+	tracks := make([][]*chromeTraceRequest, 0, len(traceIDs))
+	timed := make([]bool, 0, len(traceIDs))
+	origin := time.Time{}
+	for _, traceID := range traceIDs {
+		requests, ok := chromeTraceRequests(p.parseTime, ts.traceRequestDetailsMap[traceID])
+		tracks = append(tracks, requests)
+		timed = append(timed, ok)
+		// the timed tracks start from the earliest request of them
+		if ok {
+			for _, r := range requests {
+				if origin.IsZero() || r.start.Before(origin) {
+					origin = r.start
+				}
+			}
+		}
+	}
+
+	chrome := &chromeTrace{
+		TraceEvents:     make([]*chromeTraceEvent, 0),
+		DisplayTimeUnit: "ms",
+	}
+	chrome.TraceEvents = append(chrome.TraceEvents, &chromeTraceEvent{
+		Name: "process_name",
+		Ph:   "M",
+		Pid:  1,
+		Args: map[string]interface{}{"name": "alp-trace"},
+	})
+
+	for i, traceID := range traceIDs {
+		tid := i + 1
+		name := fmt.Sprintf("trace %s", traceID)
+		if scenarioID, ok := scenarioIDs[traceID]; ok {
+			name = fmt.Sprintf("trace %s (scenario %s)", traceID, scenarioID)
+		}
+
+		chrome.TraceEvents = append(chrome.TraceEvents,
+			&chromeTraceEvent{
+				Name: "thread_name",
+				Ph:   "M",
+				Pid:  1,
+				Tid:  tid,
+				Args: map[string]interface{}{"name": name},
+			},
+			&chromeTraceEvent{
+				Name: "thread_sort_index",
+				Ph:   "M",
+				Pid:  1,
+				Tid:  tid,
+				Args: map[string]interface{}{"sort_index": i},
+			},
+		)
+
+		base := origin
+		if !timed[i] {
+			base = time.Time{}
+		}
+
+		for _, r := range tracks[i] {
+			rd := r.requestDetail
+			dur := secondsToDuration(rd.ResponseTime).Microseconds()
+			args := map[string]interface{}{
+				"trace_id":            traceID,
+				"status":              rd.Status,
+				"response_time":       rd.ResponseTime,
+				"response_body_bytes": rd.ResponseBodyBytes,
+			}
+			if rd.RequestBodyBytes > 0 {
+				args["request_body_bytes"] = rd.RequestBodyBytes
+			}
+			if timed[i] {
+				args["time"] = rd.Time
+			}
+
+			chrome.TraceEvents = append(chrome.TraceEvents, &chromeTraceEvent{
+				Name: fmt.Sprintf("%s %s", rd.Method, rd.Uri),
+				Cat:  fmt.Sprintf("%dxx", rd.Status/100),
+				Ph:   "X",
+				Ts:   r.start.Sub(base).Microseconds(),
+				Dur:  &dur,
+				Pid:  1,
+				Tid:  tid,
+				Args: args,
+			})
+		}
+	}
+
+	return printIndentedJSON(p.writer, chrome)
+}
@@ -0,0 +1,127 @@
+package stats
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/tetsuzawa/alp-trace/options"
+)
+
+func TestPrintTraceChrome(t *testing.T) {
+	type span struct {
+		name string
+		tid  int
+		ts   int64
+		dur  int64
+	}
+
+	tests := []struct {
+		name     string
+		traceIDs []string
+		requests []traceRequest
+		want     []span
+	}{
+		{
+			name: "exemplars",
+			requests: []traceRequest{
+				{traceID: "a", time: "2024-01-01T00:00:01Z", method: "POST", uri: "/login", status: 200, restime: 0.1},
+				{traceID: "a", time: "2024-01-01T00:00:02Z", method: "GET", uri: "/home", status: 200, restime: 0.2},
+				{traceID: "b", time: "2024-01-01T00:00:00Z", method: "POST", uri: "/login", status: 200, restime: 0.3},
+				{traceID: "b", time: "2024-01-01T00:00:01Z", method: "GET", uri: "/home", status: 200, restime: 0.4},
+			},
+			// the slowest trace b starts at the origin, the start is the time minus the response time
+			want: []span{
+				{name: "POST /login", tid: 1, ts: 0, dur: 300000},
+				{name: "GET /home", tid: 1, ts: 900000, dur: 400000},
+			},
+		},
+		{
+			name:     "trace ids",
+			traceIDs: []string{"a", "unknown", "b"},
+			requests: []traceRequest{
+				{traceID: "a", time: "2024-01-01T00:00:01Z", method: "POST", uri: "/login", status: 200, restime: 0.1},
+				{traceID: "b", time: "2024-01-01T00:00:02Z", method: "POST", uri: "/login", status: 500, restime: 0.5},
+			},
+			want: []span{
+				{name: "POST /login", tid: 1, ts: 0, dur: 100000},
+				{name: "POST /login", tid: 2, ts: 600000, dur: 500000},
+			},
+		},
+		{
+			name: "untimed",
+			requests: []traceRequest{
+				{traceID: "a", method: "POST", uri: "/login", status: 200, restime: 0.1},
+				{traceID: "a", time: "-", method: "GET", uri: "/home", status: 200, restime: 0.2},
+			},
+			// the requests are laid out sequentially from zero
+			want: []span{
+				{name: "POST /login", tid: 1, ts: 0, dur: 100000},
+				{name: "GET /home", tid: 1, ts: 100000, dur: 200000},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestTraceStats(options.ScenarioKeyUriMethodStatus, tt.requests)
+
+			var buf bytes.Buffer
+			p := NewTracePrinter(&buf, "", "chrome", []int{95}, NewTracePrintOptions(false, false, false, 0))
+			p.SetTraceIDs(tt.traceIDs)
+			if err := p.SetLocation("UTC"); err != nil {
+				t.Fatal(err)
+			}
+			if err := p.Print(ts, nil); err != nil {
+				t.Fatal(err)
+			}
+
+			var chrome chromeTrace
+			if err := json.Unmarshal(buf.Bytes(), &chrome); err != nil {
+				t.Fatalf("%v: %s", err, buf.String())
+			}
+
+			got := make([]span, 0)
+			for _, e := range chrome.TraceEvents {
+				if e.Ph != "X" {
+					continue
+				}
+				if e.Dur == nil {
+					t.Fatalf("no duration: %+v", e)
+				}
+				got = append(got, span{name: e.Name, tid: e.Tid, ts: e.Ts, dur: *e.Dur})
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("want: %+v, got: %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestTracePrinterSetLocation(t *testing.T) {
+	tests := []struct {
+		location string
+		wantErr  bool
+	}{
+		{location: "UTC"},
+		{location: "Asia/Tokyo"},
+		{location: "Local"},
+		{location: "Nowhere/Unknown", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.location, func(t *testing.T) {
+			var buf bytes.Buffer
+			p := NewTracePrinter(&buf, "", "chrome", []int{95}, NewTracePrintOptions(false, false, false, 0))
+			err := p.SetLocation(tt.location)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("want error: %v, got: %v", tt.wantErr, err)
+			}
+			if buf.Len() > 0 {
+				t.Errorf("want no output, got: %s", buf.String())
+			}
+		})
+	}
+}
@@ -232,6 +232,106 @@ func (d *Differ) DiffStddevResponseBodyBytes() string {
 	return fmt.Sprintf("%.3f", v)
 }
 
+// tcp
+func (d *Differ) DiffMaxRequestTime() string {
+	v := d.To.MaxRequestTime() - d.From.MaxRequestTime()
+	if v >= 0 {
+		return fmt.Sprintf("+%.3f", v)
+	}
+
+	return fmt.Sprintf("%.3f", v)
+}
+
+func (d *Differ) DiffSumRequestTime() string {
+	v := d.To.SumRequestTime() - d.From.SumRequestTime()
+	if v >= 0 {
+		return fmt.Sprintf("+%.3f", v)
+	}
+
+	return fmt.Sprintf("%.3f", v)
+}
+
+func (d *Differ) DiffAvgRequestTime() string {
+	v := d.To.AvgRequestTime() - d.From.AvgRequestTime()
+	if v >= 0 {
+		return fmt.Sprintf("+%.3f", v)
+	}
+
+	return fmt.Sprintf("%.3f", v)
+}
+
+func (d *Differ) DiffMaxOverhead() string {
+	v := d.To.MaxOverhead() - d.From.MaxOverhead()
+	if v >= 0 {
+		return fmt.Sprintf("+%.3f", v)
+	}
+
+	return fmt.Sprintf("%.3f", v)
+}
+
+func (d *Differ) DiffSumOverhead() string {
+	v := d.To.SumOverhead() - d.From.SumOverhead()
+	if v >= 0 {
+		return fmt.Sprintf("+%.3f", v)
+	}
+
+	return fmt.Sprintf("%.3f", v)
+}
+
+func (d *Differ) DiffAvgOverhead() string {
+	v := d.To.AvgOverhead() - d.From.AvgOverhead()
+	if v >= 0 {
+		return fmt.Sprintf("+%.3f", v)
+	}
+
+	return fmt.Sprintf("%.3f", v)
+}
+
+func (d *Differ) DiffAvgHandshakeRTT() string {
+	v := d.To.AvgHandshakeRTT() - d.From.AvgHandshakeRTT()
+	if v >= 0 {
+		return fmt.Sprintf("+%.3f", v)
+	}
+
+	return fmt.Sprintf("%.3f", v)
+}
+
+func (d *Differ) DiffAvgFirstByteTime() string {
+	v := d.To.AvgFirstByteTime() - d.From.AvgFirstByteTime()
+	if v >= 0 {
+		return fmt.Sprintf("+%.3f", v)
+	}
+
+	return fmt.Sprintf("%.3f", v)
+}
+
+func (d *Differ) DiffAvgLastByteTime() string {
+	v := d.To.AvgLastByteTime() - d.From.AvgLastByteTime()
+	if v >= 0 {
+		return fmt.Sprintf("+%.3f", v)
+	}
+
+	return fmt.Sprintf("%.3f", v)
+}
+
+func (d *Differ) DiffRetransmissions() string {
+	v := d.To.Retransmissions() - d.From.Retransmissions()
+	if v >= 0 {
+		return fmt.Sprintf("+%d", v)
+	}
+
+	return fmt.Sprintf("%d", v)
+}
+
+func (d *Differ) DiffReusedConnections() string {
+	v := d.To.ReusedConnections() - d.From.ReusedConnections()
+	if v >= 0 {
+		return fmt.Sprintf("+%d", v)
+	}
+
+	return fmt.Sprintf("%d", v)
+}
+
 func DiffCountAll(from, to map[string]int) map[string]string {
 	counts := make(map[string]string, 6)
 	keys := []string{"count", "1xx", "2xx", "3xx", "4xx", "5xx"}
@@ -10,7 +10,7 @@ import (
 func TestDumpStats(t *testing.T) {
 	got := new(bytes.Buffer)
 	stats := NewHTTPStats(true, false, false)
-	stats.Set("/foo/bar", "POST", 200, 0.057, 12, 0)
+	stats.Set("/foo/bar", "POST", 200, 0.057, 12, 34)
 
 	err := stats.DumpStats(got)
 	if err != nil {
@@ -33,15 +33,15 @@ func TestDumpStats(t *testing.T) {
     percentiles:
     - 0.057
   request_body_bytes:
-    max: 12
-    min: 12
-    sum: 12
+    max: 34
+    min: 34
+    sum: 34
     usepercentile: false
     percentiles: []
   response_body_bytes:
-    max: 0
-    min: 0
-    sum: 0
+    max: 12
+    min: 12
+    sum: 12
     usepercentile: false
     percentiles: []
   time: ""
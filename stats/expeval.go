@@ -20,6 +20,7 @@ type ExpEvalEnv struct {
 	Time                             string
 	ResponseTime                     float64
 	BodyBytes                        float64
+	RequestBodyBytes                 float64
 	Status                           int
 	TimeStringEqualTime              func(l time.Time, r string) bool
 	TimeStringNotEqualTime           func(l time.Time, r string) bool
@@ -220,6 +221,7 @@ func (ee *ExpEval) Run(stat *parsers.ParsedHTTPStat) (bool, error) {
 		Time:                             stat.Time,
 		ResponseTime:                     stat.ResponseTime,
 		BodyBytes:                        stat.BodyBytes,
+		RequestBodyBytes:                 stat.RequestBodyBytes,
 		Status:                           stat.Status,
 		TimeStringEqualTime:              TimeStringEqualTime,
 		TimeStringNotEqualTime:           TimeStringNotEqualTime,
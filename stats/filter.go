@@ -42,6 +42,21 @@ func (f *Filter) Init() error {
 	return nil
 }
 
+// Validate compiles the filters and runs them once with a request of the current time,
+// so that the invalid arguments of the functions, e.g. TimeAgo("5x"), are found before the requests are read
+func (f *Filter) Validate() error {
+	if err := f.Init(); err != nil {
+		return err
+	}
+
+	err := f.Do(&parsers.ParsedHTTPStat{Time: time.Now().Format(time.RFC3339)})
+	if err != nil && err != errors.SkipReadLineErr {
+		return err
+	}
+
+	return nil
+}
+
 func (f *Filter) isEnable() bool {
 	if f.expeval != nil {
 		return true
@@ -0,0 +1,33 @@
+package stats
+
+import (
+	"testing"
+
+	"github.com/tetsuzawa/alp-trace/options"
+)
+
+func TestFilterValidate(t *testing.T) {
+	tests := []struct {
+		filters string
+		wantErr bool
+	}{
+		{filters: ""},
+		{filters: `Status >= 500`},
+		{filters: `Uri matches "^/api"`},
+		{filters: `Time > TimeAgo("5m")`},
+		{filters: `BetweenTime(Time, "2024-01-01T00:00:00Z", "2024-01-02T00:00:00Z")`},
+		{filters: `Status >=`, wantErr: true},
+		{filters: `Unknown == 1`, wantErr: true},
+		{filters: `Time > TimeAgo("5x")`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filters, func(t *testing.T) {
+			opts := options.NewOptions(options.Filters(tt.filters), options.Location("UTC"))
+			err := NewFilter(opts).Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("want error: %v, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package stats
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// the frames are separated by semicolons and the value by the last space
+var foldedFrameReplacer = strings.NewReplacer(";", ":", "\n", " ", "\r", " ")
+
+// printTraceFolded outputs the collapsed stacks of the total response time in microseconds,
+// a stack is the scenario and its request, since the logs do not have the parent span of the requests
+func (p *TracePrinter) printTraceFolded(tsFrom, tsTo *TraceStats) {
+	ts := tsFrom
+	if tsTo != nil {
+		ts = tsTo
+	}
+
+	for _, s := range ts.ScenarioStats {
+		for _, rds := range s.RequestDetailsStats {
+			us := int64(math.Round(rds.SumResponseTime() * 1000000))
+			if us <= 0 {
+				continue
+			}
+
+			frame := fmt.Sprintf("%s %s %d", rds.RequestDetail.Method, rds.RequestDetail.Uri, rds.RequestDetail.Status)
+			// the status varies in a scenario if the scenario key ignores it
+			if ts.ignoreStatus() {
+				frame = fmt.Sprintf("%s %s", rds.RequestDetail.Method, rds.RequestDetail.Uri)
+			}
+
+			fmt.Fprintf(p.writer, "%s;%s %d\n", foldedFrameReplacer.Replace(s.ID), foldedFrameReplacer.Replace(frame), us)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package stats
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/tetsuzawa/alp-trace/options"
+)
+
+func TestPrintTraceFolded(t *testing.T) {
+	tests := []struct {
+		name        string
+		scenarioKey string
+		requests    []traceRequest
+		want        string
+	}{
+		{
+			name:        "stacks",
+			scenarioKey: options.ScenarioKeyUriMethodStatus,
+			requests: []traceRequest{
+				{traceID: "a", method: "POST", uri: "/login", status: 200, restime: 0.1},
+				{traceID: "a", method: "GET", uri: "/home", status: 200, restime: 0.2},
+				{traceID: "b", method: "POST", uri: "/login", status: 200, restime: 0.3},
+				{traceID: "b", method: "GET", uri: "/home", status: 200, restime: 0.4},
+			},
+			want: "%[1]s;POST /login 200 400000\n" +
+				"%[1]s;GET /home 200 600000\n",
+		},
+		{
+			name:        "zero",
+			scenarioKey: options.ScenarioKeyUriMethodStatus,
+			requests: []traceRequest{
+				{traceID: "a", method: "GET", uri: "/cached", status: 304, restime: 0},
+				{traceID: "a", method: "GET", uri: "/home", status: 200, restime: 0.001},
+			},
+			want: "%[1]s;GET /home 200 1000\n",
+		},
+		{
+			name:        "escape",
+			scenarioKey: options.ScenarioKeyUriMethod,
+			requests: []traceRequest{
+				{traceID: "a", method: "GET", uri: "/a;b", status: 500, restime: 0.5},
+			},
+			want: "%[1]s;GET /a:b 500000\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestTraceStats(tt.scenarioKey, tt.requests)
+
+			var buf bytes.Buffer
+			p := NewTracePrinter(&buf, "", "folded", []int{95}, NewTracePrintOptions(false, false, false, 0))
+			if err := p.Print(ts, nil); err != nil {
+				t.Fatal(err)
+			}
+
+			want := fmt.Sprintf(tt.want, ts.ScenarioStats[0].ID)
+			if buf.String() != want {
+				t.Errorf("want:\n%s\ngot:\n%s", want, buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,272 @@
+package stats
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tetsuzawa/alp-trace/helpers"
+)
+
+const histogramBarWidth = 40
+
+// Histogram counts the response times into the buckets,
+// a bucket holds the values greater than the previous bound and less than or equal to its bound
+type Histogram struct {
+	bounds []float64
+	labels []string
+	rows   []*histogramRow
+}
+
+type histogramRow struct {
+	labels []string
+	counts []int
+}
+
+// histogramCounts counts the response times of an endpoint or a scenario into the buckets as they are set
+type histogramCounts struct {
+	bounds []float64
+	counts []int
+}
+
+func newHistogramCounts(bounds []float64) *histogramCounts {
+	return &histogramCounts{
+		bounds: bounds,
+		counts: make([]int, len(bounds)+1),
+	}
+}
+
+func (hc *histogramCounts) add(v float64) {
+	i := sort.SearchFloat64s(hc.bounds, v)
+	hc.counts[i]++
+}
+
+// ParseHistogramBuckets parses the bucket bounds,
+// linear:start,width,count or exp:start,factor,count or the explicit bounds separated by commas
+func ParseHistogramBuckets(spec string) ([]float64, error) {
+	var bounds []float64
+	switch {
+	case strings.HasPrefix(spec, "linear:"), strings.HasPrefix(spec, "exp:"):
+		kv := strings.SplitN(spec, ":", 2)
+		kind := kv[0]
+		values, err := parseHistogramParams(kv[1])
+		if err != nil || len(values) != 3 {
+			return nil, fmt.Errorf("invalid histogram buckets: %s (linear:start,width,count or exp:start,factor,count)", spec)
+		}
+
+		start, step, count := values[0], values[1], int(values[2])
+		if count <= 0 || float64(count) != values[2] {
+			return nil, fmt.Errorf("invalid histogram buckets: %s (count must be a positive integer)", spec)
+		}
+
+		bounds = make([]float64, count)
+		for i := range bounds {
+			if kind == "linear" {
+				bounds[i] = start + step*float64(i)
+			} else {
+				bounds[i] = start * math.Pow(step, float64(i))
+			}
+			// avoid the bounds such as 0.30000000000000004
+			bounds[i] = math.Round(bounds[i]*1e9) / 1e9
+		}
+	default:
+		values, err := parseHistogramParams(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid histogram buckets: %s", spec)
+		}
+		bounds = values
+	}
+
+	for i := 1; i < len(bounds); i++ {
+		if bounds[i] <= bounds[i-1] {
+			return nil, fmt.Errorf("invalid histogram buckets: %s (the bounds must be increasing)", spec)
+		}
+	}
+
+	return bounds, nil
+}
+
+func parseHistogramParams(s string) ([]float64, error) {
+	params := helpers.SplitCSV(s)
+	if len(params) == 0 {
+		return nil, fmt.Errorf("empty")
+	}
+
+	values := make([]float64, len(params))
+	for i, param := range params {
+		v, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+
+	return values, nil
+}
+
+func NewHistogram(bounds []float64, labels []string) *Histogram {
+	return &Histogram{
+		bounds: bounds,
+		labels: labels,
+	}
+}
+
+// Add appends the row of the counted buckets, the last bucket is +Inf
+func (h *Histogram) Add(labels []string, counts *histogramCounts) {
+	h.rows = append(h.rows, &histogramRow{
+		labels: labels,
+		counts: counts.counts,
+	})
+}
+
+// countResponseTimes counts the response times kept for the percentiles, the loaded stats have no counted buckets
+func countResponseTimes(bounds []float64, cnt int, res *responseTime) (*histogramCounts, error) {
+	if len(res.Percentiles) != cnt {
+		return nil, fmt.Errorf("--histogram needs the response time of every request, which the stats do not keep")
+	}
+
+	hc := newHistogramCounts(bounds)
+	for _, v := range res.Percentiles {
+		hc.add(v)
+	}
+
+	return hc, nil
+}
+
+func (h *Histogram) bucketNames() []string {
+	names := make([]string, 0, len(h.bounds)+1)
+	for _, b := range h.bounds {
+		names = append(names, "le_"+strconv.FormatFloat(b, 'f', -1, 64))
+	}
+
+	return append(names, "le_+Inf")
+}
+
+func (h *Histogram) Print(w io.Writer, format string) error {
+	if err := ValidateHistogramFormat(format); err != nil {
+		return err
+	}
+
+	switch format {
+	case "pretty":
+		h.printPretty(w)
+	case "tsv":
+		h.printSeparated(w, "\t")
+	case "csv":
+		h.printSeparated(w, ",")
+	case "json":
+		return h.printJSON(w)
+	}
+
+	return nil
+}
+
+func ValidateHistogramFormat(format string) error {
+	switch format {
+	case "pretty", "tsv", "csv", "json":
+		return nil
+	}
+
+	return fmt.Errorf("--histogram supports only pretty, tsv, csv and json formats, got %s", format)
+}
+
+func (h *Histogram) printPretty(w io.Writer) {
+	names := make([]string, 0, len(h.bounds)+1)
+	for _, b := range h.bounds {
+		names = append(names, "<= "+strconv.FormatFloat(b, 'f', -1, 64))
+	}
+	names = append(names, "> "+strconv.FormatFloat(h.bounds[len(h.bounds)-1], 'f', -1, 64))
+
+	nameWidth := 0
+	for _, name := range names {
+		if len(name) > nameWidth {
+			nameWidth = len(name)
+		}
+	}
+
+	for i, row := range h.rows {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+
+		total, peak := 0, 0
+		for _, c := range row.counts {
+			total += c
+			if c > peak {
+				peak = c
+			}
+		}
+		fmt.Fprintf(w, "%s (count: %d)\n", strings.Join(row.labels, " "), total)
+
+		for j, c := range row.counts {
+			bar := 0
+			if peak > 0 {
+				bar = int(math.Round(float64(c) / float64(peak) * histogramBarWidth))
+			}
+			fmt.Fprintf(w, "  %*s |%-*s| %d\n", nameWidth, names[j], histogramBarWidth, strings.Repeat("#", bar), c)
+		}
+	}
+}
+
+func (h *Histogram) printSeparated(w io.Writer, sep string) {
+	headers := append(append([]string{}, h.labels...), h.bucketNames()...)
+	fmt.Fprintln(w, strings.Join(headers, sep))
+	for _, row := range h.rows {
+		line := make([]string, 0, len(row.labels)+len(row.counts))
+		for _, v := range row.labels {
+			if sep == "," && strings.ContainsAny(v, `,"`) {
+				v = fmt.Sprintf(`"%s"`, strings.ReplaceAll(v, `"`, `""`))
+			}
+			line = append(line, v)
+		}
+		for _, c := range row.counts {
+			line = append(line, fmt.Sprint(c))
+		}
+		fmt.Fprintln(w, strings.Join(line, sep))
+	}
+}
+
+type jsonHistogramRow struct {
+	Labels  map[string]string      `json:"labels"`
+	Buckets []*jsonHistogramBucket `json:"buckets"`
+}
+
+// jsonHistogramBucket keeps the order of the buckets, le is a string for +Inf
+type jsonHistogramBucket struct {
+	Le    string `json:"le"`
+	Count int    `json:"count"`
+}
+
+func (h *Histogram) printJSON(w io.Writer) error {
+	rows := make([]*jsonHistogramRow, 0, len(h.rows))
+	for _, row := range h.rows {
+		labels := make(map[string]string, len(h.labels))
+		for i, label := range h.labels {
+			labels[label] = row.labels[i]
+		}
+
+		buckets := make([]*jsonHistogramBucket, 0, len(row.counts))
+		for i, b := range h.bounds {
+			buckets = append(buckets, &jsonHistogramBucket{Le: strconv.FormatFloat(b, 'f', -1, 64), Count: row.counts[i]})
+		}
+		buckets = append(buckets, &jsonHistogramBucket{Le: "+Inf", Count: row.counts[len(h.bounds)]})
+
+		rows = append(rows, &jsonHistogramRow{
+			Labels:  labels,
+			Buckets: buckets,
+		})
+	}
+
+	buf, err := json.MarshalIndent(rows, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(w, string(buf))
+
+	return err
+}
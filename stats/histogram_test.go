@@ -0,0 +1,171 @@
+package stats
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestParseHistogramBuckets(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    []float64
+		wantErr bool
+	}{
+		{spec: "linear:0.1,0.1,5", want: []float64{0.1, 0.2, 0.3, 0.4, 0.5}},
+		{spec: "linear:0,0.05,3", want: []float64{0, 0.05, 0.1}},
+		{spec: "exp:0.001,2,4", want: []float64{0.001, 0.002, 0.004, 0.008}},
+		{spec: "exp:1,10,3", want: []float64{1, 10, 100}},
+		{spec: "0.01, 0.1,1", want: []float64{0.01, 0.1, 1}},
+		{spec: "0.5", want: []float64{0.5}},
+		{spec: "", wantErr: true},
+		{spec: "linear:0.1,0.1", wantErr: true},
+		{spec: "linear:0.1,0.1,0", wantErr: true},
+		{spec: "linear:0.1,0.1,2.5", wantErr: true},
+		{spec: "linear:0.1,0,3", wantErr: true},
+		{spec: "linear:0.1,-0.1,3", wantErr: true},
+		{spec: "exp:0.1,1,3", wantErr: true},
+		{spec: "exp:a,2,3", wantErr: true},
+		{spec: "0.1,0.1", wantErr: true},
+		{spec: "1,0.5", wantErr: true},
+		{spec: "0.1,fast", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseHistogramBuckets(tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("want error, got: %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("want: %v, got: %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestHTTPStatsHistogram(t *testing.T) {
+	hs := NewHTTPStats(false, false, false)
+	hs.SetHistogram([]float64{0.1, 0.5})
+	for _, restime := range []float64{0.05, 0.1, 0.2, 0.5, 0.7, 1.2} {
+		hs.Set("/foo", "GET", 200, restime, 10, 0)
+	}
+
+	h, err := hs.Histogram()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := h.Print(&buf, "tsv"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "method\turi\tle_0.1\tle_0.5\tle_+Inf\n" +
+		"GET\t/foo\t2\t2\t2\n"
+	if buf.String() != want {
+		t.Errorf("want:\n%s\ngot:\n%s", want, buf.String())
+	}
+}
+
+func TestHTTPStatsHistogramLoaded(t *testing.T) {
+	tests := []struct {
+		name          string
+		usePercentile bool
+		want          string
+		wantErr       bool
+	}{
+		{
+			name:          "response times",
+			usePercentile: true,
+			want:          "method\turi\tle_0.1\tle_+Inf\nGET\t/foo\t1\t1\n",
+		},
+		{
+			name:    "no response times",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the stats loaded from a dump have no counted buckets
+			hs := NewHTTPStats(tt.usePercentile, false, false)
+			hs.Set("/foo", "GET", 200, 0.05, 10, 0)
+			hs.Set("/foo", "GET", 200, 0.2, 10, 0)
+			hs.SetHistogram([]float64{0.1})
+
+			h, err := hs.Histogram()
+			if tt.wantErr {
+				if err == nil {
+					t.Error("want error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var buf bytes.Buffer
+			if err := h.Print(&buf, "tsv"); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("want:\n%s\ngot:\n%s", tt.want, buf.String())
+			}
+		})
+	}
+}
+
+func TestHistogramPrintJSON(t *testing.T) {
+	hs := NewHTTPStats(false, false, false)
+	hs.SetHistogram([]float64{0.1, 2, 10})
+	hs.Set("/foo", "GET", 200, 0.05, 10, 0)
+	hs.Set("/foo", "GET", 200, 5, 10, 0)
+
+	h, err := hs.Histogram()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := h.Print(&buf, "json"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `[
+  {
+    "labels": {
+      "method": "GET",
+      "uri": "/foo"
+    },
+    "buckets": [
+      {
+        "le": "0.1",
+        "count": 1
+      },
+      {
+        "le": "2",
+        "count": 0
+      },
+      {
+        "le": "10",
+        "count": 1
+      },
+      {
+        "le": "+Inf",
+        "count": 0
+      }
+    ]
+  }
+]
+`
+	if buf.String() != want {
+		t.Errorf("want:\n%s\ngot:\n%s", want, buf.String())
+	}
+}
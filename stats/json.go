@@ -0,0 +1,404 @@
+package stats
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// JSONSchemaVersion is the version of the json and ndjson formats,
+// increment it when a field is renamed or removed
+const JSONSchemaVersion = 1
+
+type jsonStatus struct {
+	Status1xx int `json:"1xx"`
+	Status2xx int `json:"2xx"`
+	Status3xx int `json:"3xx"`
+	Status4xx int `json:"4xx"`
+	Status5xx int `json:"5xx"`
+}
+
+type jsonPercentile struct {
+	n     int
+	value float64
+}
+
+// jsonPercentiles keeps the order of the requested percentiles
+type jsonPercentiles []jsonPercentile
+
+func (jp jsonPercentiles) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("{")
+	for i, p := range jp {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		v, err := json.Marshal(p.value)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&buf, `"p%d":%s`, p.n, v)
+	}
+	buf.WriteString("}")
+
+	return buf.Bytes(), nil
+}
+
+type jsonSummary struct {
+	Min         float64         `json:"min"`
+	Max         float64         `json:"max"`
+	Sum         float64         `json:"sum"`
+	Avg         float64         `json:"avg"`
+	Stddev      *float64        `json:"stddev,omitempty"`
+	Percentiles jsonPercentiles `json:"percentiles,omitempty"`
+}
+
+// newJSONSummary returns the percentiles and the stddev only if the values are kept
+func newJSONSummary(min, max, sum float64, values []float64, cnt int, percentiles []int, pn func(cnt, n int) float64, stddev func(cnt int) float64) *jsonSummary {
+	s := &jsonSummary{
+		Min: min,
+		Max: max,
+		Sum: sum,
+	}
+	if cnt == 0 {
+		return s
+	}
+
+	s.Avg = sum / float64(cnt)
+	if len(values) == 0 {
+		return s
+	}
+
+	sd := stddev(cnt)
+	s.Stddev = &sd
+	s.Percentiles = make(jsonPercentiles, 0, len(percentiles))
+	for _, n := range percentiles {
+		s.Percentiles = append(s.Percentiles, jsonPercentile{n: n, value: pn(cnt, n)})
+	}
+
+	return s
+}
+
+func newJSONResponseTime(res *responseTime, cnt int, percentiles []int) *jsonSummary {
+	return newJSONSummary(res.Min, res.Max, res.Sum, res.Percentiles, cnt, percentiles, res.PN, res.Stddev)
+}
+
+func newJSONBodyBytes(body *bodyBytes, cnt int, percentiles []int) *jsonSummary {
+	return newJSONSummary(body.Min, body.Max, body.Sum, body.Percentiles, cnt, percentiles, body.PN, body.Stddev)
+}
+
+type jsonRequestTime struct {
+	Total    *jsonSummary `json:"total"`
+	Overhead *jsonSummary `json:"overhead"`
+}
+
+func newJSONRequestTime(rs *requestTimeStat, percentiles []int) *jsonRequestTime {
+	if rs == nil {
+		return nil
+	}
+
+	return &jsonRequestTime{
+		Total:    newJSONResponseTime(rs.RequestTime, rs.Cnt, percentiles),
+		Overhead: newJSONResponseTime(rs.Overhead, rs.Cnt, percentiles),
+	}
+}
+
+type jsonTCP struct {
+	AvgHandshake float64 `json:"avg_handshake"`
+	AvgTTFB      float64 `json:"avg_ttfb"`
+	AvgTTLB      float64 `json:"avg_ttlb"`
+	Retrans      int     `json:"retrans"`
+	Reused       int     `json:"reused"`
+}
+
+type jsonEndpoint struct {
+	Service           string            `json:"service,omitempty"`
+	Method            string            `json:"method"`
+	Uri               string            `json:"uri"`
+	Groups            map[string]string `json:"groups,omitempty"`
+	Count             int               `json:"count"`
+	Status            jsonStatus        `json:"status"`
+	ResponseTime      *jsonSummary      `json:"response_time"`
+	RequestBodyBytes  *jsonSummary      `json:"request_body_bytes"`
+	ResponseBodyBytes *jsonSummary      `json:"response_body_bytes"`
+	RequestTime       *jsonRequestTime  `json:"request_time,omitempty"`
+	TCP               *jsonTCP          `json:"tcp,omitempty"`
+}
+
+type jsonEndpointsGlobal struct {
+	Count  int        `json:"count"`
+	Status jsonStatus `json:"status"`
+}
+
+type jsonEndpointsReport struct {
+	SchemaVersion int                  `json:"schema_version"`
+	Type          string               `json:"type"`
+	Percentiles   []int                `json:"percentiles"`
+	Global        *jsonEndpointsGlobal `json:"global"`
+	Endpoints     []*jsonEndpoint      `json:"endpoints"`
+}
+
+type jsonStep struct {
+	Method            string           `json:"method"`
+	Uri               string           `json:"uri"`
+	StatusCode        int              `json:"status_code,omitempty"`
+	Count             int              `json:"count"`
+	Status            jsonStatus       `json:"status"`
+	ErrorRate         float64          `json:"error_rate"`
+	ResponseTime      *jsonSummary     `json:"response_time"`
+	RequestBodyBytes  *jsonSummary     `json:"request_body_bytes"`
+	ResponseBodyBytes *jsonSummary     `json:"response_body_bytes"`
+	RequestTime       *jsonRequestTime `json:"request_time,omitempty"`
+}
+
+type jsonScenario struct {
+	ID                string       `json:"id"`
+	Count             int          `json:"count"`
+	Status            jsonStatus   `json:"status"`
+	ErrorRate         float64      `json:"error_rate"`
+	ResponseTime      *jsonSummary `json:"response_time"`
+	RequestBodyBytes  *jsonSummary `json:"request_body_bytes"`
+	ResponseBodyBytes *jsonSummary `json:"response_body_bytes"`
+	TraceIDs          []string     `json:"trace_ids"`
+	Steps             []*jsonStep  `json:"steps"`
+}
+
+type jsonScenariosGlobal struct {
+	Traces            int          `json:"traces"`
+	Requests          int          `json:"requests"`
+	ResponseTime      *jsonSummary `json:"response_time"`
+	RequestBodyBytes  *jsonSummary `json:"request_body_bytes"`
+	ResponseBodyBytes *jsonSummary `json:"response_body_bytes"`
+}
+
+type jsonScenariosReport struct {
+	SchemaVersion int                  `json:"schema_version"`
+	Type          string               `json:"type"`
+	Percentiles   []int                `json:"percentiles"`
+	Global        *jsonScenariosGlobal `json:"global"`
+	Scenarios     []*jsonScenario      `json:"scenarios"`
+}
+
+// ndjsonHeader is prepended to the fields of each line of the ndjson format
+type ndjsonHeader struct {
+	SchemaVersion int    `json:"schema_version"`
+	Type          string `json:"type"`
+	Percentiles   []int  `json:"percentiles,omitempty"`
+}
+
+type ndjsonRecord struct {
+	header ndjsonHeader
+	fields interface{}
+}
+
+func (r *ndjsonRecord) MarshalJSON() ([]byte, error) {
+	header, err := json.Marshal(r.header)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := json.Marshal(r.fields)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(fields) <= 2 {
+		return header, nil
+	}
+
+	// {"schema_version":1,"type":"..."} + {"count":1,...}
+	return append(append(header[:len(header)-1], ','), fields[1:]...), nil
+}
+
+func (p *Printer) jsonEndpointsReport(hs *HTTPStats) *jsonEndpointsReport {
+	counts := hs.CountAll()
+	report := &jsonEndpointsReport{
+		SchemaVersion: JSONSchemaVersion,
+		Type:          "endpoints",
+		Percentiles:   p.percentiles,
+		Global: &jsonEndpointsGlobal{
+			Count: counts["count"],
+			Status: jsonStatus{
+				Status1xx: counts["1xx"],
+				Status2xx: counts["2xx"],
+				Status3xx: counts["3xx"],
+				Status4xx: counts["4xx"],
+				Status5xx: counts["5xx"],
+			},
+		},
+		Endpoints: make([]*jsonEndpoint, 0, len(hs.stats)),
+	}
+
+	for _, s := range hs.stats {
+		e := &jsonEndpoint{
+			Service: s.Service,
+			Method:  s.Method,
+			Uri:     s.UriWithOptions(p.printOptions.decodeUri),
+			Groups:  s.Groups,
+			Count:   s.Count(),
+			Status: jsonStatus{
+				Status1xx: s.Status1xx,
+				Status2xx: s.Status2xx,
+				Status3xx: s.Status3xx,
+				Status4xx: s.Status4xx,
+				Status5xx: s.Status5xx,
+			},
+			ResponseTime:      newJSONResponseTime(s.ResponseTime, s.Count(), p.percentiles),
+			RequestBodyBytes:  newJSONBodyBytes(s.RequestBodyBytes, s.Count(), p.percentiles),
+			ResponseBodyBytes: newJSONBodyBytes(s.ResponseBodyBytes, s.Count(), p.percentiles),
+			RequestTime:       newJSONRequestTime(s.RequestTime, p.percentiles),
+		}
+		if s.TCP != nil {
+			e.TCP = &jsonTCP{
+				AvgHandshake: s.AvgHandshakeRTT(),
+				AvgTTFB:      s.AvgFirstByteTime(),
+				AvgTTLB:      s.AvgLastByteTime(),
+				Retrans:      s.Retransmissions(),
+				Reused:       s.ReusedConnections(),
+			}
+		}
+		report.Endpoints = append(report.Endpoints, e)
+	}
+
+	return report
+}
+
+// printJSON outputs only the stats to compare with in the diff, the output columns are ignored
+func (p *Printer) printJSON(hsFrom, hsTo *HTTPStats, ndjson bool) error {
+	hs := hsFrom
+	if hsTo != nil {
+		hs = hsTo
+	}
+
+	report := p.jsonEndpointsReport(hs)
+	if !ndjson {
+		return printIndentedJSON(p.writer, report)
+	}
+
+	records := make([]*ndjsonRecord, 0, len(report.Endpoints)+1)
+	records = append(records, &ndjsonRecord{
+		header: ndjsonHeader{SchemaVersion: report.SchemaVersion, Type: "global", Percentiles: report.Percentiles},
+		fields: report.Global,
+	})
+	for _, e := range report.Endpoints {
+		records = append(records, &ndjsonRecord{
+			header: ndjsonHeader{SchemaVersion: report.SchemaVersion, Type: "endpoint"},
+			fields: e,
+		})
+	}
+	return printNDJSON(p.writer, records)
+}
+
+func (p *TracePrinter) jsonScenariosReport(ts *TraceStats) *jsonScenariosReport {
+	g := ts.GlobalStat
+	report := &jsonScenariosReport{
+		SchemaVersion: JSONSchemaVersion,
+		Type:          "scenarios",
+		Percentiles:   p.percentiles,
+		Global: &jsonScenariosGlobal{
+			Traces:            ts.CountAll()["count"],
+			Requests:          g.Cnt,
+			ResponseTime:      newJSONResponseTime(g.ResponseTime, g.Cnt, p.percentiles),
+			RequestBodyBytes:  newJSONBodyBytes(g.RequestBodyBytes, g.Cnt, p.percentiles),
+			ResponseBodyBytes: newJSONBodyBytes(g.ResponseBodyBytes, g.Cnt, p.percentiles),
+		},
+		Scenarios: make([]*jsonScenario, 0, len(ts.ScenarioStats)),
+	}
+
+	for _, s := range ts.ScenarioStats {
+		sc := &jsonScenario{
+			ID:    s.ID,
+			Count: s.Count(),
+			Status: jsonStatus{
+				Status1xx: s.Status1xx,
+				Status2xx: s.Status2xx,
+				Status3xx: s.Status3xx,
+				Status4xx: s.Status4xx,
+				Status5xx: s.Status5xx,
+			},
+			ErrorRate:         s.ErrorRate(),
+			ResponseTime:      newJSONResponseTime(s.ResponseTime, s.Count(), p.percentiles),
+			RequestBodyBytes:  newJSONBodyBytes(s.RequestBodyBytes, s.Count(), p.percentiles),
+			ResponseBodyBytes: newJSONBodyBytes(s.ResponseBodyBytes, s.Count(), p.percentiles),
+			TraceIDs:          s.TraceIDs,
+			Steps:             make([]*jsonStep, 0, len(s.RequestDetailsStats)),
+		}
+
+		for _, rds := range s.RequestDetailsStats {
+			step := &jsonStep{
+				Method: rds.RequestDetail.Method,
+				Uri:    rds.RequestDetail.Uri,
+				Count:  rds.Count(),
+				Status: jsonStatus{
+					Status1xx: rds.Status1xx,
+					Status2xx: rds.Status2xx,
+					Status3xx: rds.Status3xx,
+					Status4xx: rds.Status4xx,
+					Status5xx: rds.Status5xx,
+				},
+				ErrorRate:         rds.ErrorRate(),
+				ResponseTime:      newJSONResponseTime(rds.ResponseTime, rds.Count(), p.percentiles),
+				RequestBodyBytes:  newJSONBodyBytes(rds.RequestBodyBytes, rds.Count(), p.percentiles),
+				ResponseBodyBytes: newJSONBodyBytes(rds.ResponseBodyBytes, rds.Count(), p.percentiles),
+				RequestTime:       newJSONRequestTime(rds.RequestTime, p.percentiles),
+			}
+			// the status is not a part of the scenario with --scenario-key=uri_method
+			if !ts.ignoreStatus() {
+				step.StatusCode = rds.RequestDetail.Status
+			}
+			sc.Steps = append(sc.Steps, step)
+		}
+
+		report.Scenarios = append(report.Scenarios, sc)
+	}
+
+	return report
+}
+
+// printTraceJSON outputs only the stats to compare with in the diff, the output columns are ignored
+func (p *TracePrinter) printTraceJSON(tsFrom, tsTo *TraceStats, ndjson bool) error {
+	ts := tsFrom
+	if tsTo != nil {
+		ts = tsTo
+	}
+
+	report := p.jsonScenariosReport(ts)
+	if !ndjson {
+		return printIndentedJSON(p.writer, report)
+	}
+
+	records := make([]*ndjsonRecord, 0, len(report.Scenarios)+1)
+	records = append(records, &ndjsonRecord{
+		header: ndjsonHeader{SchemaVersion: report.SchemaVersion, Type: "global", Percentiles: report.Percentiles},
+		fields: report.Global,
+	})
+	for _, sc := range report.Scenarios {
+		records = append(records, &ndjsonRecord{
+			header: ndjsonHeader{SchemaVersion: report.SchemaVersion, Type: "scenario"},
+			fields: sc,
+		})
+	}
+	return printNDJSON(p.writer, records)
+}
+
+func printIndentedJSON(w io.Writer, v interface{}) error {
+	buf, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(w, string(buf))
+	return err
+}
+
+func printNDJSON(w io.Writer, records []*ndjsonRecord) error {
+	enc := json.NewEncoder(w)
+	for _, r := range records {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
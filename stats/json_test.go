@@ -0,0 +1,99 @@
+package stats
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestPrintJSON(t *testing.T) {
+	hs := NewHTTPStats(true, false, false)
+	hs.Set("/foo", "GET", 200, 0.1, 10, 0)
+	hs.Set("/foo", "GET", 500, 0.3, 20, 0)
+	hs.Set("/bar", "POST", 200, 0.2, 30, 0)
+
+	var buf bytes.Buffer
+	p := NewPrinter(&buf, "all", "json", []int{50, 99}, NewPrintOptions(false, false, false, 0))
+	if err := p.Print(hs, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var report struct {
+		SchemaVersion int `json:"schema_version"`
+		Global        struct {
+			Count int `json:"count"`
+		} `json:"global"`
+		Endpoints []struct {
+			Method       string `json:"method"`
+			Uri          string `json:"uri"`
+			Count        int    `json:"count"`
+			ResponseTime struct {
+				Max         float64            `json:"max"`
+				Percentiles map[string]float64 `json:"percentiles"`
+			} `json:"response_time"`
+		} `json:"endpoints"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+
+	if report.SchemaVersion != JSONSchemaVersion {
+		t.Errorf("want schema version: %d, got: %d", JSONSchemaVersion, report.SchemaVersion)
+	}
+
+	if report.Global.Count != 3 {
+		t.Errorf("want global count: 3, got: %d", report.Global.Count)
+	}
+
+	if len(report.Endpoints) != 2 {
+		t.Fatalf("want 2 endpoints, got: %d", len(report.Endpoints))
+	}
+
+	foo := report.Endpoints[0]
+	if foo.Method != "GET" || foo.Uri != "/foo" || foo.Count != 2 {
+		t.Errorf("want GET /foo 2, got: %s %s %d", foo.Method, foo.Uri, foo.Count)
+	}
+
+	if foo.ResponseTime.Max != 0.3 || foo.ResponseTime.Percentiles["p99"] != 0.3 {
+		t.Errorf("want max and p99: 0.3, got: %v", foo.ResponseTime)
+	}
+
+	buf.Reset()
+	p.SetFormat("ndjson")
+	if err := p.Print(hs, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	types := make([]string, 0)
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var record struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatal(err)
+		}
+		types = append(types, record.Type)
+	}
+
+	if len(types) != 3 || types[0] != "global" || types[1] != "endpoint" {
+		t.Errorf("want global and 2 endpoints, got: %v", types)
+	}
+}
+
+func TestPrintJSONMarshalError(t *testing.T) {
+	hs := NewHTTPStats(true, false, false)
+	hs.Set("/foo", "GET", 200, math.NaN(), 10, 0)
+
+	for _, format := range []string{"json", "ndjson"} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			p := NewPrinter(&buf, "all", format, []int{99}, NewPrintOptions(false, false, false, 0))
+			if err := p.Print(hs, nil); err == nil {
+				t.Errorf("want the error of the marshal, got: %s", buf.String())
+			}
+		})
+	}
+}
@@ -1,11 +1,16 @@
 package stats
 
 import (
+	"fmt"
 	"io"
+	"sort"
+	"strings"
 
 	"gopkg.in/yaml.v2"
 )
 
+// LoadStats loads the dumped stats, the group-by fields are restored from the stats unless SetGroupBy is called,
+// and the stats grouped by the other fields are rejected
 func (hs *HTTPStats) LoadStats(r io.Reader) error {
 	buf, err := io.ReadAll(r)
 	if err != nil {
@@ -14,7 +19,35 @@ func (hs *HTTPStats) LoadStats(r io.Reader) error {
 
 	var stats []*HTTPStat
 	err = yaml.Unmarshal(buf, &stats)
+	if err != nil {
+		return err
+	}
+
+	fields := groupFields(stats)
+	if len(hs.groupBy) == 0 {
+		hs.groupBy = fields
+	} else if !sameFields(hs.groupBy, fields) {
+		return fmt.Errorf("the stats are grouped by [%s], not by --group-by=%s", strings.Join(fields, ","), strings.Join(hs.groupBy, ","))
+	}
+
 	hs.stats = stats
+	hs.reindex()
+
+	return nil
+}
+
+func sameFields(groupBy, fields []string) bool {
+	if len(groupBy) != len(fields) {
+		return false
+	}
+
+	sorted := append([]string{}, groupBy...)
+	sort.Strings(sorted)
+	for i, field := range sorted {
+		if field != fields[i] {
+			return false
+		}
+	}
 
-	return err
+	return true
 }
@@ -2,7 +2,10 @@ package stats
 
 import (
 	"bytes"
+	"reflect"
 	"testing"
+
+	"github.com/tetsuzawa/alp-trace/parsers"
 )
 
 func TestLoadStats(t *testing.T) {
@@ -95,3 +98,59 @@ func TestLoadStats(t *testing.T) {
 		t.Errorf(`status5xx want: %d, got: %d`, status5xx, s[0].Status5xx)
 	}
 }
+
+func TestLoadStatsGroupBy(t *testing.T) {
+	dumped := NewHTTPStats(true, false, false)
+	dumped.SetGroupBy([]string{"host"})
+	dumped.SetWithService("", "/foo", "GET", []string{"a"}, 200, 0.1, 10, 0)
+	dumped.SetWithService("", "/foo", "GET", []string{"b"}, 200, 0.2, 10, 0)
+
+	var buf bytes.Buffer
+	if err := dumped.DumpStats(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		groupBy []string
+		wantErr bool
+	}{
+		{name: "restored"},
+		{name: "same", groupBy: []string{"host"}},
+		{name: "other", groupBy: []string{"upstream_addr"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hs := NewHTTPStats(true, false, false)
+			hs.SetGroupBy(tt.groupBy)
+
+			err := hs.LoadStats(bytes.NewReader(buf.Bytes()))
+			if tt.wantErr {
+				if err == nil {
+					t.Error("want the error of the group-by fields")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(hs.GroupBy(), []string{"host"}) {
+				t.Errorf("want the group-by fields: [host], got: %v", hs.GroupBy())
+			}
+
+			// the groups are not merged by the requests set after the sort
+			hs.Sort(NewSortOptions(), false)
+			hs.SetWithService("", "/foo", "GET", hs.Groups(parsers.LogEntries{"host": "b"}), 200, 0.3, 10, 0)
+			if hs.CountUris() != 2 || len(hs.Stats()) != 2 {
+				t.Fatalf("want 2 stats, got: %d in %d", hs.CountUris(), len(hs.Stats()))
+			}
+			for _, s := range hs.Stats() {
+				if want := map[string]int{"a": 1, "b": 2}[s.Groups["host"]]; s.Cnt != want {
+					t.Errorf("%s: want count: %d, got: %d", s.Groups["host"], want, s.Cnt)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package stats
+
+import (
+	"fmt"
+	"strings"
+)
+
+// the characters that end or break a message of mermaid are written as the entity codes
+var mermaidReplacer = strings.NewReplacer("#", "#35;", ";", "#59;", "\n", " ", "\r", " ")
+
+// printTraceMermaid outputs the sequence diagram of each scenario in markdown,
+// the scenarios are limited by --limit in the sorted order
+func (p *TracePrinter) printTraceMermaid(tsFrom, tsTo *TraceStats) {
+	ts := tsFrom
+	if tsTo != nil {
+		ts = tsTo
+	}
+
+	for i, s := range ts.ScenarioStats {
+		if i > 0 {
+			fmt.Fprintln(p.writer)
+		}
+
+		fmt.Fprintf(p.writer, "### %d. Scenario %s\n\n", i+1, s.ID)
+		fmt.Fprintf(p.writer, "count: %d, error rate: %.2f%%, avg: %s, p95: %s\n\n",
+			s.Count(), s.ErrorRate(), milliseconds(s.AvgResponseTime()), milliseconds(s.PNResponseTime(95)))
+
+		fmt.Fprintln(p.writer, "```mermaid")
+		fmt.Fprintln(p.writer, "sequenceDiagram")
+		fmt.Fprintln(p.writer, "    participant Client")
+		fmt.Fprintln(p.writer, "    participant Server")
+		for _, rds := range s.RequestDetailsStats {
+			status := fmt.Sprint(rds.RequestDetail.Status)
+			// the status varies in a scenario if the scenario key ignores it
+			if ts.ignoreStatus() {
+				status = "*"
+			}
+
+			fmt.Fprintf(p.writer, "    Client->>+Server: %s %s\n",
+				rds.RequestDetail.Method, mermaidReplacer.Replace(rds.RequestDetail.Uri))
+			fmt.Fprintf(p.writer, "    Server-->>-Client: %s (avg %s, p95 %s, count %d)\n",
+				status, milliseconds(rds.AvgResponseTime()), milliseconds(rds.PNResponseTime(95)), rds.Count())
+		}
+		fmt.Fprintln(p.writer, "```")
+	}
+}
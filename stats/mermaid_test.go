@@ -0,0 +1,88 @@
+package stats
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/tetsuzawa/alp-trace/options"
+)
+
+func TestPrintTraceMermaid(t *testing.T) {
+	tests := []struct {
+		name        string
+		scenarioKey string
+		requests    []traceRequest
+		want        string
+	}{
+		{
+			name:        "scenario",
+			scenarioKey: options.ScenarioKeyUriMethodStatus,
+			requests: []traceRequest{
+				{traceID: "a", method: "POST", uri: "/login", status: 200, restime: 0.1},
+				{traceID: "a", method: "GET", uri: "/home", status: 200, restime: 0.2},
+			},
+			want: "### 1. Scenario %s\n\n" +
+				"count: 1, error rate: 0.00%%, avg: 300.0ms, p95: 300.0ms\n\n" +
+				"```mermaid\n" +
+				"sequenceDiagram\n" +
+				"    participant Client\n" +
+				"    participant Server\n" +
+				"    Client->>+Server: POST /login\n" +
+				"    Server-->>-Client: 200 (avg 100.0ms, p95 100.0ms, count 1)\n" +
+				"    Client->>+Server: GET /home\n" +
+				"    Server-->>-Client: 200 (avg 200.0ms, p95 200.0ms, count 1)\n" +
+				"```\n",
+		},
+		{
+			name:        "escape",
+			scenarioKey: options.ScenarioKeyUriMethodStatus,
+			requests: []traceRequest{
+				{traceID: "a", method: "GET", uri: "/search?q=a;b#top", status: 404, restime: 0.1},
+			},
+			want: "### 1. Scenario %s\n\n" +
+				"count: 1, error rate: 100.00%%, avg: 100.0ms, p95: 100.0ms\n\n" +
+				"```mermaid\n" +
+				"sequenceDiagram\n" +
+				"    participant Client\n" +
+				"    participant Server\n" +
+				"    Client->>+Server: GET /search?q=a#59;b#35;top\n" +
+				"    Server-->>-Client: 404 (avg 100.0ms, p95 100.0ms, count 1)\n" +
+				"```\n",
+		},
+		{
+			name:        "ignore status",
+			scenarioKey: options.ScenarioKeyUriMethod,
+			requests: []traceRequest{
+				{traceID: "a", method: "GET", uri: "/home", status: 200, restime: 0.1},
+				{traceID: "b", method: "GET", uri: "/home", status: 500, restime: 0.3},
+			},
+			want: "### 1. Scenario %s\n\n" +
+				"count: 2, error rate: 50.00%%, avg: 200.0ms, p95: 300.0ms\n\n" +
+				"```mermaid\n" +
+				"sequenceDiagram\n" +
+				"    participant Client\n" +
+				"    participant Server\n" +
+				"    Client->>+Server: GET /home\n" +
+				"    Server-->>-Client: * (avg 200.0ms, p95 300.0ms, count 2)\n" +
+				"```\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestTraceStats(tt.scenarioKey, tt.requests)
+
+			var buf bytes.Buffer
+			p := NewTracePrinter(&buf, "", "mermaid", []int{95}, NewTracePrintOptions(false, false, false, 0))
+			if err := p.Print(ts, nil); err != nil {
+				t.Fatal(err)
+			}
+
+			want := fmt.Sprintf(tt.want, ts.ScenarioStats[0].ID)
+			if buf.String() != want {
+				t.Errorf("want:\n%s\ngot:\n%s", want, buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package stats
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// the metric names are a part of the output format, do not rename them
+const (
+	omHTTPRequests           = "alp_http_requests"
+	omHTTPResponseTime       = "alp_http_response_time_seconds"
+	omHTTPResponseBodyBytes  = "alp_http_response_body_bytes"
+	omHTTPRequestBodyBytes   = "alp_http_request_body_bytes"
+	omTraceScenarios         = "alp_trace_scenarios"
+	omTraceResponseTime      = "alp_trace_scenario_response_time_seconds"
+	omTraceResponseBodyBytes = "alp_trace_scenario_response_body_bytes"
+	omTraceRequestBodyBytes  = "alp_trace_scenario_request_body_bytes"
+	omStatusLabel            = "status"
+	omQuantileLabel          = "quantile"
+	omScenarioIDLabel        = "scenario_id"
+)
+
+var omInvalidLabelNameChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+var omLabelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+var omHelpReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+type omLabel struct {
+	name  string
+	value string
+}
+
+// openMetrics writes the OpenMetrics text exposition format
+type openMetrics struct {
+	w io.Writer
+}
+
+func (om *openMetrics) family(name, typ, unit, help string) {
+	fmt.Fprintf(om.w, "# TYPE %s %s\n", name, typ)
+	if unit != "" {
+		fmt.Fprintf(om.w, "# UNIT %s %s\n", name, unit)
+	}
+	fmt.Fprintf(om.w, "# HELP %s %s\n", name, omHelpReplacer.Replace(help))
+}
+
+func (om *openMetrics) sample(name string, labels []omLabel, value float64) {
+	pairs := make([]string, 0, len(labels))
+	for _, l := range labels {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, l.name, omLabelValueReplacer.Replace(l.value)))
+	}
+
+	var ls string
+	if len(pairs) > 0 {
+		ls = "{" + strings.Join(pairs, ",") + "}"
+	}
+
+	fmt.Fprintf(om.w, "%s%s %s\n", name, ls, strconv.FormatFloat(value, 'f', -1, 64))
+}
+
+func (om *openMetrics) statusCounters(name string, labels []omLabel, counts [5]int) {
+	for i, c := range counts {
+		om.sample(name+"_total", withOMLabels(labels, omLabel{omStatusLabel, fmt.Sprintf("%dxx", i+1)}), float64(c))
+	}
+}
+
+// summary writes the quantiles only if pn is not nil
+func (om *openMetrics) summary(name string, labels []omLabel, percentiles []int, pn func(int) float64, sum float64, cnt int) {
+	if pn != nil {
+		for _, n := range percentiles {
+			quantile := strconv.FormatFloat(float64(n)/100, 'f', -1, 64)
+			om.sample(name, withOMLabels(labels, omLabel{omQuantileLabel, quantile}), pn(n))
+		}
+	}
+	om.sample(name+"_sum", labels, sum)
+	om.sample(name+"_count", labels, float64(cnt))
+}
+
+func (om *openMetrics) eof() {
+	fmt.Fprintln(om.w, "# EOF")
+}
+
+func withOMLabels(labels []omLabel, extra ...omLabel) []omLabel {
+	return append(append(make([]omLabel, 0, len(labels)+len(extra)), labels...), extra...)
+}
+
+// omGroupLabelName replaces the characters that are not allowed in the label names,
+// and prefixes the names that conflict with the labels of alp-trace
+func omGroupLabelName(name string) string {
+	name = omInvalidLabelNameChars.ReplaceAllString(name, "_")
+	switch name {
+	case "service", "method", "uri", omStatusLabel, omQuantileLabel:
+		return "group_" + name
+	}
+
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+
+	return name
+}
+
+func (p *Printer) httpStatOMLabels(hs *HTTPStats, s *HTTPStat) []omLabel {
+	labels := make([]omLabel, 0, 3)
+	if hs.HasServices() {
+		labels = append(labels, omLabel{"service", s.Service})
+	}
+	labels = append(labels,
+		omLabel{"method", s.Method},
+		omLabel{"uri", s.UriWithOptions(p.printOptions.decodeUri)},
+	)
+	for _, field := range hs.GroupBy() {
+		labels = append(labels, omLabel{omGroupLabelName(field), s.Groups[field]})
+	}
+
+	return labels
+}
+
+// printOpenMetrics outputs only the stats to compare with in the diff, the output columns are ignored
+func (p *Printer) printOpenMetrics(hsFrom, hsTo *HTTPStats) {
+	hs := hsFrom
+	if hsTo != nil {
+		hs = hsTo
+	}
+
+	labels := make([][]omLabel, len(hs.stats))
+	for i, s := range hs.stats {
+		labels[i] = p.httpStatOMLabels(hs, s)
+	}
+
+	om := &openMetrics{w: p.writer}
+
+	om.family(omHTTPRequests, "counter", "", "The number of the requests per status class")
+	for i, s := range hs.stats {
+		om.statusCounters(omHTTPRequests, labels[i], [5]int{s.Status1xx, s.Status2xx, s.Status3xx, s.Status4xx, s.Status5xx})
+	}
+
+	om.family(omHTTPResponseTime, "summary", "seconds", "The response time")
+	for i, s := range hs.stats {
+		om.summary(omHTTPResponseTime, labels[i], p.percentiles, s.PNResponseTime, s.SumResponseTime(), s.Count())
+	}
+
+	om.family(omHTTPResponseBodyBytes, "summary", "bytes", "The response body size")
+	for i, s := range hs.stats {
+		om.summary(omHTTPResponseBodyBytes, labels[i], p.percentiles, nil, s.SumResponseBodyBytes(), s.Count())
+	}
+
+	if hs.HasRequestBodyBytes() {
+		om.family(omHTTPRequestBodyBytes, "summary", "bytes", "The request body size")
+		for i, s := range hs.stats {
+			om.summary(omHTTPRequestBodyBytes, labels[i], p.percentiles, nil, s.SumRequestBodyBytes(), s.Count())
+		}
+	}
+
+	om.eof()
+}
+
+// printTraceOpenMetrics outputs only the stats to compare with in the diff, the output columns are ignored
+func (p *TracePrinter) printTraceOpenMetrics(tsFrom, tsTo *TraceStats) {
+	ts := tsFrom
+	if tsTo != nil {
+		ts = tsTo
+	}
+
+	labels := make([][]omLabel, len(ts.ScenarioStats))
+	for i, s := range ts.ScenarioStats {
+		labels[i] = []omLabel{{omScenarioIDLabel, s.ID}}
+	}
+
+	om := &openMetrics{w: p.writer}
+
+	om.family(omTraceScenarios, "counter", "", "The number of the traces per status class of the worst request")
+	for i, s := range ts.ScenarioStats {
+		om.statusCounters(omTraceScenarios, labels[i], [5]int{s.Status1xx, s.Status2xx, s.Status3xx, s.Status4xx, s.Status5xx})
+	}
+
+	om.family(omTraceResponseTime, "summary", "seconds", "The total response time of the requests in a trace")
+	for i, s := range ts.ScenarioStats {
+		om.summary(omTraceResponseTime, labels[i], p.percentiles, s.PNResponseTime, s.SumResponseTime(), s.Count())
+	}
+
+	om.family(omTraceResponseBodyBytes, "summary", "bytes", "The total response body size of the requests in a trace")
+	for i, s := range ts.ScenarioStats {
+		om.summary(omTraceResponseBodyBytes, labels[i], p.percentiles, nil, s.SumResponseBodyBytes(), s.Count())
+	}
+
+	if ts.HasRequestBodyBytes() {
+		om.family(omTraceRequestBodyBytes, "summary", "bytes", "The total request body size of the requests in a trace")
+		for i, s := range ts.ScenarioStats {
+			om.summary(omTraceRequestBodyBytes, labels[i], p.percentiles, nil, s.SumRequestBodyBytes(), s.Count())
+		}
+	}
+
+	om.eof()
+}
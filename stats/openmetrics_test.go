@@ -0,0 +1,38 @@
+package stats
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPrintOpenMetrics(t *testing.T) {
+	hs := NewHTTPStats(true, false, false)
+	hs.Set(`/foo"bar\baz`+"\n", "GET", 200, 0.1, 10, 0)
+	hs.Set(`/foo"bar\baz`+"\n", "GET", 500, 0.3, 20, 0)
+
+	var buf bytes.Buffer
+	p := NewPrinter(&buf, "all", "openmetrics", []int{50}, NewPrintOptions(false, false, false, 0))
+	if err := p.Print(hs, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		`alp_http_requests_total{method="GET",uri="/foo\"bar\\baz\n",status="2xx"} 1`,
+		`alp_http_requests_total{method="GET",uri="/foo\"bar\\baz\n",status="5xx"} 1`,
+		`alp_http_response_time_seconds{method="GET",uri="/foo\"bar\\baz\n",quantile="0.5"} 0.1`,
+		`alp_http_response_time_seconds_count{method="GET",uri="/foo\"bar\\baz\n"} 2`,
+		`alp_http_response_body_bytes_sum{method="GET",uri="/foo\"bar\\baz\n"} 30`,
+	}
+
+	got := buf.String()
+	for _, line := range want {
+		if !strings.Contains(got, line+"\n") {
+			t.Errorf("want: %s, got:\n%s", line, got)
+		}
+	}
+
+	if !strings.HasSuffix(got, "# EOF\n") {
+		t.Errorf("want: # EOF at the end, got:\n%s", got)
+	}
+}
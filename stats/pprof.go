@@ -0,0 +1,129 @@
+package stats
+
+import (
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/google/pprof/profile"
+)
+
+// pprofBuilder makes the profile of the synthetic stacks,
+// the values of a sample are the count, the total response time and the total response body bytes
+type pprofBuilder struct {
+	prof      *profile.Profile
+	locations map[string]*profile.Location
+}
+
+func newPProfBuilder() *pprofBuilder {
+	return &pprofBuilder{
+		prof: &profile.Profile{
+			SampleType: []*profile.ValueType{
+				{Type: "requests", Unit: "count"},
+				{Type: "response_time", Unit: "nanoseconds"},
+				{Type: "response_body", Unit: "bytes"},
+			},
+			DefaultSampleType: "response_time",
+			PeriodType:        &profile.ValueType{Type: "requests", Unit: "count"},
+			Period:            1,
+		},
+		locations: make(map[string]*profile.Location),
+	}
+}
+
+func (b *pprofBuilder) location(name string) *profile.Location {
+	if loc, ok := b.locations[name]; ok {
+		return loc
+	}
+
+	fn := &profile.Function{
+		ID:         uint64(len(b.prof.Function) + 1),
+		Name:       name,
+		SystemName: name,
+	}
+	b.prof.Function = append(b.prof.Function, fn)
+
+	loc := &profile.Location{
+		ID:   uint64(len(b.prof.Location) + 1),
+		Line: []profile.Line{{Function: fn}},
+	}
+	b.prof.Location = append(b.prof.Location, loc)
+	b.locations[name] = loc
+
+	return loc
+}
+
+// add adds the sample of the stack, the first frame is the leaf
+func (b *pprofBuilder) add(stack []string, labels map[string][]string, cnt int, restime, resBodyBytes float64) {
+	locs := make([]*profile.Location, 0, len(stack))
+	for _, name := range stack {
+		locs = append(locs, b.location(name))
+	}
+
+	b.prof.Sample = append(b.prof.Sample, &profile.Sample{
+		Location: locs,
+		Value:    []int64{int64(cnt), int64(math.Round(restime * 1e9)), int64(math.Round(resBodyBytes))},
+		Label:    labels,
+	})
+}
+
+func (b *pprofBuilder) write(w io.Writer) error {
+	if err := b.prof.CheckValid(); err != nil {
+		return err
+	}
+
+	return b.prof.Write(w)
+}
+
+// printPProf outputs only the stats to compare with in the diff, use pprof -base to compare them
+func (p *Printer) printPProf(hsFrom, hsTo *HTTPStats) error {
+	hs := hsFrom
+	if hsTo != nil {
+		hs = hsTo
+	}
+
+	b := newPProfBuilder()
+	groupBy := hs.GroupBy()
+	for _, s := range hs.stats {
+		stack := []string{fmt.Sprintf("%s %s", s.Method, s.UriWithOptions(p.printOptions.decodeUri))}
+		if s.Service != "" {
+			stack = append(stack, s.Service)
+		}
+
+		var labels map[string][]string
+		if len(groupBy) > 0 {
+			labels = make(map[string][]string, len(groupBy))
+			for _, field := range groupBy {
+				labels[field] = []string{s.Groups[field]}
+			}
+		}
+
+		b.add(stack, labels, s.Count(), s.SumResponseTime(), s.SumResponseBodyBytes())
+	}
+
+	return b.write(p.writer)
+}
+
+// printTracePProf outputs the requests of each scenario as the children of the scenario
+func (p *TracePrinter) printTracePProf(tsFrom, tsTo *TraceStats) error {
+	ts := tsFrom
+	if tsTo != nil {
+		ts = tsTo
+	}
+
+	b := newPProfBuilder()
+	for _, s := range ts.ScenarioStats {
+		scenario := fmt.Sprintf("scenario %s", s.ID)
+		for _, rds := range s.RequestDetailsStats {
+			request := fmt.Sprintf("%s %s %d", rds.RequestDetail.Method, rds.RequestDetail.Uri, rds.RequestDetail.Status)
+			// the status varies in a scenario if the scenario key ignores it
+			if ts.ignoreStatus() {
+				request = fmt.Sprintf("%s %s", rds.RequestDetail.Method, rds.RequestDetail.Uri)
+			}
+
+			b.add([]string{request, scenario}, nil, rds.Count(), rds.SumResponseTime(), rds.SumResponseBodyBytes())
+		}
+	}
+
+	return b.write(p.writer)
+}
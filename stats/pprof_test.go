@@ -0,0 +1,72 @@
+package stats
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/google/pprof/profile"
+)
+
+func TestPrintPProf(t *testing.T) {
+	hs := NewHTTPStats(true, false, false)
+	hs.Set("/foo", "GET", 200, 0.1, 10, 0)
+	hs.Set("/foo", "GET", 200, 0.3, 20, 0)
+	hs.Set("/bar", "POST", 200, 0.2, 30, 0)
+
+	var buf bytes.Buffer
+	p := NewPrinter(&buf, "", "pprof", []int{99}, NewPrintOptions(false, false, false, 0))
+	if err := p.Print(hs, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	prof, err := profile.Parse(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][]int64{
+		"GET /foo":  {2, 400000000, 30},
+		"POST /bar": {1, 200000000, 30},
+	}
+	if len(prof.Sample) != len(want) {
+		t.Fatalf("want %d samples, got: %d", len(want), len(prof.Sample))
+	}
+	for _, s := range prof.Sample {
+		name := s.Location[0].Line[0].Function.Name
+		values, ok := want[name]
+		if !ok {
+			t.Errorf("unexpected sample: %s", name)
+			continue
+		}
+		for i, v := range values {
+			if s.Value[i] != v {
+				t.Errorf("%s: want: %v, got: %v", name, values, s.Value)
+				break
+			}
+		}
+	}
+}
+
+// failingWriter fails to write
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestPrintPProfWriteError(t *testing.T) {
+	hs := NewHTTPStats(true, false, false)
+	hs.Set("/foo", "GET", 200, 0.1, 10, 0)
+
+	p := NewPrinter(failingWriter{}, "", "pprof", []int{99}, NewPrintOptions(false, false, false, 0))
+	if err := p.Print(hs, nil); err == nil {
+		t.Error("want the error of the writer")
+	}
+
+	ts := newTestTraceStats("", []traceRequest{{traceID: "a", uri: "/foo", method: "GET", status: 200, restime: 0.1}})
+	tp := NewTracePrinter(failingWriter{}, "", "pprof", []int{99}, NewTracePrintOptions(false, false, false, 0))
+	if err := tp.Print(ts, nil); err == nil {
+		t.Error("want the error of the writer")
+	}
+}
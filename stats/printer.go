@@ -84,6 +84,66 @@ func defaultHeaders(percentiles []int) []string {
 	return s
 }
 
+func reqBodyKeywords() []string {
+	return []string{
+		"min_req_body",
+		"max_req_body",
+		"sum_req_body",
+		"avg_req_body",
+	}
+}
+
+func reqBodyHeaders() []string {
+	return []string{
+		"Min(ReqBody)",
+		"Max(ReqBody)",
+		"Sum(ReqBody)",
+		"Avg(ReqBody)",
+	}
+}
+
+func reqTimeKeywords() []string {
+	return []string{
+		"max_reqtime",
+		"sum_reqtime",
+		"avg_reqtime",
+		"max_overhead",
+		"sum_overhead",
+		"avg_overhead",
+	}
+}
+
+func reqTimeHeaders() []string {
+	return []string{
+		"Max(ReqTime)",
+		"Sum(ReqTime)",
+		"Avg(ReqTime)",
+		"Max(Overhead)",
+		"Sum(Overhead)",
+		"Avg(Overhead)",
+	}
+}
+
+func tcpKeywords() []string {
+	return []string{
+		"handshake",
+		"ttfb",
+		"ttlb",
+		"retrans",
+		"reused",
+	}
+}
+
+func tcpHeaders() []string {
+	return []string{
+		"Handshake",
+		"TTFB",
+		"TTLB",
+		"Retrans",
+		"Reused",
+	}
+}
+
 func headersMap(percentiles []int) map[string]string {
 	headers := map[string]string{
 		"count":    "Count",
@@ -103,6 +163,25 @@ func headersMap(percentiles []int) map[string]string {
 		"max_body": "Max(Body)",
 		"sum_body": "Sum(Body)",
 		"avg_body": "Avg(Body)",
+		// request body
+		"min_req_body": "Min(ReqBody)",
+		"max_req_body": "Max(ReqBody)",
+		"sum_req_body": "Sum(ReqBody)",
+		"avg_req_body": "Avg(ReqBody)",
+		// request time
+		"max_reqtime":  "Max(ReqTime)",
+		"sum_reqtime":  "Sum(ReqTime)",
+		"avg_reqtime":  "Avg(ReqTime)",
+		"max_overhead": "Max(Overhead)",
+		"sum_overhead": "Sum(Overhead)",
+		"avg_overhead": "Avg(Overhead)",
+		// pcap only
+		"service":   "Service",
+		"handshake": "Handshake",
+		"ttfb":      "TTFB",
+		"ttlb":      "TTLB",
+		"retrans":   "Retrans",
+		"reused":    "Reused",
 	}
 
 	for _, p := range percentiles {
@@ -139,6 +218,7 @@ type Printer struct {
 	headersMap   map[string]string
 	writer       io.Writer
 	all          bool
+	groupBy      []string
 }
 
 func NewPrinter(w io.Writer, val, format string, percentiles []int, printOptions *PrintOptions) *Printer {
@@ -170,6 +250,32 @@ func NewPrinter(w io.Writer, val, format string, percentiles []int, printOptions
 	return p
 }
 
+// SetGroupBy makes the group-by fields available as the output keywords
+func (p *Printer) SetGroupBy(fields []string) {
+	p.groupBy = fields
+	for _, field := range fields {
+		p.headersMap[field] = field
+	}
+
+	if p.all {
+		return
+	}
+
+	for i, key := range p.keywords {
+		p.headers[i] = p.headersMap[key]
+	}
+}
+
+func (p *Printer) isGroup(key string) bool {
+	for _, field := range p.groupBy {
+		if key == field {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (p *Printer) Validate() error {
 	if p.all {
 		return nil
@@ -194,11 +300,18 @@ func (p *Printer) GenerateLine(s *HTTPStat, quoteUri bool) []string {
 	line := make([]string, 0, keyLen)
 
 	for i := 0; i < keyLen; i++ {
+		if p.isGroup(p.keywords[i]) {
+			line = append(line, s.Groups[p.keywords[i]])
+			continue
+		}
+
 		switch p.keywords[i] {
 		case "count":
 			line = append(line, s.StrCount())
 		case "method":
 			line = append(line, s.Method)
+		case "service":
+			line = append(line, s.Service)
 		case "uri":
 			uri := s.UriWithOptions(p.printOptions.decodeUri)
 			if quoteUri && strings.Contains(s.Uri, ",") {
@@ -233,6 +346,36 @@ func (p *Printer) GenerateLine(s *HTTPStat, quoteUri bool) []string {
 			line = append(line, round(s.SumResponseBodyBytes()))
 		case "avg_body":
 			line = append(line, round(s.AvgResponseBodyBytes()))
+		case "min_req_body":
+			line = append(line, round(s.MinRequestBodyBytes()))
+		case "max_req_body":
+			line = append(line, round(s.MaxRequestBodyBytes()))
+		case "sum_req_body":
+			line = append(line, round(s.SumRequestBodyBytes()))
+		case "avg_req_body":
+			line = append(line, round(s.AvgRequestBodyBytes()))
+		case "max_reqtime":
+			line = append(line, round(s.MaxRequestTime()))
+		case "sum_reqtime":
+			line = append(line, round(s.SumRequestTime()))
+		case "avg_reqtime":
+			line = append(line, round(s.AvgRequestTime()))
+		case "max_overhead":
+			line = append(line, round(s.MaxOverhead()))
+		case "sum_overhead":
+			line = append(line, round(s.SumOverhead()))
+		case "avg_overhead":
+			line = append(line, round(s.AvgOverhead()))
+		case "handshake":
+			line = append(line, round(s.AvgHandshakeRTT()))
+		case "ttfb":
+			line = append(line, round(s.AvgFirstByteTime()))
+		case "ttlb":
+			line = append(line, round(s.AvgLastByteTime()))
+		case "retrans":
+			line = append(line, fmt.Sprint(s.Retransmissions()))
+		case "reused":
+			line = append(line, fmt.Sprint(s.ReusedConnections()))
 		default: // percentile
 			var n int
 			_, err := fmt.Sscanf(p.keywords[i], "p%d", &n)
@@ -260,11 +403,18 @@ func (p *Printer) GenerateLineWithDiff(from, to *HTTPStat, quoteUri bool) []stri
 	differ := NewDiffer(from, to)
 
 	for i := 0; i < keyLen; i++ {
+		if p.isGroup(p.keywords[i]) {
+			line = append(line, to.Groups[p.keywords[i]])
+			continue
+		}
+
 		switch p.keywords[i] {
 		case "count":
 			line = append(line, formattedLineWithDiff(to.StrCount(), differ.DiffCnt()))
 		case "method":
 			line = append(line, to.Method)
+		case "service":
+			line = append(line, to.Service)
 		case "uri":
 			uri := to.UriWithOptions(p.printOptions.decodeUri)
 			if quoteUri && strings.Contains(to.Uri, ",") {
@@ -299,6 +449,36 @@ func (p *Printer) GenerateLineWithDiff(from, to *HTTPStat, quoteUri bool) []stri
 			line = append(line, formattedLineWithDiff(round(to.SumResponseBodyBytes()), differ.DiffSumResponseBodyBytes()))
 		case "avg_body":
 			line = append(line, formattedLineWithDiff(round(to.AvgResponseBodyBytes()), differ.DiffAvgResponseBodyBytes()))
+		case "min_req_body":
+			line = append(line, formattedLineWithDiff(round(to.MinRequestBodyBytes()), differ.DiffMinRequestBodyBytes()))
+		case "max_req_body":
+			line = append(line, formattedLineWithDiff(round(to.MaxRequestBodyBytes()), differ.DiffMaxRequestBodyBytes()))
+		case "sum_req_body":
+			line = append(line, formattedLineWithDiff(round(to.SumRequestBodyBytes()), differ.DiffSumRequestBodyBytes()))
+		case "avg_req_body":
+			line = append(line, formattedLineWithDiff(round(to.AvgRequestBodyBytes()), differ.DiffAvgRequestBodyBytes()))
+		case "max_reqtime":
+			line = append(line, formattedLineWithDiff(round(to.MaxRequestTime()), differ.DiffMaxRequestTime()))
+		case "sum_reqtime":
+			line = append(line, formattedLineWithDiff(round(to.SumRequestTime()), differ.DiffSumRequestTime()))
+		case "avg_reqtime":
+			line = append(line, formattedLineWithDiff(round(to.AvgRequestTime()), differ.DiffAvgRequestTime()))
+		case "max_overhead":
+			line = append(line, formattedLineWithDiff(round(to.MaxOverhead()), differ.DiffMaxOverhead()))
+		case "sum_overhead":
+			line = append(line, formattedLineWithDiff(round(to.SumOverhead()), differ.DiffSumOverhead()))
+		case "avg_overhead":
+			line = append(line, formattedLineWithDiff(round(to.AvgOverhead()), differ.DiffAvgOverhead()))
+		case "handshake":
+			line = append(line, formattedLineWithDiff(round(to.AvgHandshakeRTT()), differ.DiffAvgHandshakeRTT()))
+		case "ttfb":
+			line = append(line, formattedLineWithDiff(round(to.AvgFirstByteTime()), differ.DiffAvgFirstByteTime()))
+		case "ttlb":
+			line = append(line, formattedLineWithDiff(round(to.AvgLastByteTime()), differ.DiffAvgLastByteTime()))
+		case "retrans":
+			line = append(line, formattedLineWithDiff(fmt.Sprint(to.Retransmissions()), differ.DiffRetransmissions()))
+		case "reused":
+			line = append(line, formattedLineWithDiff(fmt.Sprint(to.ReusedConnections()), differ.DiffReusedConnections()))
 		default: // percentile
 			var n int
 			_, err := fmt.Sscanf(p.keywords[i], "p%d", &n)
@@ -377,7 +557,46 @@ func (p *Printer) SetWriter(w io.Writer) {
 	p.writer = w
 }
 
-func (p *Printer) Print(hs, hsTo *HTTPStats) {
+// showOptionalColumns adds the columns to the default output only if the stats have the values,
+// the group-by fields, the request body bytes, the request time, and the service and TCP metrics of pcap
+func (p *Printer) showOptionalColumns(hs *HTTPStats) {
+	if !p.all {
+		return
+	}
+
+	p.keywords = keywords(p.percentiles)
+	p.headers = defaultHeaders(p.percentiles)
+
+	if groupBy := hs.GroupBy(); len(groupBy) > 0 {
+		p.SetGroupBy(groupBy)
+		p.keywords = append(append([]string{}, groupBy...), p.keywords...)
+		p.headers = append(append([]string{}, groupBy...), p.headers...)
+	}
+
+	if hs.HasServices() {
+		p.keywords = append([]string{"service"}, p.keywords...)
+		p.headers = append([]string{"Service"}, p.headers...)
+	}
+
+	if hs.HasRequestBodyBytes() {
+		p.keywords = append(p.keywords, reqBodyKeywords()...)
+		p.headers = append(p.headers, reqBodyHeaders()...)
+	}
+
+	if hs.HasRequestTime() {
+		p.keywords = append(p.keywords, reqTimeKeywords()...)
+		p.headers = append(p.headers, reqTimeHeaders()...)
+	}
+
+	if hs.HasTCPMetrics() {
+		p.keywords = append(p.keywords, tcpKeywords()...)
+		p.headers = append(p.headers, tcpHeaders()...)
+	}
+}
+
+func (p *Printer) Print(hs, hsTo *HTTPStats) error {
+	p.showOptionalColumns(hs)
+
 	switch p.format {
 	case "table":
 		p.printTable(hs, hsTo)
@@ -388,23 +607,51 @@ func (p *Printer) Print(hs, hsTo *HTTPStats) {
 	case "csv":
 		p.printCSV(hs, hsTo)
 	case "html":
-		p.printHTML(hs, hsTo)
+		return p.printHTML(hs, hsTo)
+	case "openmetrics":
+		p.printOpenMetrics(hs, hsTo)
+	case "json":
+		return p.printJSON(hs, hsTo, false)
+	case "ndjson":
+		return p.printJSON(hs, hsTo, true)
+	case "pprof":
+		return p.printPProf(hs, hsTo)
 	}
+
+	return nil
 }
 
 func round(num float64) string {
 	return fmt.Sprintf("%.3f", num)
 }
 
+func milliseconds(sec float64) string {
+	return fmt.Sprintf("%.1fms", sec*1000)
+}
+
 func findHTTPStatFrom(hsFrom *HTTPStats, hsTo *HTTPStat) *HTTPStat {
 	for _, sFrom := range hsFrom.stats {
-		if sFrom.Uri == hsTo.Uri && sFrom.Method == hsTo.Method {
+		if sFrom.Uri == hsTo.Uri && sFrom.Method == hsTo.Method && sFrom.Service == hsTo.Service && sameGroups(sFrom.Groups, hsTo.Groups) {
 			return sFrom
 		}
 	}
 	return nil
 }
 
+func sameGroups(from, to map[string]string) bool {
+	if len(from) != len(to) {
+		return false
+	}
+
+	for field, val := range to {
+		if v, ok := from[field]; !ok || v != val {
+			return false
+		}
+	}
+
+	return true
+}
+
 func (p *Printer) printTable(hsFrom, hsTo *HTTPStats) {
 	table := tablewriter.NewWriter(p.writer)
 	table.SetHeader(p.headers)
@@ -530,7 +777,19 @@ func (p *Printer) printCSV(hsFrom, hsTo *HTTPStats) {
 	}
 }
 
-func (p *Printer) printHTML(hsFrom, hsTo *HTTPStats) {
+// Table returns the headers and the lines of the stats, as the interactive formats render the table by themselves
+func (p *Printer) Table(hs *HTTPStats) ([]string, [][]string) {
+	p.showOptionalColumns(hs)
+
+	data := make([][]string, 0, len(hs.stats))
+	for _, s := range hs.stats {
+		data = append(data, p.GenerateLine(s, false))
+	}
+
+	return p.headers, data
+}
+
+func (p *Printer) printHTML(hsFrom, hsTo *HTTPStats) error {
 	var data [][]string
 
 	if hsTo == nil {
@@ -548,6 +807,11 @@ func (p *Printer) printHTML(hsFrom, hsTo *HTTPStats) {
 			}
 		}
 	}
-	content, _ := html.RenderTableWithGridJS("alp", p.headers, data, p.printOptions.paginationLimit)
-	fmt.Println(content)
+	content, err := html.RenderTable("alp", p.headers, data, p.printOptions.paginationLimit)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(p.writer, content)
+	return err
 }
@@ -0,0 +1,47 @@
+package stats
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPrinterTable(t *testing.T) {
+	hs := NewHTTPStats(true, false, false)
+	hs.Set("/foo", "GET", 200, 0.1, 10, 0)
+	hs.Set("/foo", "GET", 500, 0.3, 20, 0)
+	hs.Set("/bar", "POST", 200, 0.2, 30, 0)
+
+	p := NewPrinter(nil, "count,method,uri,max", "table", []int{99}, NewPrintOptions(false, false, false, 0))
+	headers, data := p.Table(hs)
+
+	wantHeaders := []string{"Count", "Method", "Uri", "Max"}
+	if !reflect.DeepEqual(headers, wantHeaders) {
+		t.Errorf("want headers: %v, got: %v", wantHeaders, headers)
+	}
+
+	wantData := [][]string{
+		{"2", "GET", "/foo", "0.300"},
+		{"1", "POST", "/bar", "0.200"},
+	}
+	if !reflect.DeepEqual(data, wantData) {
+		t.Errorf("want data: %v, got: %v", wantData, data)
+	}
+}
+
+func TestPrintHTML(t *testing.T) {
+	hs := NewHTTPStats(true, false, false)
+	hs.Set("/foo", "GET", 200, 0.1, 10, 0)
+
+	var buf bytes.Buffer
+	p := NewPrinter(&buf, "count,method,uri", "html", []int{99}, NewPrintOptions(false, false, false, 0))
+	if err := p.Print(hs, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// the html is output to the writer, not to stdout
+	if !strings.Contains(buf.String(), "<html") || !strings.Contains(buf.String(), "/foo") {
+		t.Errorf("want the html of the stats, got: %.100s", buf.String())
+	}
+}
@@ -0,0 +1,214 @@
+package stats
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tkuchiki/parsetime"
+)
+
+// seriesSamplesLimit is the number of the response times sampled per point for the percentiles
+const seriesSamplesLimit = 1000
+
+// Series aggregates the requests into the time buckets by the parsed log time
+type Series struct {
+	bucket    time.Duration
+	labels    []string
+	parseTime parsetime.ParseTime
+	hints     *hints
+	points    []*seriesPoint
+	rand      *rand.Rand
+}
+
+// seriesPoint keeps the counts and a uniform sample of the response times of a bucket
+type seriesPoint struct {
+	time    time.Time
+	labels  []string
+	count   int
+	errors  int
+	samples []float64
+	sorted  bool
+}
+
+func NewSeries(bucket time.Duration, location string, labels []string) (*Series, error) {
+	pt, err := parsetime.NewParseTime(location)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Series{
+		bucket:    bucket,
+		labels:    labels,
+		parseTime: pt,
+		hints:     newHints(),
+		rand:      rand.New(rand.NewSource(1)),
+	}, nil
+}
+
+func (sr *Series) parse(timestr string) (time.Time, error) {
+	// parsetime does not fail on an empty time
+	if timestr == "" || timestr == "-" {
+		return time.Time{}, fmt.Errorf("empty time")
+	}
+
+	t, err := sr.parseTime.Parse(timestr)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse time: %s", timestr)
+	}
+
+	return t, nil
+}
+
+// Add counts the request in the bucket of t, 4xx and 5xx are the errors
+func (sr *Series) Add(t time.Time, labels []string, status int, restime float64) {
+	start := t.Truncate(sr.bucket)
+	key := fmt.Sprintf("%d\x00%s", start.UnixNano(), strings.Join(labels, "\x00"))
+
+	idx := sr.hints.loadOrStore(key)
+	if idx >= len(sr.points) {
+		sr.points = append(sr.points, &seriesPoint{
+			time:   start,
+			labels: labels,
+		})
+	}
+
+	p := sr.points[idx]
+	p.count++
+	if status >= 400 {
+		p.errors++
+	}
+
+	// reservoir sampling keeps each response time with the same probability
+	p.sorted = false
+	if len(p.samples) < seriesSamplesLimit {
+		p.samples = append(p.samples, restime)
+	} else if i := sr.rand.Intn(p.count); i < seriesSamplesLimit {
+		p.samples[i] = restime
+	}
+}
+
+func (p *seriesPoint) pn(n int) float64 {
+	if len(p.samples) == 0 {
+		return 0
+	}
+
+	if !p.sorted {
+		sort.Float64s(p.samples)
+		p.sorted = true
+	}
+	return p.samples[percentRank(len(p.samples), n)]
+}
+
+func (sr *Series) headers() []string {
+	headers := make([]string, 0, len(sr.labels)+5)
+	headers = append(headers, "time")
+	headers = append(headers, sr.labels...)
+	headers = append(headers, "count", "errors", "p50", "p99")
+
+	return headers
+}
+
+func (sr *Series) line(p *seriesPoint) []string {
+	line := make([]string, 0, len(p.labels)+5)
+	line = append(line, p.time.Format(time.RFC3339))
+	line = append(line, p.labels...)
+	line = append(line, fmt.Sprint(p.count), fmt.Sprint(p.errors), round(p.pn(50)), round(p.pn(99)))
+
+	return line
+}
+
+// Print outputs the series in long form, sorted by time
+func (sr *Series) Print(w io.Writer, format string) error {
+	if err := ValidateSeriesFormat(format); err != nil {
+		return err
+	}
+
+	// the points are sorted in a copy, the hints keep pointing to the points
+	points := append(make([]*seriesPoint, 0, len(sr.points)), sr.points...)
+	sort.SliceStable(points, func(i, j int) bool {
+		return points[i].time.Before(points[j].time)
+	})
+
+	switch format {
+	case "tsv":
+		sr.printSeparated(w, points, "\t")
+	case "csv":
+		sr.printSeparated(w, points, ",")
+	case "json":
+		return sr.printJSON(w, points)
+	}
+
+	return nil
+}
+
+// reset drops the points to aggregate them again
+func (sr *Series) reset() {
+	sr.hints = newHints()
+	sr.points = nil
+}
+
+func ValidateSeriesFormat(format string) error {
+	switch format {
+	case "tsv", "csv", "json":
+		return nil
+	}
+
+	return fmt.Errorf("--bucket supports only tsv, csv and json formats, got %s", format)
+}
+
+func (sr *Series) printSeparated(w io.Writer, points []*seriesPoint, sep string) {
+	fmt.Fprintln(w, strings.Join(sr.headers(), sep))
+	for _, p := range points {
+		line := sr.line(p)
+		if sep == "," {
+			for i, v := range line {
+				if strings.ContainsAny(v, `,"`) {
+					line[i] = fmt.Sprintf(`"%s"`, strings.ReplaceAll(v, `"`, `""`))
+				}
+			}
+		}
+		fmt.Fprintln(w, strings.Join(line, sep))
+	}
+}
+
+type jsonSeriesPoint struct {
+	Time   string            `json:"time"`
+	Labels map[string]string `json:"labels"`
+	Count  int               `json:"count"`
+	Errors int               `json:"errors"`
+	P50    float64           `json:"p50"`
+	P99    float64           `json:"p99"`
+}
+
+func (sr *Series) printJSON(w io.Writer, points []*seriesPoint) error {
+	jsonPoints := make([]*jsonSeriesPoint, 0, len(points))
+	for _, p := range points {
+		labels := make(map[string]string, len(sr.labels))
+		for i, label := range sr.labels {
+			labels[label] = p.labels[i]
+		}
+
+		jsonPoints = append(jsonPoints, &jsonSeriesPoint{
+			Time:   p.time.Format(time.RFC3339),
+			Labels: labels,
+			Count:  p.count,
+			Errors: p.errors,
+			P50:    p.pn(50),
+			P99:    p.pn(99),
+		})
+	}
+
+	buf, err := json.MarshalIndent(jsonPoints, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(w, string(buf))
+
+	return err
+}
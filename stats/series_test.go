@@ -0,0 +1,143 @@
+package stats
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestSeriesAdd(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type request struct {
+		offset  time.Duration
+		labels  []string
+		status  int
+		restime float64
+	}
+
+	tests := []struct {
+		name     string
+		requests []request
+		want     string
+	}{
+		{
+			name: "bucket",
+			requests: []request{
+				{offset: 0, labels: []string{"/a"}, status: 200, restime: 0.1},
+				{offset: 30 * time.Second, labels: []string{"/a"}, status: 200, restime: 0.3},
+				{offset: 70 * time.Second, labels: []string{"/a"}, status: 200, restime: 0.2},
+			},
+			want: "time\turi\tcount\terrors\tp50\tp99\n" +
+				"2024-01-01T00:00:00Z\t/a\t2\t0\t0.100\t0.300\n" +
+				"2024-01-01T00:01:00Z\t/a\t1\t0\t0.200\t0.200\n",
+		},
+		{
+			name: "labels",
+			requests: []request{
+				{offset: 0, labels: []string{"/a"}, status: 200, restime: 0.1},
+				{offset: 10 * time.Second, labels: []string{"/b"}, status: 200, restime: 0.2},
+			},
+			want: "time\turi\tcount\terrors\tp50\tp99\n" +
+				"2024-01-01T00:00:00Z\t/a\t1\t0\t0.100\t0.100\n" +
+				"2024-01-01T00:00:00Z\t/b\t1\t0\t0.200\t0.200\n",
+		},
+		{
+			name: "errors",
+			requests: []request{
+				{offset: 0, labels: []string{"/a"}, status: 200, restime: 0.1},
+				{offset: 1 * time.Second, labels: []string{"/a"}, status: 404, restime: 0.1},
+				{offset: 2 * time.Second, labels: []string{"/a"}, status: 503, restime: 0.1},
+				{offset: 3 * time.Second, labels: []string{"/a"}, status: 304, restime: 0.1},
+			},
+			want: "time\turi\tcount\terrors\tp50\tp99\n" +
+				"2024-01-01T00:00:00Z\t/a\t4\t2\t0.100\t0.100\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sr, err := NewSeries(time.Minute, "UTC", []string{"uri"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, r := range tt.requests {
+				sr.Add(start.Add(r.offset), r.labels, r.status, r.restime)
+			}
+
+			var buf bytes.Buffer
+			if err := sr.Print(&buf, "tsv"); err != nil {
+				t.Fatal(err)
+			}
+
+			if buf.String() != tt.want {
+				t.Errorf("want:\n%s\ngot:\n%s", tt.want, buf.String())
+			}
+		})
+	}
+}
+
+func TestSeriesSamples(t *testing.T) {
+	sr, err := NewSeries(time.Hour, "UTC", []string{"uri"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	n := seriesSamplesLimit * 10
+	for i := 0; i < n; i++ {
+		sr.Add(start.Add(time.Duration(i)*time.Millisecond), []string{"/a"}, 200, float64(i)/float64(n))
+	}
+
+	if len(sr.points) != 1 {
+		t.Fatalf("want 1 point, got: %d", len(sr.points))
+	}
+
+	p := sr.points[0]
+	if p.count != n {
+		t.Errorf("want count: %d, got: %d", n, p.count)
+	}
+	if len(p.samples) != seriesSamplesLimit {
+		t.Errorf("want %d samples, got: %d", seriesSamplesLimit, len(p.samples))
+	}
+
+	// the samples are uniform over the response times from 0 to 1
+	if p50 := p.pn(50); p50 < 0.4 || p50 > 0.6 {
+		t.Errorf("want p50 around 0.5, got: %v", p50)
+	}
+	if p99 := p.pn(99); p99 < 0.95 {
+		t.Errorf("want p99 around 0.99, got: %v", p99)
+	}
+}
+
+func TestSeriesPrintJSON(t *testing.T) {
+	sr, err := NewSeries(time.Minute, "UTC", []string{"uri", "method"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	sr.Add(start, []string{"/a", "GET"}, 500, 0.25)
+
+	var buf bytes.Buffer
+	if err := sr.Print(&buf, "json"); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []jsonSeriesPoint
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != 1 {
+		t.Fatalf("want 1 point, got: %d", len(got))
+	}
+
+	p := got[0]
+	if p.Time != "2024-01-01T00:00:00Z" || p.Labels["uri"] != "/a" || p.Labels["method"] != "GET" ||
+		p.Count != 1 || p.Errors != 1 || p.P50 != 0.25 || p.P99 != 0.25 {
+		t.Errorf("unexpected point: %+v", p)
+	}
+}
@@ -2,6 +2,7 @@ package stats
 
 import (
 	"fmt"
+	"regexp"
 	"sort"
 )
 
@@ -9,6 +10,8 @@ const (
 	SortCount                   = "Count"
 	SortUri                     = "Uri"
 	SortMethod                  = "Method"
+	SortService                 = "Service"
+	SortGroup                   = "Group"
 	SortMaxResponseTime         = "MaxResponseTime"
 	SortMinResponseTime         = "MinResponseTime"
 	SortSumResponseTime         = "SumResponseTime"
@@ -27,12 +30,28 @@ const (
 	SortAvgResponseBodyBytes    = "AvgResponseBodyBytes"
 	SortPNResponseBodyBytes     = "PNResponseBodyBytes"
 	SortStddevResponseBodyBytes = "StddevResponseBodyBytes"
+	SortMaxRequestTime          = "MaxRequestTime"
+	SortSumRequestTime          = "SumRequestTime"
+	SortAvgRequestTime          = "AvgRequestTime"
+	SortMaxOverhead             = "MaxOverhead"
+	SortSumOverhead             = "SumOverhead"
+	SortAvgOverhead             = "AvgOverhead"
+	SortAvgHandshakeRTT         = "AvgHandshakeRTT"
+	SortAvgFirstByteTime        = "AvgFirstByteTime"
+	SortAvgLastByteTime         = "AvgLastByteTime"
+	SortRetransmissions         = "Retransmissions"
+	SortReusedConnections       = "ReusedConnections"
+	SortErrorRate               = "ErrorRate"
 )
 
+var percentileKeyRe = regexp.MustCompile(`^p[0-9]+$`)
+
 type SortOptions struct {
 	options    map[string]string
 	sortType   string
 	percentile int
+	groupBy    []string
+	group      string
 }
 
 func NewSortOptions() *SortOptions {
@@ -44,12 +63,33 @@ func NewSortOptions() *SortOptions {
 		"count":    SortCount,
 		"uri":      SortUri,
 		"method":   SortMethod,
+		"service":  SortService,
 		"max-body": SortMaxResponseBodyBytes,
 		"min-body": SortMinResponseBodyBytes,
 		"avg-body": SortAvgResponseBodyBytes,
 		"sum-body": SortSumResponseBodyBytes,
 		"stddev":   SortStddevResponseTime,
 		"pn":       SortPNResponseTime,
+		// request body
+		"max-req-body": SortMaxRequestBodyBytes,
+		"min-req-body": SortMinRequestBodyBytes,
+		"avg-req-body": SortAvgRequestBodyBytes,
+		"sum-req-body": SortSumRequestBodyBytes,
+		// request time
+		"max-reqtime":  SortMaxRequestTime,
+		"sum-reqtime":  SortSumRequestTime,
+		"avg-reqtime":  SortAvgRequestTime,
+		"max-overhead": SortMaxOverhead,
+		"sum-overhead": SortSumOverhead,
+		"avg-overhead": SortAvgOverhead,
+		// pcap only
+		"handshake": SortAvgHandshakeRTT,
+		"ttfb":      SortAvgFirstByteTime,
+		"ttlb":      SortAvgLastByteTime,
+		"retrans":   SortRetransmissions,
+		"reused":    SortReusedConnections,
+		// status
+		"error-rate": SortErrorRate,
 	}
 
 	return &SortOptions{
@@ -57,6 +97,23 @@ func NewSortOptions() *SortOptions {
 	}
 }
 
+// SetGroupBy makes the group-by fields available as the sort keys,
+// the fields must not conflict with the output keywords and the sort keys
+func (so *SortOptions) SetGroupBy(fields []string) error {
+	keywords := headersMap(nil)
+	for _, field := range fields {
+		_, isKeyword := keywords[field]
+		_, isSortKey := so.options[field]
+		if isKeyword || isSortKey || field == "all" || percentileKeyRe.MatchString(field) {
+			return fmt.Errorf("group-by field conflicts with the keyword: %s", field)
+		}
+	}
+
+	so.groupBy = fields
+
+	return nil
+}
+
 func (so *SortOptions) SetAndValidate(opt string) error {
 	_, ok := so.options[opt]
 	if ok {
@@ -64,6 +121,14 @@ func (so *SortOptions) SetAndValidate(opt string) error {
 		return nil
 	}
 
+	for _, field := range so.groupBy {
+		if opt == field {
+			so.sortType = SortGroup
+			so.group = field
+			return nil
+		}
+	}
+
 	var n int
 	_, err := fmt.Sscanf(opt, "p%d", &n)
 	if err != nil {
@@ -88,6 +153,10 @@ func (so *SortOptions) Percentile() int {
 	return so.percentile
 }
 
+func (so *SortOptions) Group() string {
+	return so.group
+}
+
 func (hs *HTTPStats) Sort(sortOptions *SortOptions, reverse bool) {
 	switch sortOptions.sortType {
 	case SortCount:
@@ -96,6 +165,10 @@ func (hs *HTTPStats) Sort(sortOptions *SortOptions, reverse bool) {
 		hs.SortUri(reverse)
 	case SortMethod:
 		hs.SortMethod(reverse)
+	case SortService:
+		hs.SortService(reverse)
+	case SortGroup:
+		hs.SortGroup(sortOptions.group, reverse)
 	// response time
 	case SortMaxResponseTime:
 		hs.SortMaxResponseTime(reverse)
@@ -135,9 +208,38 @@ func (hs *HTTPStats) Sort(sortOptions *SortOptions, reverse bool) {
 		hs.SortPNResponseBodyBytes(reverse)
 	case SortStddevResponseBodyBytes:
 		hs.SortStddevResponseBodyBytes(reverse)
+	// request time
+	case SortMaxRequestTime:
+		hs.SortMaxRequestTime(reverse)
+	case SortSumRequestTime:
+		hs.SortSumRequestTime(reverse)
+	case SortAvgRequestTime:
+		hs.SortAvgRequestTime(reverse)
+	case SortMaxOverhead:
+		hs.SortMaxOverhead(reverse)
+	case SortSumOverhead:
+		hs.SortSumOverhead(reverse)
+	case SortAvgOverhead:
+		hs.SortAvgOverhead(reverse)
+	// tcp
+	case SortAvgHandshakeRTT:
+		hs.SortAvgHandshakeRTT(reverse)
+	case SortAvgFirstByteTime:
+		hs.SortAvgFirstByteTime(reverse)
+	case SortAvgLastByteTime:
+		hs.SortAvgLastByteTime(reverse)
+	case SortRetransmissions:
+		hs.SortRetransmissions(reverse)
+	case SortReusedConnections:
+		hs.SortReusedConnections(reverse)
+	// status
+	case SortErrorRate:
+		hs.SortErrorRate(reverse)
 	default:
 		hs.SortCount(reverse)
 	}
+
+	hs.reindex()
 }
 
 func (hs *HTTPStats) SortCount(reverse bool) {
@@ -164,6 +266,30 @@ func (hs *HTTPStats) SortUri(reverse bool) {
 	}
 }
 
+func (hs *HTTPStats) SortService(reverse bool) {
+	if reverse {
+		sort.Slice(hs.stats, func(i, j int) bool {
+			return hs.stats[i].Service > hs.stats[j].Service
+		})
+	} else {
+		sort.Slice(hs.stats, func(i, j int) bool {
+			return hs.stats[i].Service < hs.stats[j].Service
+		})
+	}
+}
+
+func (hs *HTTPStats) SortGroup(field string, reverse bool) {
+	if reverse {
+		sort.Slice(hs.stats, func(i, j int) bool {
+			return hs.stats[i].Groups[field] > hs.stats[j].Groups[field]
+		})
+	} else {
+		sort.Slice(hs.stats, func(i, j int) bool {
+			return hs.stats[i].Groups[field] < hs.stats[j].Groups[field]
+		})
+	}
+}
+
 func (hs *HTTPStats) SortMethod(reverse bool) {
 	if reverse {
 		sort.Slice(hs.stats, func(i, j int) bool {
@@ -393,3 +519,150 @@ func (hs *HTTPStats) SortStddevResponseBodyBytes(reverse bool) {
 		})
 	}
 }
+
+// request time
+func (hs *HTTPStats) SortMaxRequestTime(reverse bool) {
+	if reverse {
+		sort.Slice(hs.stats, func(i, j int) bool {
+			return hs.stats[i].MaxRequestTime() > hs.stats[j].MaxRequestTime()
+		})
+	} else {
+		sort.Slice(hs.stats, func(i, j int) bool {
+			return hs.stats[i].MaxRequestTime() < hs.stats[j].MaxRequestTime()
+		})
+	}
+}
+
+func (hs *HTTPStats) SortSumRequestTime(reverse bool) {
+	if reverse {
+		sort.Slice(hs.stats, func(i, j int) bool {
+			return hs.stats[i].SumRequestTime() > hs.stats[j].SumRequestTime()
+		})
+	} else {
+		sort.Slice(hs.stats, func(i, j int) bool {
+			return hs.stats[i].SumRequestTime() < hs.stats[j].SumRequestTime()
+		})
+	}
+}
+
+func (hs *HTTPStats) SortAvgRequestTime(reverse bool) {
+	if reverse {
+		sort.Slice(hs.stats, func(i, j int) bool {
+			return hs.stats[i].AvgRequestTime() > hs.stats[j].AvgRequestTime()
+		})
+	} else {
+		sort.Slice(hs.stats, func(i, j int) bool {
+			return hs.stats[i].AvgRequestTime() < hs.stats[j].AvgRequestTime()
+		})
+	}
+}
+
+func (hs *HTTPStats) SortMaxOverhead(reverse bool) {
+	if reverse {
+		sort.Slice(hs.stats, func(i, j int) bool {
+			return hs.stats[i].MaxOverhead() > hs.stats[j].MaxOverhead()
+		})
+	} else {
+		sort.Slice(hs.stats, func(i, j int) bool {
+			return hs.stats[i].MaxOverhead() < hs.stats[j].MaxOverhead()
+		})
+	}
+}
+
+func (hs *HTTPStats) SortSumOverhead(reverse bool) {
+	if reverse {
+		sort.Slice(hs.stats, func(i, j int) bool {
+			return hs.stats[i].SumOverhead() > hs.stats[j].SumOverhead()
+		})
+	} else {
+		sort.Slice(hs.stats, func(i, j int) bool {
+			return hs.stats[i].SumOverhead() < hs.stats[j].SumOverhead()
+		})
+	}
+}
+
+func (hs *HTTPStats) SortAvgOverhead(reverse bool) {
+	if reverse {
+		sort.Slice(hs.stats, func(i, j int) bool {
+			return hs.stats[i].AvgOverhead() > hs.stats[j].AvgOverhead()
+		})
+	} else {
+		sort.Slice(hs.stats, func(i, j int) bool {
+			return hs.stats[i].AvgOverhead() < hs.stats[j].AvgOverhead()
+		})
+	}
+}
+
+// status
+func (hs *HTTPStats) SortErrorRate(reverse bool) {
+	if reverse {
+		sort.Slice(hs.stats, func(i, j int) bool {
+			return hs.stats[i].ErrorRate() > hs.stats[j].ErrorRate()
+		})
+	} else {
+		sort.Slice(hs.stats, func(i, j int) bool {
+			return hs.stats[i].ErrorRate() < hs.stats[j].ErrorRate()
+		})
+	}
+}
+
+// tcp
+func (hs *HTTPStats) SortAvgHandshakeRTT(reverse bool) {
+	if reverse {
+		sort.Slice(hs.stats, func(i, j int) bool {
+			return hs.stats[i].AvgHandshakeRTT() > hs.stats[j].AvgHandshakeRTT()
+		})
+	} else {
+		sort.Slice(hs.stats, func(i, j int) bool {
+			return hs.stats[i].AvgHandshakeRTT() < hs.stats[j].AvgHandshakeRTT()
+		})
+	}
+}
+
+func (hs *HTTPStats) SortAvgFirstByteTime(reverse bool) {
+	if reverse {
+		sort.Slice(hs.stats, func(i, j int) bool {
+			return hs.stats[i].AvgFirstByteTime() > hs.stats[j].AvgFirstByteTime()
+		})
+	} else {
+		sort.Slice(hs.stats, func(i, j int) bool {
+			return hs.stats[i].AvgFirstByteTime() < hs.stats[j].AvgFirstByteTime()
+		})
+	}
+}
+
+func (hs *HTTPStats) SortAvgLastByteTime(reverse bool) {
+	if reverse {
+		sort.Slice(hs.stats, func(i, j int) bool {
+			return hs.stats[i].AvgLastByteTime() > hs.stats[j].AvgLastByteTime()
+		})
+	} else {
+		sort.Slice(hs.stats, func(i, j int) bool {
+			return hs.stats[i].AvgLastByteTime() < hs.stats[j].AvgLastByteTime()
+		})
+	}
+}
+
+func (hs *HTTPStats) SortRetransmissions(reverse bool) {
+	if reverse {
+		sort.Slice(hs.stats, func(i, j int) bool {
+			return hs.stats[i].Retransmissions() > hs.stats[j].Retransmissions()
+		})
+	} else {
+		sort.Slice(hs.stats, func(i, j int) bool {
+			return hs.stats[i].Retransmissions() < hs.stats[j].Retransmissions()
+		})
+	}
+}
+
+func (hs *HTTPStats) SortReusedConnections(reverse bool) {
+	if reverse {
+		sort.Slice(hs.stats, func(i, j int) bool {
+			return hs.stats[i].ReusedConnections() > hs.stats[j].ReusedConnections()
+		})
+	} else {
+		sort.Slice(hs.stats, func(i, j int) bool {
+			return hs.stats[i].ReusedConnections() < hs.stats[j].ReusedConnections()
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package stats
+
+import "testing"
+
+func TestSortOptionsSetGroupBy(t *testing.T) {
+	tests := []struct {
+		fields  []string
+		wantErr bool
+	}{
+		{fields: []string{"host", "upstream_addr"}},
+		{fields: []string{"status"}},
+		{fields: []string{"host", "uri"}, wantErr: true},
+		{fields: []string{"count"}, wantErr: true},
+		{fields: []string{"avg_body"}, wantErr: true},
+		{fields: []string{"max-body"}, wantErr: true},
+		{fields: []string{"p99"}, wantErr: true},
+		{fields: []string{"all"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		so := NewSortOptions()
+		err := so.SetGroupBy(tt.fields)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("SetGroupBy(%v): want error: %v, got: %v", tt.fields, tt.wantErr, err)
+		}
+	}
+
+	so := NewSortOptions()
+	if err := so.SetGroupBy([]string{"host"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := so.SetAndValidate("host"); err != nil || so.SortType() != SortGroup {
+		t.Errorf("want the sort by the group, got: %s, %v", so.SortType(), err)
+	}
+}
+
+func TestHTTPStatsSortErrorRate(t *testing.T) {
+	hs := NewHTTPStats(true, false, false)
+	hs.Set("/foo", "GET", 200, 0.1, 10, 0)
+	hs.Set("/foo", "GET", 500, 0.1, 10, 0)
+	hs.Set("/bar", "GET", 404, 0.1, 10, 0)
+	hs.Set("/baz", "GET", 200, 0.1, 10, 0)
+	hs.Set("/baz", "GET", 200, 0.1, 10, 0)
+	hs.Set("/baz", "GET", 200, 0.1, 10, 0)
+
+	so := NewSortOptions()
+	if err := so.SetAndValidate("error-rate"); err != nil {
+		t.Fatal(err)
+	}
+	hs.Sort(so, false)
+
+	want := []string{"/baz", "/foo", "/bar"}
+	for i, s := range hs.stats {
+		if s.Uri != want[i] {
+			t.Errorf("want: %v, got: %s at %d", want, s.Uri, i)
+		}
+	}
+}
+
+func TestHTTPStatsSetAfterSort(t *testing.T) {
+	hs := NewHTTPStats(true, false, false)
+	hs.SetGroupBy([]string{"host"})
+	hs.SetWithService("", "/foo", "GET", []string{"a.example.com"}, 200, 0.1, 10, 0)
+	hs.SetWithService("", "/bar", "GET", []string{"a.example.com"}, 200, 0.1, 10, 0)
+	hs.SetWithService("", "/bar", "GET", []string{"a.example.com"}, 200, 0.1, 10, 0)
+
+	so := NewSortOptions()
+	if err := so.SetAndValidate("count"); err != nil {
+		t.Fatal(err)
+	}
+	hs.Sort(so, true)
+
+	// the requests are set to the same endpoints after the sort
+	hs.SetWithService("", "/foo", "GET", []string{"a.example.com"}, 200, 0.1, 10, 0)
+	hs.SetWithService("", "/foo", "GET", []string{"b.example.com"}, 200, 0.1, 10, 0)
+
+	want := map[string]int{"/bar a.example.com": 2, "/foo a.example.com": 2, "/foo b.example.com": 1}
+	if len(hs.stats) != len(want) {
+		t.Fatalf("want %d stats, got: %d", len(want), len(hs.stats))
+	}
+	for _, s := range hs.stats {
+		key := s.Uri + " " + s.Groups["host"]
+		if s.Cnt != want[key] {
+			t.Errorf("%s: want count: %d, got: %d", key, want[key], s.Cnt)
+		}
+	}
+}
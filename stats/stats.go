@@ -2,11 +2,13 @@ package stats
 
 import (
 	"fmt"
+	"io"
 	"math"
 	"net/url"
 	"regexp"
 	"sort"
 	"sync"
+	"time"
 
 	"github.com/tetsuzawa/alp-trace/errors"
 	"github.com/tetsuzawa/alp-trace/helpers"
@@ -49,6 +51,9 @@ type HTTPStats struct {
 	options                        *options.Options
 	sortOptions                    *SortOptions
 	uriMatchingGroups              []*regexp.Regexp
+	groupBy                        []string
+	series                         *Series
+	histogramBounds                []float64
 }
 
 func NewHTTPStats(useResTimePercentile, useRequestBodyBytesPercentile, useResponseBodyBytesPercentile bool) *HTTPStats {
@@ -57,30 +62,94 @@ func NewHTTPStats(useResTimePercentile, useRequestBodyBytesPercentile, useRespon
 		stats:                          make([]*HTTPStat, 0),
 		traceStats:                     NewTraceStats(useResTimePercentile, useRequestBodyBytesPercentile, useResponseBodyBytesPercentile),
 		useResponseTimePercentile:      useResTimePercentile,
+		useRequestBodyBytesPercentile:  useRequestBodyBytesPercentile,
 		useResponseBodyBytesPercentile: useResponseBodyBytesPercentile,
 	}
 }
 
 func (hs *HTTPStats) Set(uri, method string, status int, restime, resBodyBytes, reqBodyBytes float64) {
-	if len(hs.uriMatchingGroups) > 0 {
-		for _, re := range hs.uriMatchingGroups {
-			if ok := re.Match([]byte(uri)); ok {
-				pattern := re.String()
-				uri = pattern
-				break
-			}
+	hs.SetWithService("", uri, method, nil, status, restime, resBodyBytes, reqBodyBytes)
+}
+
+// SetWithService aggregates the endpoints of each service and group separately
+func (hs *HTTPStats) SetWithService(service, uri, method string, groups []string, status int, restime, resBodyBytes, reqBodyBytes float64) {
+	hs.lookup(service, uri, method, groups).Set(status, restime, reqBodyBytes, resBodyBytes)
+}
+
+func (hs *HTTPStats) SetTCPMetrics(service, uri, method string, groups []string, m *parsers.TCPMetrics) {
+	s := hs.lookup(service, uri, method, groups)
+	if s.TCP == nil {
+		s.TCP = newTCPStat(hs.useResponseTimePercentile)
+	}
+
+	s.TCP.Set(m)
+}
+
+func (hs *HTTPStats) SetTimeBreakdown(service, uri, method string, groups []string, b *parsers.TimeBreakdown) {
+	s := hs.lookup(service, uri, method, groups)
+	if s.RequestTime == nil {
+		s.RequestTime = newRequestTimeStat(hs.useResponseTimePercentile)
+	}
+
+	s.RequestTime.Set(b)
+}
+
+func (hs *HTTPStats) matchingGroup(uri string) string {
+	for _, re := range hs.uriMatchingGroups {
+		if ok := re.Match([]byte(uri)); ok {
+			return re.String()
 		}
 	}
 
+	return uri
+}
+
+func httpStatKey(service, uri, method string, groups []string) string {
 	key := fmt.Sprintf("%s_%s", method, uri)
+	if service != "" {
+		key = fmt.Sprintf("%s_%s", service, key)
+	}
+	// the values of the log entries may contain any characters
+	for _, group := range groups {
+		key = fmt.Sprintf("%s\x00%s", key, group)
+	}
 
-	idx := hs.hints.loadOrStore(key)
+	return key
+}
+
+func (hs *HTTPStats) lookup(service, uri, method string, groups []string) *HTTPStat {
+	uri = hs.matchingGroup(uri)
+
+	idx := hs.hints.loadOrStore(httpStatKey(service, uri, method, groups))
 
 	if idx >= len(hs.stats) {
-		hs.stats = append(hs.stats, newHTTPStat(uri, method, hs.useResponseTimePercentile, hs.useRequestBodyBytesPercentile, hs.useResponseBodyBytesPercentile))
+		s := newHTTPStat(uri, method, hs.useResponseTimePercentile, hs.useRequestBodyBytesPercentile, hs.useResponseBodyBytesPercentile)
+		s.Service = service
+		if hs.histogramBounds != nil {
+			s.histogram = newHistogramCounts(hs.histogramBounds)
+		}
+		if len(groups) > 0 {
+			s.Groups = make(map[string]string, len(groups))
+			for i, group := range groups {
+				s.Groups[hs.groupBy[i]] = group
+			}
+		}
+		hs.stats = append(hs.stats, s)
 	}
 
-	hs.stats[idx].Set(status, restime, resBodyBytes, reqBodyBytes)
+	return hs.stats[idx]
+}
+
+// reindex points the hints to the sorted stats, so that the requests can be set after the sort
+func (hs *HTTPStats) reindex() {
+	hs.hints = newHints()
+	for _, s := range hs.stats {
+		groups := make([]string, len(hs.groupBy))
+		for i, field := range hs.groupBy {
+			groups[i] = s.Groups[field]
+		}
+		hs.hints.loadOrStore(httpStatKey(s.Service, s.Uri, s.Method, groups))
+	}
 }
 
 func (hs *HTTPStats) Stats() []*HTTPStat {
@@ -110,6 +179,109 @@ func (hs *HTTPStats) SetURIMatchingGroups(groups []string) error {
 	return nil
 }
 
+// SetGroupBy adds the log entries to the dimensions of the stats
+func (hs *HTTPStats) SetGroupBy(fields []string) {
+	hs.groupBy = fields
+}
+
+// GroupBy returns the fields of the loaded stats if SetGroupBy is not called
+func (hs *HTTPStats) GroupBy() []string {
+	if len(hs.groupBy) > 0 {
+		return hs.groupBy
+	}
+
+	return groupFields(hs.stats)
+}
+
+// groupFields returns the sorted group-by fields of the stats
+func groupFields(stats []*HTTPStat) []string {
+	fields := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, s := range stats {
+		for field := range s.Groups {
+			if !seen[field] {
+				seen[field] = true
+				fields = append(fields, field)
+			}
+		}
+	}
+	sort.Strings(fields)
+
+	return fields
+}
+
+// Groups returns the values of the group-by fields in the log entries
+func (hs *HTTPStats) Groups(entries parsers.LogEntries) []string {
+	if len(hs.groupBy) == 0 {
+		return nil
+	}
+
+	groups := make([]string, len(hs.groupBy))
+	for i, field := range hs.groupBy {
+		groups[i] = entries[field]
+	}
+
+	return groups
+}
+
+// SetBucket enables the time series of the endpoints, call it after SetGroupBy
+func (hs *HTTPStats) SetBucket(bucket time.Duration, location string) error {
+	labels := append([]string{"method", "uri"}, hs.groupBy...)
+	series, err := NewSeries(bucket, location, labels)
+	if err != nil {
+		return err
+	}
+
+	hs.series = series
+
+	return nil
+}
+
+func (hs *HTTPStats) AppendSeries(timestr, uri, method string, groups []string, status int, restime float64) error {
+	t, err := hs.series.parse(timestr)
+	if err != nil {
+		return err
+	}
+
+	labels := append([]string{method, hs.matchingGroup(uri)}, groups...)
+	hs.series.Add(t, labels, status, restime)
+
+	return nil
+}
+
+func (hs *HTTPStats) PrintSeries(w io.Writer, format string) error {
+	return hs.series.Print(w, format)
+}
+
+// SetHistogram counts the response times of the endpoints into the buckets of the bounds
+func (hs *HTTPStats) SetHistogram(bounds []float64) {
+	hs.histogramBounds = bounds
+}
+
+// Histogram returns the counted buckets of each endpoint, call it after the sort
+func (hs *HTTPStats) Histogram() (*Histogram, error) {
+	groupBy := hs.GroupBy()
+	h := NewHistogram(hs.histogramBounds, append([]string{"method", "uri"}, groupBy...))
+	for _, s := range hs.stats {
+		labels := []string{s.Method, s.Uri}
+		for _, field := range groupBy {
+			labels = append(labels, s.Groups[field])
+		}
+
+		counts := s.histogram
+		if counts == nil {
+			var err error
+			counts, err = countResponseTimes(hs.histogramBounds, s.Cnt, s.ResponseTime)
+			if err != nil {
+				return nil, err
+			}
+		}
+		h.Add(labels, counts)
+	}
+
+	return h, nil
+}
+
 func (hs *HTTPStats) InitFilter(options *options.Options) error {
 	hs.filter = NewFilter(options)
 	return hs.filter.Init()
@@ -141,23 +313,68 @@ func (hs *HTTPStats) CountAll() map[string]int {
 	return counts
 }
 
+func (hs *HTTPStats) HasRequestBodyBytes() bool {
+	for _, s := range hs.stats {
+		if s.RequestBodyBytes.Sum > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (hs *HTTPStats) HasServices() bool {
+	for _, s := range hs.stats {
+		if s.Service != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (hs *HTTPStats) HasRequestTime() bool {
+	for _, s := range hs.stats {
+		if s.RequestTime != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (hs *HTTPStats) HasTCPMetrics() bool {
+	for _, s := range hs.stats {
+		if s.TCP != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (hs *HTTPStats) SortWithOptions() {
 	hs.Sort(hs.sortOptions, hs.options.Reverse)
 }
 
 type HTTPStat struct {
-	Uri               string        `yaml:"uri"`
-	Cnt               int           `yaml:"count"`
-	Status1xx         int           `yaml:"status1xx"`
-	Status2xx         int           `yaml:"status2xx"`
-	Status3xx         int           `yaml:"status3xx"`
-	Status4xx         int           `yaml:"status4xx"`
-	Status5xx         int           `yaml:"status5xx"`
-	Method            string        `yaml:"method"`
-	ResponseTime      *responseTime `yaml:"response_time"`
-	RequestBodyBytes  *bodyBytes    `yaml:"request_body_bytes"`
-	ResponseBodyBytes *bodyBytes    `yaml:"response_body_bytes"`
+	Uri               string            `yaml:"uri"`
+	Cnt               int               `yaml:"count"`
+	Status1xx         int               `yaml:"status1xx"`
+	Status2xx         int               `yaml:"status2xx"`
+	Status3xx         int               `yaml:"status3xx"`
+	Status4xx         int               `yaml:"status4xx"`
+	Status5xx         int               `yaml:"status5xx"`
+	Method            string            `yaml:"method"`
+	Service           string            `yaml:"service,omitempty"`
+	Groups            map[string]string `yaml:"groups,omitempty"`
+	ResponseTime      *responseTime     `yaml:"response_time"`
+	RequestBodyBytes  *bodyBytes        `yaml:"request_body_bytes"`
+	ResponseBodyBytes *bodyBytes        `yaml:"response_body_bytes"`
+	TCP               *tcpStat          `yaml:"tcp,omitempty"`
+	RequestTime       *requestTimeStat  `yaml:"request_time,omitempty"`
 	Time              string
+	histogram         *histogramCounts
 }
 
 type httpStats []*HTTPStat
@@ -178,6 +395,9 @@ func (hs *HTTPStat) Set(status int, restime, reqBodyBytes, resBodyBytes float64)
 	hs.ResponseTime.Set(restime)
 	hs.RequestBodyBytes.Set(reqBodyBytes)
 	hs.ResponseBodyBytes.Set(resBodyBytes)
+	if hs.histogram != nil {
+		hs.histogram.add(restime)
+	}
 }
 
 func (hs *HTTPStat) setStatus(status int) {
@@ -294,27 +514,114 @@ func (hs *HTTPStat) StddevRequestBodyBytes() float64 {
 
 // response
 func (hs *HTTPStat) MaxResponseBodyBytes() float64 {
-	return hs.RequestBodyBytes.Max
+	return hs.ResponseBodyBytes.Max
 }
 
 func (hs *HTTPStat) MinResponseBodyBytes() float64 {
-	return hs.RequestBodyBytes.Min
+	return hs.ResponseBodyBytes.Min
 }
 
 func (hs *HTTPStat) SumResponseBodyBytes() float64 {
-	return hs.RequestBodyBytes.Sum
+	return hs.ResponseBodyBytes.Sum
 }
 
 func (hs *HTTPStat) AvgResponseBodyBytes() float64 {
-	return hs.RequestBodyBytes.Avg(hs.Cnt)
+	return hs.ResponseBodyBytes.Avg(hs.Cnt)
 }
 
 func (hs *HTTPStat) PNResponseBodyBytes(n int) float64 {
-	return hs.RequestBodyBytes.PN(hs.Cnt, n)
+	return hs.ResponseBodyBytes.PN(hs.Cnt, n)
 }
 
 func (hs *HTTPStat) StddevResponseBodyBytes() float64 {
-	return hs.RequestBodyBytes.Stddev(hs.Cnt)
+	return hs.ResponseBodyBytes.Stddev(hs.Cnt)
+}
+
+// ErrorRate is the percentage of the 4xx and 5xx responses
+func (hs *HTTPStat) ErrorRate() float64 {
+	if hs.Cnt == 0 {
+		return 0
+	}
+	return float64(hs.Status4xx+hs.Status5xx) / float64(hs.Cnt) * 100
+}
+
+// request time, only available if the log has both the upstream time and the request time
+func (hs *HTTPStat) MaxRequestTime() float64 {
+	if hs.RequestTime == nil {
+		return 0
+	}
+	return hs.RequestTime.RequestTime.Max
+}
+
+func (hs *HTTPStat) SumRequestTime() float64 {
+	if hs.RequestTime == nil {
+		return 0
+	}
+	return hs.RequestTime.RequestTime.Sum
+}
+
+func (hs *HTTPStat) AvgRequestTime() float64 {
+	if hs.RequestTime == nil {
+		return 0
+	}
+	return hs.RequestTime.RequestTime.Avg(hs.RequestTime.Cnt)
+}
+
+func (hs *HTTPStat) MaxOverhead() float64 {
+	if hs.RequestTime == nil {
+		return 0
+	}
+	return hs.RequestTime.Overhead.Max
+}
+
+func (hs *HTTPStat) SumOverhead() float64 {
+	if hs.RequestTime == nil {
+		return 0
+	}
+	return hs.RequestTime.Overhead.Sum
+}
+
+func (hs *HTTPStat) AvgOverhead() float64 {
+	if hs.RequestTime == nil {
+		return 0
+	}
+	return hs.RequestTime.Overhead.Avg(hs.RequestTime.Cnt)
+}
+
+// tcp
+func (hs *HTTPStat) AvgHandshakeRTT() float64 {
+	if hs.TCP == nil || hs.TCP.Handshakes == 0 {
+		return 0
+	}
+	return hs.TCP.HandshakeRTT.Avg(hs.TCP.Handshakes)
+}
+
+func (hs *HTTPStat) AvgFirstByteTime() float64 {
+	if hs.TCP == nil {
+		return 0
+	}
+	return hs.TCP.FirstByteTime.Avg(hs.TCP.Cnt)
+}
+
+func (hs *HTTPStat) AvgLastByteTime() float64 {
+	if hs.TCP == nil {
+		return 0
+	}
+	return hs.TCP.LastByteTime.Avg(hs.TCP.Cnt)
+}
+
+func (hs *HTTPStat) Retransmissions() int {
+	if hs.TCP == nil {
+		return 0
+	}
+	return hs.TCP.Retransmissions
+}
+
+func (hs *HTTPStat) ReusedConnections() int {
+	if hs.TCP == nil {
+		return 0
+	}
+	return hs.TCP.Reused
 }
 
 func percentRank(n int, pi int) int {
@@ -467,3 +774,62 @@ func (body *bodyBytes) Sort() {
 		return body.Percentiles[i] < body.Percentiles[j]
 	})
 }
+
+type tcpStat struct {
+	Cnt             int           `yaml:"count"`
+	Handshakes      int           `yaml:"handshakes"`
+	HandshakeRTT    *responseTime `yaml:"handshake_rtt"`
+	FirstByteTime   *responseTime `yaml:"first_byte_time"`
+	LastByteTime    *responseTime `yaml:"last_byte_time"`
+	Retransmissions int           `yaml:"retransmissions"`
+	Reused          int           `yaml:"reused"`
+}
+
+func newTCPStat(usePercentile bool) *tcpStat {
+	return &tcpStat{
+		HandshakeRTT:  newResponseTime(usePercentile),
+		FirstByteTime: newResponseTime(usePercentile),
+		LastByteTime:  newResponseTime(usePercentile),
+	}
+}
+
+func (ts *tcpStat) Set(m *parsers.TCPMetrics) {
+	ts.Cnt++
+	ts.FirstByteTime.Set(m.FirstByteTime)
+	ts.LastByteTime.Set(m.LastByteTime)
+	ts.Retransmissions += m.Retransmissions
+
+	if m.Reused {
+		ts.Reused++
+	} else if m.HandshakeRTT > 0 {
+		// the handshake is not captured if the connection was opened before the capture started
+		ts.Handshakes++
+		ts.HandshakeRTT.Set(m.HandshakeRTT)
+	}
+}
+
+// requestTimeStat is the total request time and the overhead of the proxy and the client transfer,
+// the request time minus the upstream time
+type requestTimeStat struct {
+	Cnt         int           `yaml:"count"`
+	RequestTime *responseTime `yaml:"request_time"`
+	Overhead    *responseTime `yaml:"overhead"`
+}
+
+func newRequestTimeStat(usePercentile bool) *requestTimeStat {
+	return &requestTimeStat{
+		RequestTime: newResponseTime(usePercentile),
+		Overhead:    newResponseTime(usePercentile),
+	}
+}
+
+func (rs *requestTimeStat) Set(b *parsers.TimeBreakdown) {
+	rs.Cnt++
+	rs.RequestTime.Set(b.RequestTime)
+	rs.Overhead.Set(overhead(b))
+}
+
+// overhead is not negative even if the times are rounded differently
+func overhead(b *parsers.TimeBreakdown) float64 {
+	return math.Max(b.RequestTime-b.UpstreamTime, 0)
+}
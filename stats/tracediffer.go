@@ -23,6 +23,60 @@ func (d *TraceDiffer) DiffCnt() string {
 	return fmt.Sprintf("%d", v)
 }
 
+func (d *TraceDiffer) DiffStatus1xx() string {
+	v := d.To.Status1xx - d.From.Status1xx
+	if v >= 0 {
+		return fmt.Sprintf("+%d", v)
+	}
+
+	return fmt.Sprintf("%d", v)
+}
+
+func (d *TraceDiffer) DiffStatus2xx() string {
+	v := d.To.Status2xx - d.From.Status2xx
+	if v >= 0 {
+		return fmt.Sprintf("+%d", v)
+	}
+
+	return fmt.Sprintf("%d", v)
+}
+
+func (d *TraceDiffer) DiffStatus3xx() string {
+	v := d.To.Status3xx - d.From.Status3xx
+	if v >= 0 {
+		return fmt.Sprintf("+%d", v)
+	}
+
+	return fmt.Sprintf("%d", v)
+}
+
+func (d *TraceDiffer) DiffStatus4xx() string {
+	v := d.To.Status4xx - d.From.Status4xx
+	if v >= 0 {
+		return fmt.Sprintf("+%d", v)
+	}
+
+	return fmt.Sprintf("%d", v)
+}
+
+func (d *TraceDiffer) DiffStatus5xx() string {
+	v := d.To.Status5xx - d.From.Status5xx
+	if v >= 0 {
+		return fmt.Sprintf("+%d", v)
+	}
+
+	return fmt.Sprintf("%d", v)
+}
+
+func (d *TraceDiffer) DiffErrorRate() string {
+	v := d.To.ErrorRate() - d.From.ErrorRate()
+	if v >= 0 {
+		return fmt.Sprintf("+%.3f", v)
+	}
+
+	return fmt.Sprintf("%.3f", v)
+}
+
 func (d *TraceDiffer) DiffMaxResponseTime() string {
 	v := d.To.MaxResponseTime() - d.From.MaxResponseTime()
 	if v >= 0 {
@@ -77,6 +131,25 @@ func (d *TraceDiffer) DiffStddevResponseTime() string {
 	return fmt.Sprintf("%.3f", v)
 }
 
+// request time
+func (d *TraceDiffer) DiffAvgRequestTime() string {
+	v := d.To.AvgRequestTime() - d.From.AvgRequestTime()
+	if v >= 0 {
+		return fmt.Sprintf("+%.3f", v)
+	}
+
+	return fmt.Sprintf("%.3f", v)
+}
+
+func (d *TraceDiffer) DiffAvgOverhead() string {
+	v := d.To.AvgOverhead() - d.From.AvgOverhead()
+	if v >= 0 {
+		return fmt.Sprintf("+%.3f", v)
+	}
+
+	return fmt.Sprintf("%.3f", v)
+}
+
 // request
 func (d *TraceDiffer) DiffMaxRequestBodyBytes() string {
 	v := d.To.MaxRequestBodyBytes() - d.From.MaxRequestBodyBytes()
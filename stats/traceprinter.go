@@ -11,8 +11,12 @@ import (
 	"github.com/olekukonko/tablewriter"
 	"github.com/tetsuzawa/alp-trace/helpers"
 	"github.com/tetsuzawa/alp-trace/html"
+	"github.com/tkuchiki/parsetime"
 )
 
+// exemplarTraceIDsLimit is the number of the trace IDs shown in the html report of a scenario
+const exemplarTraceIDsLimit = 10
+
 func traceKeywords(percentiles []int) []string {
 	s1 := []string{
 		"count",
@@ -99,6 +103,21 @@ func traceHeadersMap(percentiles []int) map[string]string {
 		"sum_body":          "Sum(Body)",
 		"avg_body":          "Avg(Body)",
 		"trace_id_sample":   "TraceIdSample",
+		// request body
+		"min_req_body": "Min(ReqBody)",
+		"max_req_body": "Max(ReqBody)",
+		"sum_req_body": "Sum(ReqBody)",
+		"avg_req_body": "Avg(ReqBody)",
+		// request time
+		"avg_reqtime":  "Avg(ReqTime)",
+		"avg_overhead": "Avg(Overhead)",
+		// status
+		"1xx":        "1xx",
+		"2xx":        "2xx",
+		"3xx":        "3xx",
+		"4xx":        "4xx",
+		"5xx":        "5xx",
+		"error_rate": "ErrorRate",
 	}
 
 	for _, p := range percentiles {
@@ -135,15 +154,21 @@ type TracePrinter struct {
 	headersMap   map[string]string
 	writer       io.Writer
 	all          bool
+	traceIDs     []string
+	parseTime    parsetime.ParseTime
 }
 
 func NewTracePrinter(w io.Writer, val, format string, percentiles []int, printOptions *TracePrintOptions) *TracePrinter {
+	// the local timezone does not fail
+	pt, _ := parsetime.NewParseTime()
+
 	p := &TracePrinter{
 		format:       format,
 		percentiles:  percentiles,
 		headersMap:   traceHeadersMap(percentiles),
 		writer:       w,
 		printOptions: printOptions,
+		parseTime:    pt,
 	}
 
 	if val == "all" {
@@ -193,6 +218,18 @@ func (p *TracePrinter) GenerateTraceLine(s *ScenarioStat, quoteUri bool) []strin
 		switch p.keywords[i] {
 		case "count":
 			line = append(line, s.StrCount())
+		case "1xx":
+			line = append(line, fmt.Sprint(s.Status1xx))
+		case "2xx":
+			line = append(line, fmt.Sprint(s.Status2xx))
+		case "3xx":
+			line = append(line, fmt.Sprint(s.Status3xx))
+		case "4xx":
+			line = append(line, fmt.Sprint(s.Status4xx))
+		case "5xx":
+			line = append(line, fmt.Sprint(s.Status5xx))
+		case "error_rate":
+			line = append(line, round(s.ErrorRate()))
 		case "uri_method_status":
 			uriMethodStatus := s.UriWithOptions(p.printOptions.decodeUri)
 			if quoteUri && strings.Contains(s.TraceUriMethodStatus, ",") {
@@ -217,6 +254,18 @@ func (p *TracePrinter) GenerateTraceLine(s *ScenarioStat, quoteUri bool) []strin
 			line = append(line, round(s.SumResponseBodyBytes()))
 		case "avg_body":
 			line = append(line, round(s.AvgResponseBodyBytes()))
+		case "min_req_body":
+			line = append(line, round(s.MinRequestBodyBytes()))
+		case "max_req_body":
+			line = append(line, round(s.MaxRequestBodyBytes()))
+		case "sum_req_body":
+			line = append(line, round(s.SumRequestBodyBytes()))
+		case "avg_req_body":
+			line = append(line, round(s.AvgRequestBodyBytes()))
+		case "avg_reqtime":
+			line = append(line, round(s.AvgRequestTime()))
+		case "avg_overhead":
+			line = append(line, round(s.AvgOverhead()))
 		case "trace_id_sample":
 			traceIDSample := s.RandomTraceID()
 			line = append(line, traceIDSample)
@@ -243,6 +292,18 @@ func (p *TracePrinter) GenerateTraceLineWithDiff(from, to *ScenarioStat, quoteUr
 		switch p.keywords[i] {
 		case "count":
 			line = append(line, formattedLineWithDiff(to.StrCount(), differ.DiffCnt()))
+		case "1xx":
+			line = append(line, formattedLineWithDiff(fmt.Sprint(to.Status1xx), differ.DiffStatus1xx()))
+		case "2xx":
+			line = append(line, formattedLineWithDiff(fmt.Sprint(to.Status2xx), differ.DiffStatus2xx()))
+		case "3xx":
+			line = append(line, formattedLineWithDiff(fmt.Sprint(to.Status3xx), differ.DiffStatus3xx()))
+		case "4xx":
+			line = append(line, formattedLineWithDiff(fmt.Sprint(to.Status4xx), differ.DiffStatus4xx()))
+		case "5xx":
+			line = append(line, formattedLineWithDiff(fmt.Sprint(to.Status5xx), differ.DiffStatus5xx()))
+		case "error_rate":
+			line = append(line, formattedLineWithDiff(round(to.ErrorRate()), differ.DiffErrorRate()))
 		case "uri_method_status":
 			uriMethodStatus := to.UriWithOptions(p.printOptions.decodeUri)
 			if quoteUri && strings.Contains(to.TraceUriMethodStatus, ",") {
@@ -267,6 +328,18 @@ func (p *TracePrinter) GenerateTraceLineWithDiff(from, to *ScenarioStat, quoteUr
 			line = append(line, formattedLineWithDiff(round(to.SumResponseBodyBytes()), differ.DiffSumResponseBodyBytes()))
 		case "avg_body":
 			line = append(line, formattedLineWithDiff(round(to.AvgResponseBodyBytes()), differ.DiffAvgResponseBodyBytes()))
+		case "min_req_body":
+			line = append(line, formattedLineWithDiff(round(to.MinRequestBodyBytes()), differ.DiffMinRequestBodyBytes()))
+		case "max_req_body":
+			line = append(line, formattedLineWithDiff(round(to.MaxRequestBodyBytes()), differ.DiffMaxRequestBodyBytes()))
+		case "sum_req_body":
+			line = append(line, formattedLineWithDiff(round(to.SumRequestBodyBytes()), differ.DiffSumRequestBodyBytes()))
+		case "avg_req_body":
+			line = append(line, formattedLineWithDiff(round(to.AvgRequestBodyBytes()), differ.DiffAvgRequestBodyBytes()))
+		case "avg_reqtime":
+			line = append(line, formattedLineWithDiff(round(to.AvgRequestTime()), differ.DiffAvgRequestTime()))
+		case "avg_overhead":
+			line = append(line, formattedLineWithDiff(round(to.AvgOverhead()), differ.DiffAvgOverhead()))
 		default: // percentile
 			var n int
 			_, err := fmt.Sscanf(p.keywords[i], "p%d", &n)
@@ -325,7 +398,40 @@ func (p *TracePrinter) SetWriter(w io.Writer) {
 	p.writer = w
 }
 
-func (p *TracePrinter) Print(ts, tsTo *TraceStats) {
+// showReqBodyColumns adds the request body columns to the default output only if the stats have the values
+func (p *TracePrinter) showReqBodyColumns(ts *TraceStats) {
+	if !p.all || !ts.HasRequestBodyBytes() {
+		return
+	}
+
+	keywords := traceKeywords(p.percentiles)
+	headers := traceDefaultHeaders(p.percentiles)
+	// before trace_id_sample
+	n := len(keywords) - 1
+	p.keywords = append(append(keywords[:n:n], reqBodyKeywords()...), keywords[n:]...)
+	p.headers = append(append(headers[:n:n], reqBodyHeaders()...), headers[n:]...)
+}
+
+// SetTraceIDs selects the traces exported in the chrome format instead of the exemplars
+func (p *TracePrinter) SetTraceIDs(traceIDs []string) {
+	p.traceIDs = traceIDs
+}
+
+// SetLocation sets the timezone of the log times exported in the chrome format
+func (p *TracePrinter) SetLocation(location string) error {
+	pt, err := parsetime.NewParseTime(location)
+	if err != nil {
+		return err
+	}
+
+	p.parseTime = pt
+
+	return nil
+}
+
+func (p *TracePrinter) Print(ts, tsTo *TraceStats) error {
+	p.showReqBodyColumns(ts)
+
 	switch p.format {
 	case "pretty":
 		p.printTracePretty(ts, tsTo)
@@ -338,8 +444,26 @@ func (p *TracePrinter) Print(ts, tsTo *TraceStats) {
 	case "csv":
 		p.printTraceCSV(ts, tsTo)
 	case "html":
-		p.printTraceHTML(ts, tsTo)
+		return p.printTraceHTML(ts, tsTo)
+	case "openmetrics":
+		p.printTraceOpenMetrics(ts, tsTo)
+	case "json":
+		return p.printTraceJSON(ts, tsTo, false)
+	case "ndjson":
+		return p.printTraceJSON(ts, tsTo, true)
+	case "mermaid":
+		p.printTraceMermaid(ts, tsTo)
+	case "dot":
+		p.printTraceDot(ts, tsTo)
+	case "folded":
+		p.printTraceFolded(ts, tsTo)
+	case "pprof":
+		return p.printTracePProf(ts, tsTo)
+	case "chrome":
+		return p.printTraceChrome(ts, tsTo)
 	}
+
+	return nil
 }
 
 //func round(num float64) string {
@@ -626,26 +750,106 @@ func (p *TracePrinter) printTraceCSV(tsFrom, tsTo *TraceStats) {
 	}
 }
 
-func (p *TracePrinter) printTraceHTML(tsFrom, tsTo *TraceStats) {
+func (p *TracePrinter) printTraceHTML(tsFrom, tsTo *TraceStats) error {
 	var data [][]string
+	var details []*html.Detail
 
 	if tsTo == nil {
 		for _, s := range tsFrom.ScenarioStats {
-			data = append(data, p.GenerateTraceLine(s, true))
+			data = append(data, append([]string{s.ID}, p.GenerateTraceLine(s, false)...))
+			details = append(details, p.scenarioDetail(tsFrom, s))
 		}
 	} else {
 		for _, to := range tsTo.ScenarioStats {
 			from := findTraceStatFrom(tsFrom, to)
 
 			if from == nil {
-				data = append(data, p.GenerateTraceLine(to, false))
+				data = append(data, append([]string{to.ID}, p.GenerateTraceLine(to, false)...))
 			} else {
-				data = append(data, p.GenerateTraceLineWithDiff(from, to, false))
+				data = append(data, append([]string{to.ID}, p.GenerateTraceLineWithDiff(from, to, false)...))
 			}
+			details = append(details, p.scenarioDetail(tsTo, to))
+		}
+	}
+
+	headers := append([]string{"Scenario ID"}, p.headers...)
+	content, err := html.RenderTableWithDetails("alp-trace", headers, data, details, p.printOptions.paginationLimit)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(p.writer, content)
+	return err
+}
+
+// Table returns the headers, the lines and the details of the scenarios, as the interactive formats render the table by themselves
+func (p *TracePrinter) Table(ts *TraceStats) ([]string, [][]string, []*html.Detail) {
+	p.showReqBodyColumns(ts)
+
+	data := make([][]string, 0, len(ts.ScenarioStats))
+	details := make([]*html.Detail, 0, len(ts.ScenarioStats))
+	for _, s := range ts.ScenarioStats {
+		data = append(data, append([]string{s.ID}, p.GenerateTraceLine(s, false)...))
+		details = append(details, p.scenarioDetail(ts, s))
+	}
+
+	return append([]string{"Scenario ID"}, p.headers...), data, details
+}
+
+// scenarioDetail is the table of the requests in the scenario and the exemplar trace IDs
+func (p *TracePrinter) scenarioDetail(ts *TraceStats, s *ScenarioStat) *html.Detail {
+	columns := []string{"#", "Method", "Uri", "Status", "Count", "4xx", "5xx", "Error(%)", "Min", "Max", "Sum", "Avg"}
+	for _, n := range p.percentiles {
+		columns = append(columns, fmt.Sprintf("P%d", n))
+	}
+	columns = append(columns, "Avg(Body)")
+	hasRequestTime := s.HasRequestTime()
+	if hasRequestTime {
+		columns = append(columns, "Avg(ReqTime)", "Avg(Overhead)")
+	}
+
+	rows := make([][]string, 0, len(s.RequestDetailsStats))
+	for i, rds := range s.RequestDetailsStats {
+		status := fmt.Sprint(rds.RequestDetail.Status)
+		// the status varies in a scenario if the scenario key ignores it
+		if ts.ignoreStatus() {
+			status = "*"
+		}
+
+		row := []string{
+			fmt.Sprint(i + 1),
+			rds.RequestDetail.Method,
+			rds.RequestDetail.Uri,
+			status,
+			rds.StrCount(),
+			fmt.Sprint(rds.Status4xx),
+			fmt.Sprint(rds.Status5xx),
+			round(rds.ErrorRate()),
+			round(rds.MinResponseTime()),
+			round(rds.MaxResponseTime()),
+			round(rds.SumResponseTime()),
+			round(rds.AvgResponseTime()),
+		}
+		for _, n := range p.percentiles {
+			row = append(row, round(rds.PNResponseTime(n)))
 		}
+		row = append(row, round(rds.AvgResponseBodyBytes()))
+		if hasRequestTime {
+			row = append(row, round(rds.AvgRequestTime()), round(rds.AvgOverhead()))
+		}
+		rows = append(rows, row)
+	}
+
+	traceIDs := s.TraceIDs
+	if len(traceIDs) > exemplarTraceIDsLimit {
+		traceIDs = traceIDs[:exemplarTraceIDsLimit]
+	}
+
+	return &html.Detail{
+		Columns:  columns,
+		Rows:     rows,
+		TraceIDs: traceIDs,
 	}
-	content, _ := html.RenderTableWithGridJS("alp", p.headers, data, p.printOptions.paginationLimit)
-	fmt.Println(content)
 }
 
 func anyToFloat64(v interface{}) float64 {
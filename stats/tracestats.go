@@ -8,12 +8,14 @@ import (
 	"github.com/tetsuzawa/alp-trace/helpers"
 	"github.com/tetsuzawa/alp-trace/options"
 	"github.com/tetsuzawa/alp-trace/parsers"
+	"io"
 	"math"
 	"math/rand"
 	"net/url"
 	"regexp"
 	"sort"
 	"strings"
+	"time"
 )
 
 //type traceHints struct {
@@ -52,6 +54,9 @@ type TraceStats struct {
 	options                        *options.Options
 	sortOptions                    *SortOptions
 	uriMatchingGroups              []*regexp.Regexp
+	series                         *Series
+	traceTimes                     map[string]time.Time
+	histogramBounds                []float64
 }
 
 // TraceRequestDetailsMap -> trace_id: [method1_uri1_, method2_uri2, ...]
@@ -60,10 +65,12 @@ type TraceRequestDetailsMap map[string][]*RequestDetail
 type RequestDetail struct {
 	Uri               string
 	Method            string
+	Time              string `yaml:",omitempty"`
 	Status            int
 	ResponseTime      float64
 	RequestBodyBytes  float64
 	ResponseBodyBytes float64
+	TimeBreakdown     *parsers.TimeBreakdown `yaml:",omitempty"`
 	Pos               int
 }
 
@@ -74,6 +81,7 @@ func NewTraceStats(useResTimePercentile, useRequestBodyBytesPercentile, useRespo
 		GlobalStat:                     newGlobalStat(useResTimePercentile, useRequestBodyBytesPercentile, useResponseBodyBytesPercentile),
 		ScenarioStats:                  make([]*ScenarioStat, 0),
 		useResponseTimePercentile:      useResTimePercentile,
+		useRequestBodyBytesPercentile:  useRequestBodyBytesPercentile,
 		useResponseBodyBytesPercentile: useResponseBodyBytesPercentile,
 	}
 }
@@ -117,20 +125,119 @@ func (ts *TraceStats) AggregateTrace() {
 		//}
 		resultStatIDGenerator := murmur3.New32()
 		for _, requestDetail := range requestDetails {
-			resultStatIDPart := fmt.Sprintf("%s%s%d", requestDetail.Method, requestDetail.Uri, requestDetail.Status)
-			resultStatIDGenerator.Write([]byte(resultStatIDPart))
+			resultStatIDGenerator.Write([]byte(ts.scenarioIDPart(requestDetail)))
 		}
 		resultStatID := fmt.Sprintf("%x", resultStatIDGenerator.Sum(nil))
 
 		// 表示制限の数に至っていなければ追加
 		idx := ts.hints.loadOrStore(resultStatID)
 		if len(ts.ScenarioStats) <= idx {
-			ts.ScenarioStats = append(ts.ScenarioStats, newTraceStat(resultStatID, requestDetails, ts.useResponseTimePercentile, ts.useRequestBodyBytesPercentile, ts.useResponseBodyBytesPercentile))
+			s := newTraceStat(resultStatID, requestDetails, ts.useResponseTimePercentile, ts.useRequestBodyBytesPercentile, ts.useResponseBodyBytesPercentile)
+			s.TraceUriMethodStatus = ts.scenarioRequests(requestDetails)
+			if ts.histogramBounds != nil {
+				s.histogram = newHistogramCounts(ts.histogramBounds)
+			}
+			ts.ScenarioStats = append(ts.ScenarioStats, s)
 		}
 
 		ts.GlobalStat.Set(requestDetails)
 		ts.ScenarioStats[idx].Set(traceID, requestDetails)
+
+		if t, ok := ts.traceTimes[traceID]; ok {
+			ts.appendSeries(t, resultStatID, requestDetails)
+		}
+	}
+}
+
+// Reaggregate aggregates the traces again from the requests appended so far,
+// the scenario of a trace changes while its requests are appended
+func (ts *TraceStats) Reaggregate() {
+	ts.hints = newHints()
+	ts.ScenarioStats = make([]*ScenarioStat, 0)
+	ts.GlobalStat = newGlobalStat(ts.useResponseTimePercentile, ts.useRequestBodyBytesPercentile, ts.useResponseBodyBytesPercentile)
+	if ts.series != nil {
+		ts.series.reset()
+	}
+
+	ts.AggregateTrace()
+}
+
+// SetBucket enables the time series of the scenarios, the traces are bucketed by the time of the first request
+func (ts *TraceStats) SetBucket(bucket time.Duration, location string) error {
+	series, err := NewSeries(bucket, location, []string{"scenario_id"})
+	if err != nil {
+		return err
 	}
+
+	ts.series = series
+	ts.traceTimes = make(map[string]time.Time)
+
+	return nil
+}
+
+func (ts *TraceStats) AppendTraceTime(traceID, timestr string) error {
+	t, err := ts.series.parse(timestr)
+	if err != nil {
+		return err
+	}
+
+	if first, ok := ts.traceTimes[traceID]; !ok || t.Before(first) {
+		ts.traceTimes[traceID] = t
+	}
+
+	return nil
+}
+
+func (ts *TraceStats) appendSeries(t time.Time, scenarioID string, requestDetails []*RequestDetail) {
+	restime := 0.0
+	status := 0
+	for _, requestDetail := range requestDetails {
+		restime += requestDetail.ResponseTime
+		if requestDetail.Status > status {
+			status = requestDetail.Status
+		}
+	}
+
+	ts.series.Add(t, []string{scenarioID}, status, restime)
+}
+
+func (ts *TraceStats) PrintSeries(w io.Writer, format string) error {
+	return ts.series.Print(w, format)
+}
+
+// SetHistogram counts the response times of the scenarios into the buckets of the bounds
+func (ts *TraceStats) SetHistogram(bounds []float64) {
+	ts.histogramBounds = bounds
+}
+
+// Histogram returns the counted buckets of each scenario, call it after the sort
+func (ts *TraceStats) Histogram() (*Histogram, error) {
+	h := NewHistogram(ts.histogramBounds, []string{"scenario_id"})
+	for _, s := range ts.ScenarioStats {
+		counts := s.histogram
+		if counts == nil {
+			var err error
+			counts, err = countResponseTimes(ts.histogramBounds, s.Cnt, s.ResponseTime)
+			if err != nil {
+				return nil, err
+			}
+		}
+		h.Add([]string{s.ID}, counts)
+	}
+
+	return h, nil
+}
+
+// TraceScenarioIDs maps the trace IDs to the IDs of their scenarios
+func (ts *TraceStats) TraceScenarioIDs() map[string]string {
+	scenarioIDs := make(map[string]string)
+	for _, s := range ts.ScenarioStats {
+		for _, traceID := range s.TraceIDs {
+			scenarioIDs[traceID] = s.ID
+		}
+	}
+
+	return scenarioIDs
 }
 
 func (ts *ScenarioStat) UriWithOptions(decode bool) string {
@@ -154,6 +261,34 @@ func (ts *ScenarioStat) UriWithOptions(decode bool) string {
 	return fmt.Sprintf("%s?%s", unescaped, decoded)
 }
 
+// scenarioIDPart returns the part of the scenario ID for a request,
+// the requests with different statuses are the same scenario if the scenario key ignores the status
+func (ts *TraceStats) scenarioIDPart(requestDetail *RequestDetail) string {
+	if ts.ignoreStatus() {
+		return fmt.Sprintf("%s%s", requestDetail.Method, requestDetail.Uri)
+	}
+
+	return fmt.Sprintf("%s%s%d", requestDetail.Method, requestDetail.Uri, requestDetail.Status)
+}
+
+// scenarioRequests joins the requests of a scenario, without the statuses if the scenario key ignores them
+func (ts *TraceStats) scenarioRequests(requestDetails []*RequestDetail) string {
+	requests := make([]string, len(requestDetails))
+	for i, requestDetail := range requestDetails {
+		if ts.ignoreStatus() {
+			requests[i] = fmt.Sprintf("%s %s", requestDetail.Method, requestDetail.Uri)
+		} else {
+			requests[i] = fmt.Sprintf("%s %s %d", requestDetail.Method, requestDetail.Uri, requestDetail.Status)
+		}
+	}
+
+	return strings.Join(requests, ", ")
+}
+
+func (ts *TraceStats) ignoreStatus() bool {
+	return ts.options != nil && ts.options.ScenarioKey == options.ScenarioKeyUriMethod
+}
+
 //func (ts *TraceStats) ScenarioStats() []*ScenarioStat {
 //	return ts.ScenarioStats
 //}
@@ -212,7 +347,9 @@ func (ts *TraceStats) SortWithOptions() {
 }
 
 func (ts *TraceStats) TrimAfterLimit() {
-	ts.ScenarioStats = ts.ScenarioStats[:ts.options.Limit]
+	if len(ts.ScenarioStats) > ts.options.Limit {
+		ts.ScenarioStats = ts.ScenarioStats[:ts.options.Limit]
+	}
 }
 
 // TODO sortedSliceを作って、RequestDetail追加時にソートする
@@ -223,9 +360,10 @@ func (ts *TraceStats) TrimAfterLimit() {
 type ScenarioStat struct {
 	ID string
 	// todo 名前考える
-	// ex: GET /foo/bar 200<br>POST /foo/bar 200
+	// ex: GET /foo/bar 200, POST /foo/bar 200
 	TraceUriMethodStatus string
 	Cnt                  int
+	statusCounts         `yaml:",inline"`
 	ResponseTime         *responseTime
 	RequestBodyBytes     *bodyBytes
 	ResponseBodyBytes    *bodyBytes
@@ -234,6 +372,7 @@ type ScenarioStat struct {
 
 	TraceIDs    []string
 	traceIDRand *rand.Rand
+	histogram   *histogramCounts
 }
 
 func newTraceStat(id string, requestDetails []*RequestDetail, useResTimePercentile, useRequestBodyBytesPercentile, useResponseBodyBytesPercentile bool) *ScenarioStat {
@@ -256,17 +395,26 @@ func (ts *ScenarioStat) Set(traceID string, requestDetails []*RequestDetail) {
 	restime := 0.0
 	resBodyBytes := 0.0
 	reqBodyBytes := 0.0
+	status := 0
 	// total response time, body bytesの計算
 	for _, requestDetail := range requestDetails {
 		restime += requestDetail.ResponseTime
 		resBodyBytes += requestDetail.ResponseBodyBytes
 		reqBodyBytes += requestDetail.RequestBodyBytes
+		// the worst status of the requests
+		if requestDetail.Status > status {
+			status = requestDetail.Status
+		}
 	}
 
 	ts.Cnt++
+	ts.setStatus(status)
 	ts.ResponseTime.Set(restime)
 	ts.RequestBodyBytes.Set(reqBodyBytes)
 	ts.ResponseBodyBytes.Set(resBodyBytes)
+	if ts.histogram != nil {
+		ts.histogram.add(restime)
+	}
 	for i := range ts.RequestDetailsStats {
 		ts.RequestDetailsStats[i].Set(requestDetails[i])
 	}
@@ -332,34 +480,75 @@ func (ts *ScenarioStat) StddevRequestBodyBytes() float64 {
 
 // response
 func (ts *ScenarioStat) MaxResponseBodyBytes() float64 {
-	return ts.RequestBodyBytes.Max
+	return ts.ResponseBodyBytes.Max
 }
 
 func (ts *ScenarioStat) MinResponseBodyBytes() float64 {
-	return ts.RequestBodyBytes.Min
+	return ts.ResponseBodyBytes.Min
 }
 
 func (ts *ScenarioStat) SumResponseBodyBytes() float64 {
-	return ts.RequestBodyBytes.Sum
+	return ts.ResponseBodyBytes.Sum
 }
 
 func (ts *ScenarioStat) AvgResponseBodyBytes() float64 {
-	return ts.RequestBodyBytes.Avg(ts.Cnt)
+	return ts.ResponseBodyBytes.Avg(ts.Cnt)
 }
 
 func (ts *ScenarioStat) PNResponseBodyBytes(n int) float64 {
-	return ts.RequestBodyBytes.PN(ts.Cnt, n)
+	return ts.ResponseBodyBytes.PN(ts.Cnt, n)
 }
 
 func (ts *ScenarioStat) StddevResponseBodyBytes() float64 {
-	return ts.RequestBodyBytes.Stddev(ts.Cnt)
+	return ts.ResponseBodyBytes.Stddev(ts.Cnt)
+}
+
+// request time, the sum of the averages of the requests in the scenario
+func (ts *ScenarioStat) AvgRequestTime() float64 {
+	var v float64
+	for _, rds := range ts.RequestDetailsStats {
+		v += rds.AvgRequestTime()
+	}
+	return v
+}
+
+func (ts *ScenarioStat) AvgOverhead() float64 {
+	var v float64
+	for _, rds := range ts.RequestDetailsStats {
+		v += rds.AvgOverhead()
+	}
+	return v
+}
+
+// HasRequestTime returns true if any request in the scenario has the request time breakdown
+func (ts *ScenarioStat) HasRequestTime() bool {
+	for _, rds := range ts.RequestDetailsStats {
+		if rds.RequestTime != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (ts *ScenarioStat) ErrorRate() float64 {
+	return ts.errorRate(ts.Cnt)
 }
 
 func (ts *ScenarioStat) RandomTraceID() string {
 	return ts.TraceIDs[ts.traceIDRand.Intn(len(ts.TraceIDs))]
 }
 
-func (ts *TraceStats) AppendTrace(traceID, uri, method string, status int, restime, resBodyBytes, reqBodyBytes float64, pos int) {
+func (ts *TraceStats) HasRequestBodyBytes() bool {
+	for _, s := range ts.ScenarioStats {
+		if s.RequestBodyBytes.Sum > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (ts *TraceStats) AppendTrace(traceID, uri, method, timestr string, status int, restime, resBodyBytes, reqBodyBytes float64, timeBreakdown *parsers.TimeBreakdown, pos int) {
 	if len(ts.uriMatchingGroups) > 0 {
 		for _, re := range ts.uriMatchingGroups {
 			if ok := re.Match([]byte(uri)); ok {
@@ -373,10 +562,12 @@ func (ts *TraceStats) AppendTrace(traceID, uri, method string, status int, resti
 	requestDetail := &RequestDetail{
 		Uri:               uri,
 		Method:            method,
+		Time:              timestr,
 		Status:            status,
 		ResponseTime:      restime,
-		RequestBodyBytes:  resBodyBytes,
-		ResponseBodyBytes: reqBodyBytes,
+		RequestBodyBytes:  reqBodyBytes,
+		ResponseBodyBytes: resBodyBytes,
+		TimeBreakdown:     timeBreakdown,
 		Pos:               pos,
 	}
 	ts.traceRequestDetailsMap[traceID] = append(ts.traceRequestDetailsMap[traceID], requestDetail)
@@ -386,6 +577,8 @@ func (ts *TraceStats) Sort(sortOptions *SortOptions, reverse bool) {
 	switch sortOptions.sortType {
 	case SortCount:
 		ts.SortCount(reverse)
+	case SortErrorRate:
+		ts.SortErrorRate(reverse)
 	//case SortUri:
 	//	ts.SortUri(reverse)
 	//case SortMethod:
@@ -446,6 +639,18 @@ func (ts *TraceStats) SortCount(reverse bool) {
 	}
 }
 
+func (ts *TraceStats) SortErrorRate(reverse bool) {
+	if reverse {
+		sort.Slice(ts.ScenarioStats, func(i, j int) bool {
+			return ts.ScenarioStats[i].ErrorRate() > ts.ScenarioStats[j].ErrorRate()
+		})
+	} else {
+		sort.Slice(ts.ScenarioStats, func(i, j int) bool {
+			return ts.ScenarioStats[i].ErrorRate() < ts.ScenarioStats[j].ErrorRate()
+		})
+	}
+}
+
 //func (ts *ScenarioStats) SortUri(reverse bool) {
 //	if reverse {
 //		sort.Slice(ts.ScenarioStats, func(i, j int) bool {
@@ -758,6 +963,11 @@ func (ts *TraceStats) DrawRequestHR() string {
 
 func (ts *TraceStats) FormatRequest(method, uri string, status int) string {
 	wm, wu, ws := ts.widthMethod(), ts.widthUri(), ts.widthStatus()
+	// the status varies in a scenario if the scenario key ignores it
+	if ts.ignoreStatus() {
+		f := fmt.Sprintf("%%-%ds %%-%ds %%%ds", wm, wu, ws)
+		return fmt.Sprintf(f, method, uri, "*")
+	}
 	f := fmt.Sprintf("%%-%ds %%-%ds %%%dd", wm, wu, ws)
 	return fmt.Sprintf(f, method, uri, status)
 }
@@ -838,6 +1048,28 @@ func (ts *TraceStats) FormatRate(v float64) string {
 	return fmt.Sprintf(f, v)
 }
 
+func (ts *TraceStats) widthErrorRate() int {
+	w := 6
+	return w
+}
+
+func (ts *TraceStats) DrawErrorRateHeader() string {
+	w := ts.widthErrorRate()
+	s := "Error"
+	return s + strings.Repeat(" ", w-len(s))
+}
+
+func (ts *TraceStats) DrawErrorRateHR() string {
+	w := ts.widthErrorRate()
+	return strings.Repeat("=", w)
+}
+
+func (ts *TraceStats) FormatErrorRate(v float64) string {
+	w := ts.widthErrorRate() - 1
+	f := fmt.Sprintf("%%%d.1f%%%%", w)
+	return fmt.Sprintf(f, v)
+}
+
 func (ts *TraceStats) widthMin() int {
 	w := getIntWidth(ts.GlobalStat.ResponseTime.Min)
 	if w < 2 {
@@ -1069,30 +1301,74 @@ func (ts *GlobalStat) Set(requestDetails []*RequestDetail) {
 	}
 }
 
+// statusCounts counts the status classes, 4xx and 5xx are the errors
+type statusCounts struct {
+	Status1xx int `yaml:"status1xx"`
+	Status2xx int `yaml:"status2xx"`
+	Status3xx int `yaml:"status3xx"`
+	Status4xx int `yaml:"status4xx"`
+	Status5xx int `yaml:"status5xx"`
+}
+
+func (sc *statusCounts) setStatus(status int) {
+	if status >= 100 && status <= 199 {
+		sc.Status1xx++
+	} else if status >= 200 && status <= 299 {
+		sc.Status2xx++
+	} else if status >= 300 && status <= 399 {
+		sc.Status3xx++
+	} else if status >= 400 && status <= 499 {
+		sc.Status4xx++
+	} else if status >= 500 && status <= 599 {
+		sc.Status5xx++
+	}
+}
+
+// errorRate returns the percentage of the errors
+func (sc *statusCounts) errorRate(cnt int) float64 {
+	if cnt == 0 {
+		return 0
+	}
+	return float64(sc.Status4xx+sc.Status5xx) / float64(cnt) * 100
+}
+
 type RequestDetailStat struct {
 	// todo 名前考える
 	// ex: GET /foo/bar 200<br>POST /foo/bar 200
 	RequestDetail     *RequestDetail
 	Cnt               int
+	statusCounts      `yaml:",inline"`
 	ResponseTime      *responseTime
 	RequestBodyBytes  *bodyBytes
 	ResponseBodyBytes *bodyBytes
+	RequestTime       *requestTimeStat `yaml:",omitempty"`
+
+	useResponseTimePercentile bool
 }
 
 func newRequestDetailStat(requestDetail *RequestDetail, useResTimePercentile, useRequestBodyBytesPercentile, useResponseBodyBytesPercentile bool) *RequestDetailStat {
 	return &RequestDetailStat{
-		RequestDetail:     requestDetail,
-		ResponseTime:      newResponseTime(useResTimePercentile),
-		RequestBodyBytes:  newBodyBytes(useRequestBodyBytesPercentile),
-		ResponseBodyBytes: newBodyBytes(useResponseBodyBytesPercentile),
+		RequestDetail:             requestDetail,
+		ResponseTime:              newResponseTime(useResTimePercentile),
+		RequestBodyBytes:          newBodyBytes(useRequestBodyBytesPercentile),
+		ResponseBodyBytes:         newBodyBytes(useResponseBodyBytesPercentile),
+		useResponseTimePercentile: useResTimePercentile,
 	}
 }
 
 func (ts *RequestDetailStat) Set(requestDetail *RequestDetail) {
 	ts.Cnt++
+	ts.setStatus(requestDetail.Status)
 	ts.ResponseTime.Set(requestDetail.ResponseTime)
 	ts.RequestBodyBytes.Set(requestDetail.RequestBodyBytes)
 	ts.ResponseBodyBytes.Set(requestDetail.ResponseBodyBytes)
+
+	if requestDetail.TimeBreakdown != nil {
+		if ts.RequestTime == nil {
+			ts.RequestTime = newRequestTimeStat(ts.useResponseTimePercentile)
+		}
+		ts.RequestTime.Set(requestDetail.TimeBreakdown)
+	}
 }
 
 func (ts *RequestDetailStat) Count() int {
@@ -1103,6 +1379,10 @@ func (ts *RequestDetailStat) StrCount() string {
 	return fmt.Sprint(ts.Cnt)
 }
 
+func (ts *RequestDetailStat) ErrorRate() float64 {
+	return ts.errorRate(ts.Cnt)
+}
+
 func (ts *RequestDetailStat) MaxResponseTime() float64 {
 	return ts.ResponseTime.Max
 }
@@ -1154,27 +1434,42 @@ func (ts *RequestDetailStat) StddevRequestBodyBytes() float64 {
 
 // response
 func (ts *RequestDetailStat) MaxResponseBodyBytes() float64 {
-	return ts.RequestBodyBytes.Max
+	return ts.ResponseBodyBytes.Max
 }
 
 func (ts *RequestDetailStat) MinResponseBodyBytes() float64 {
-	return ts.RequestBodyBytes.Min
+	return ts.ResponseBodyBytes.Min
 }
 
 func (ts *RequestDetailStat) SumResponseBodyBytes() float64 {
-	return ts.RequestBodyBytes.Sum
+	return ts.ResponseBodyBytes.Sum
 }
 
 func (ts *RequestDetailStat) AvgResponseBodyBytes() float64 {
-	return ts.RequestBodyBytes.Avg(ts.Cnt)
+	return ts.ResponseBodyBytes.Avg(ts.Cnt)
 }
 
 func (ts *RequestDetailStat) PNResponseBodyBytes(n int) float64 {
-	return ts.RequestBodyBytes.PN(ts.Cnt, n)
+	return ts.ResponseBodyBytes.PN(ts.Cnt, n)
 }
 
 func (ts *RequestDetailStat) StddevResponseBodyBytes() float64 {
-	return ts.RequestBodyBytes.Stddev(ts.Cnt)
+	return ts.ResponseBodyBytes.Stddev(ts.Cnt)
+}
+
+// request time
+func (ts *RequestDetailStat) AvgRequestTime() float64 {
+	if ts.RequestTime == nil {
+		return 0
+	}
+	return ts.RequestTime.RequestTime.Avg(ts.RequestTime.Cnt)
+}
+
+func (ts *RequestDetailStat) AvgOverhead() float64 {
+	if ts.RequestTime == nil {
+		return 0
+	}
+	return ts.RequestTime.Overhead.Avg(ts.RequestTime.Cnt)
 }
 
 // ==============================
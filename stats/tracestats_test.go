@@ -0,0 +1,71 @@
+package stats
+
+import (
+	"testing"
+
+	"github.com/tetsuzawa/alp-trace/options"
+)
+
+func TestTraceStatsScenarioRequests(t *testing.T) {
+	tests := []struct {
+		scenarioKey string
+		want        []string
+	}{
+		{
+			scenarioKey: options.ScenarioKeyUriMethodStatus,
+			want:        []string{"POST /login 200, GET /home 200", "POST /login 200, GET /home 500"},
+		},
+		{
+			scenarioKey: options.ScenarioKeyUriMethod,
+			want:        []string{"POST /login, GET /home"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.scenarioKey, func(t *testing.T) {
+			ts := NewTraceStats(true, false, false)
+			ts.SetOptions(options.NewOptions(options.ScenarioKey(tt.scenarioKey)))
+			ts.AppendTrace("a", "/login", "POST", "", 200, 0.1, 10, 0, nil, 0)
+			ts.AppendTrace("a", "/home", "GET", "", 200, 0.1, 10, 0, nil, 0)
+			ts.AppendTrace("b", "/login", "POST", "", 200, 0.1, 10, 0, nil, 0)
+			ts.AppendTrace("b", "/home", "GET", "", 500, 0.1, 10, 0, nil, 0)
+			ts.AggregateTrace()
+
+			got := make(map[string]bool)
+			for _, s := range ts.ScenarioStats {
+				got[s.TraceUriMethodStatus] = true
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("want: %v, got: %v", tt.want, got)
+			}
+			for _, w := range tt.want {
+				if !got[w] {
+					t.Errorf("want: %v, got: %v", tt.want, got)
+				}
+			}
+		})
+	}
+}
+
+type traceRequest struct {
+	traceID string
+	time    string
+	method  string
+	uri     string
+	status  int
+	restime float64
+}
+
+// newTestTraceStats aggregates the requests and sorts the scenarios by count
+func newTestTraceStats(scenarioKey string, requests []traceRequest) *TraceStats {
+	ts := NewTraceStats(true, false, false)
+	ts.SetOptions(options.NewOptions(options.ScenarioKey(scenarioKey)))
+	for _, r := range requests {
+		ts.AppendTrace(r.traceID, r.uri, r.method, r.time, r.status, r.restime, 10, 0, nil, 0)
+	}
+	ts.AggregateTrace()
+	ts.Sort(NewSortOptions(), false)
+
+	return ts
+}
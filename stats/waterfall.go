@@ -0,0 +1,151 @@
+package stats
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/tkuchiki/parsetime"
+)
+
+const waterfallBarWidth = 40
+
+// PrintWaterfall outputs the requests of each trace in order with the bars of their start offsets and durations,
+// it returns an error for the trace IDs that are not found after printing the others
+func (ts *TraceStats) PrintWaterfall(w io.Writer, traceIDs []string, location string) error {
+	pt, err := parsetime.NewParseTime(location)
+	if err != nil {
+		return err
+	}
+
+	scenarioIDs := ts.TraceScenarioIDs()
+	notFound := make([]string, 0)
+	printed := 0
+	for _, traceID := range traceIDs {
+		requestDetails, ok := ts.traceRequestDetailsMap[traceID]
+		if !ok {
+			notFound = append(notFound, traceID)
+			continue
+		}
+
+		if printed > 0 {
+			fmt.Fprintln(w)
+		}
+		printed++
+
+		requests, timed := chromeTraceRequests(pt, requestDetails)
+		printWaterfall(w, traceID, scenarioIDs[traceID], requests, timed)
+	}
+
+	if len(notFound) > 0 {
+		return fmt.Errorf("trace IDs not found: %s", strings.Join(notFound, ", "))
+	}
+
+	return nil
+}
+
+func printWaterfall(w io.Writer, traceID, scenarioID string, requests []*chromeTraceRequest, timed bool) {
+	origin := requests[0].start
+	end := requests[0].start
+	total := 0.0
+	showReqBody := false
+	for _, r := range requests {
+		if r.start.Before(origin) {
+			origin = r.start
+		}
+		if e := r.start.Add(secondsToDuration(r.requestDetail.ResponseTime)); e.After(end) {
+			end = e
+		}
+		total += r.requestDetail.ResponseTime
+		if r.requestDetail.RequestBodyBytes > 0 {
+			showReqBody = true
+		}
+	}
+	span := end.Sub(origin).Seconds()
+
+	fmt.Fprintf(w, "Trace ID:    %s\n", traceID)
+	fmt.Fprintf(w, "Scenario ID: %s\n", scenarioID)
+	fmt.Fprintf(w, "Requests:    %d\n", len(requests))
+	fmt.Fprintf(w, "Total span:  %s\n", milliseconds(span))
+	fmt.Fprintf(w, "Total time:  %s\n", milliseconds(total))
+	if !timed {
+		fmt.Fprintln(w, "(the time of the requests cannot be parsed, so they are laid out one after another)")
+	}
+	fmt.Fprintln(w)
+
+	headers := []string{"START", "DURATION", "WATERFALL", "METHOD", "URI", "STATUS", "BODY"}
+	if showReqBody {
+		headers = append(headers, "REQ BODY")
+	}
+
+	rows := make([][]string, 0, len(requests))
+	for _, r := range requests {
+		rd := r.requestDetail
+		offset := r.start.Sub(origin).Seconds()
+		row := []string{
+			milliseconds(offset),
+			milliseconds(rd.ResponseTime),
+			fmt.Sprintf("|%s|", waterfallBar(offset, rd.ResponseTime, span)),
+			rd.Method,
+			rd.Uri,
+			fmt.Sprint(rd.Status),
+			fmt.Sprintf("%.0f", rd.ResponseBodyBytes),
+		}
+		if showReqBody {
+			row = append(row, fmt.Sprintf("%.0f", rd.RequestBodyBytes))
+		}
+		rows = append(rows, row)
+	}
+
+	widths := make([]int, len(headers))
+	for i, h := range headers {
+		widths[i] = len(h)
+	}
+	for _, row := range rows {
+		for i, col := range row {
+			if len(col) > widths[i] {
+				widths[i] = len(col)
+			}
+		}
+	}
+
+	printRow := func(row []string) {
+		cols := make([]string, len(row))
+		for i, col := range row {
+			// the numbers are aligned to the right
+			if i < 2 {
+				cols[i] = fmt.Sprintf("%*s", widths[i], col)
+			} else {
+				cols[i] = fmt.Sprintf("%-*s", widths[i], col)
+			}
+		}
+		fmt.Fprintln(w, strings.TrimRight(strings.Join(cols, "  "), " "))
+	}
+
+	printRow(headers)
+	for _, row := range rows {
+		printRow(row)
+	}
+}
+
+// waterfallBar draws the duration from the offset in the span, a request is at least one character
+func waterfallBar(offset, duration, span float64) string {
+	if span <= 0 {
+		return strings.Repeat("#", waterfallBarWidth)
+	}
+
+	start := int(math.Floor(offset / span * waterfallBarWidth))
+	end := int(math.Ceil((offset + duration) / span * waterfallBarWidth))
+	if start >= waterfallBarWidth {
+		start = waterfallBarWidth - 1
+	}
+	if end > waterfallBarWidth {
+		end = waterfallBarWidth
+	}
+	if end <= start {
+		end = start + 1
+	}
+
+	return strings.Repeat(" ", start) + strings.Repeat("#", end-start) + strings.Repeat(" ", waterfallBarWidth-end)
+}
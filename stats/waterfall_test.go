@@ -0,0 +1,114 @@
+package stats
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/tetsuzawa/alp-trace/options"
+)
+
+func TestWaterfallBar(t *testing.T) {
+	tests := []struct {
+		name     string
+		offset   float64
+		duration float64
+		span     float64
+		want     string
+	}{
+		{
+			name:     "whole span",
+			offset:   0,
+			duration: 1,
+			span:     1,
+			want:     strings.Repeat("#", 40),
+		},
+		{
+			name:     "first half",
+			offset:   0,
+			duration: 0.5,
+			span:     1,
+			want:     strings.Repeat("#", 20) + strings.Repeat(" ", 20),
+		},
+		{
+			name:     "second half",
+			offset:   0.5,
+			duration: 0.5,
+			span:     1,
+			want:     strings.Repeat(" ", 20) + strings.Repeat("#", 20),
+		},
+		{
+			name:     "rounded out",
+			offset:   0.01,
+			duration: 0.02,
+			span:     1,
+			want:     "##" + strings.Repeat(" ", 38),
+		},
+		{
+			name:     "zero duration",
+			offset:   0.5,
+			duration: 0,
+			span:     1,
+			want:     strings.Repeat(" ", 20) + "#" + strings.Repeat(" ", 19),
+		},
+		{
+			name:     "end of span",
+			offset:   1,
+			duration: 0,
+			span:     1,
+			want:     strings.Repeat(" ", 39) + "#",
+		},
+		{
+			name:     "over the span",
+			offset:   0.75,
+			duration: 1,
+			span:     1,
+			want:     strings.Repeat(" ", 30) + strings.Repeat("#", 10),
+		},
+		{
+			name:     "zero span",
+			offset:   0,
+			duration: 0,
+			span:     0,
+			want:     strings.Repeat("#", 40),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := waterfallBar(tt.offset, tt.duration, tt.span)
+			if got != tt.want {
+				t.Errorf("want: %q, got: %q", tt.want, got)
+			}
+			if len(got) != waterfallBarWidth {
+				t.Errorf("want width: %d, got: %d", waterfallBarWidth, len(got))
+			}
+		})
+	}
+}
+
+func TestPrintWaterfall(t *testing.T) {
+	ts := newTestTraceStats(options.ScenarioKeyUriMethodStatus, []traceRequest{
+		{traceID: "a", time: "2024-01-01T00:00:01Z", method: "POST", uri: "/login", status: 200, restime: 1},
+		{traceID: "a", time: "2024-01-01T00:00:02Z", method: "GET", uri: "/home", status: 200, restime: 1},
+	})
+
+	var buf bytes.Buffer
+	err := ts.PrintWaterfall(&buf, []string{"a", "unknown"}, "UTC")
+	if err == nil || !strings.Contains(err.Error(), "unknown") {
+		t.Errorf("want the error of the unknown trace ID, got: %v", err)
+	}
+
+	want := "Trace ID:    a\n" +
+		"Scenario ID: " + ts.ScenarioStats[0].ID + "\n" +
+		"Requests:    2\n" +
+		"Total span:  2000.0ms\n" +
+		"Total time:  2000.0ms\n" +
+		"\n" +
+		"   START  DURATION  WATERFALL                                   METHOD  URI     STATUS  BODY\n" +
+		"   0.0ms  1000.0ms  |" + strings.Repeat("#", 20) + strings.Repeat(" ", 20) + "|  POST    /login  200     10\n" +
+		"1000.0ms  1000.0ms  |" + strings.Repeat(" ", 20) + strings.Repeat("#", 20) + "|  GET     /home   200     10\n"
+	if buf.String() != want {
+		t.Errorf("want:\n%s\ngot:\n%s", want, buf.String())
+	}
+}
@@ -0,0 +1,114 @@
+package tui
+
+import "unicode/utf8"
+
+type keyCode int
+
+const (
+	keyRune keyCode = iota
+	keyUp
+	keyDown
+	keyLeft
+	keyRight
+	keyPageUp
+	keyPageDown
+	keyHome
+	keyEnd
+	keyEnter
+	keyEscape
+	keyBackspace
+	keyTab
+	keyCtrlC
+	keyCtrlU
+	keyUnknown
+)
+
+type key struct {
+	code keyCode
+	r    rune
+}
+
+// parseKeys splits the input of the terminal into the keys,
+// a lone escape is the escape key and the others are the control sequences of the cursor keys
+func parseKeys(b []byte) []key {
+	keys := make([]key, 0, len(b))
+	for len(b) > 0 {
+		switch c := b[0]; {
+		case c == 0x1b:
+			if len(b) > 1 && (b[1] == '[' || b[1] == 'O') {
+				k, n := parseSequence(b[2:])
+				keys = append(keys, k)
+				b = b[2+n:]
+				continue
+			}
+			keys = append(keys, key{code: keyEscape})
+		case c == '\r' || c == '\n':
+			keys = append(keys, key{code: keyEnter})
+		case c == 0x7f || c == 0x08:
+			keys = append(keys, key{code: keyBackspace})
+		case c == '\t':
+			keys = append(keys, key{code: keyTab})
+		case c == 0x03:
+			keys = append(keys, key{code: keyCtrlC})
+		case c == 0x15:
+			keys = append(keys, key{code: keyCtrlU})
+		case c == 0x02:
+			keys = append(keys, key{code: keyPageUp})
+		case c == 0x06:
+			keys = append(keys, key{code: keyPageDown})
+		case c < 0x20:
+			keys = append(keys, key{code: keyUnknown})
+		default:
+			r, n := utf8.DecodeRune(b)
+			if r != utf8.RuneError {
+				keys = append(keys, key{code: keyRune, r: r})
+			}
+			b = b[n:]
+			continue
+		}
+		b = b[1:]
+	}
+
+	return keys
+}
+
+// parseSequence reads the parameters and the final byte after ESC [ or ESC O,
+// it returns the key and the number of the bytes read
+func parseSequence(b []byte) (key, int) {
+	n := 0
+	for n < len(b) && (b[n] < 0x40 || b[n] > 0x7e) {
+		n++
+	}
+	if n == len(b) {
+		return key{code: keyUnknown}, n
+	}
+
+	params := string(b[:n])
+	switch b[n] {
+	case 'A':
+		return key{code: keyUp}, n + 1
+	case 'B':
+		return key{code: keyDown}, n + 1
+	case 'C':
+		return key{code: keyRight}, n + 1
+	case 'D':
+		return key{code: keyLeft}, n + 1
+	case 'H':
+		return key{code: keyHome}, n + 1
+	case 'F':
+		return key{code: keyEnd}, n + 1
+	case '~':
+		switch params {
+		case "1", "7":
+			return key{code: keyHome}, n + 1
+		case "4", "8":
+			return key{code: keyEnd}, n + 1
+		case "5":
+			return key{code: keyPageUp}, n + 1
+		case "6":
+			return key{code: keyPageDown}, n + 1
+		}
+	}
+
+	return key{code: keyUnknown}, n + 1
+}
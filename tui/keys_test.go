@@ -0,0 +1,33 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseKeys(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []key
+	}{
+		{name: "runes", input: "jé", want: []key{{code: keyRune, r: 'j'}, {code: keyRune, r: 'é'}}},
+		{name: "cursor", input: "\x1b[A\x1b[B\x1b[C\x1b[D", want: []key{{code: keyUp}, {code: keyDown}, {code: keyRight}, {code: keyLeft}}},
+		{name: "application cursor", input: "\x1bOA\x1bOH\x1bOF", want: []key{{code: keyUp}, {code: keyHome}, {code: keyEnd}}},
+		{name: "pages", input: "\x1b[5~\x1b[6~\x02\x06", want: []key{{code: keyPageUp}, {code: keyPageDown}, {code: keyPageUp}, {code: keyPageDown}}},
+		{name: "home and end", input: "\x1b[1~\x1b[4~\x1b[7~\x1b[8~", want: []key{{code: keyHome}, {code: keyEnd}, {code: keyHome}, {code: keyEnd}}},
+		{name: "escape", input: "\x1bq", want: []key{{code: keyEscape}, {code: keyRune, r: 'q'}}},
+		{name: "controls", input: "\r\n\x7f\x08\t\x03\x15\x01", want: []key{{code: keyEnter}, {code: keyEnter}, {code: keyBackspace}, {code: keyBackspace}, {code: keyTab}, {code: keyCtrlC}, {code: keyCtrlU}, {code: keyUnknown}}},
+		{name: "unknown sequence", input: "\x1b[2~\x1b[1;5Aj", want: []key{{code: keyUnknown}, {code: keyUp}, {code: keyRune, r: 'j'}}},
+		{name: "incomplete sequence", input: "\x1b[12", want: []key{{code: keyUnknown}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseKeys([]byte(tt.input))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("want: %+v, got: %+v", tt.want, got)
+			}
+		})
+	}
+}
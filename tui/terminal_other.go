@@ -0,0 +1,35 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+
+package tui
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+)
+
+type terminal struct{}
+
+func openTerminal() (*terminal, error) {
+	return nil, fmt.Errorf("tui is not supported on %s", runtime.GOOS)
+}
+
+func (t *terminal) Read(b []byte) (int, error) {
+	return 0, nil
+}
+
+func (t *terminal) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+func (t *terminal) size() (int, int) {
+	return defaultWidth, defaultHeight
+}
+
+func (t *terminal) resized() <-chan os.Signal {
+	return nil
+}
+
+func (t *terminal) Close() error {
+	return nil
+}
@@ -0,0 +1,83 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package tui
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// terminal is the controlling terminal in the raw mode,
+// it is opened apart from stdin because the log may be read from stdin
+type terminal struct {
+	tty   *os.File
+	state unix.Termios
+	winch chan os.Signal
+}
+
+func openTerminal() (*terminal, error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	fd := int(tty.Fd())
+	state, err := unix.IoctlGetTermios(fd, ioctlReadTermios)
+	if err != nil {
+		tty.Close()
+		return nil, err
+	}
+
+	raw := *state
+	raw.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP | unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON
+	raw.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+	raw.Cc[unix.VMIN] = 1
+	raw.Cc[unix.VTIME] = 0
+	if err = unix.IoctlSetTermios(fd, ioctlWriteTermios, &raw); err != nil {
+		tty.Close()
+		return nil, err
+	}
+
+	winch := make(chan os.Signal, 1)
+	signal.Notify(winch, syscall.SIGWINCH)
+
+	return &terminal{
+		tty:   tty,
+		state: *state,
+		winch: winch,
+	}, nil
+}
+
+func (t *terminal) Read(b []byte) (int, error) {
+	return t.tty.Read(b)
+}
+
+func (t *terminal) Write(b []byte) (int, error) {
+	return t.tty.Write(b)
+}
+
+// size returns the columns and the rows of the terminal
+func (t *terminal) size() (int, int) {
+	ws, err := unix.IoctlGetWinsize(int(t.tty.Fd()), unix.TIOCGWINSZ)
+	if err != nil || ws.Col == 0 || ws.Row == 0 {
+		return defaultWidth, defaultHeight
+	}
+
+	return int(ws.Col), int(ws.Row)
+}
+
+// resized receives when the size of the terminal is changed
+func (t *terminal) resized() <-chan os.Signal {
+	return t.winch
+}
+
+// Close restores the mode of the terminal
+func (t *terminal) Close() error {
+	signal.Stop(t.winch)
+	unix.IoctlSetTermios(int(t.tty.Fd()), ioctlWriteTermios, &t.state)
+
+	return t.tty.Close()
+}
@@ -0,0 +1,698 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+const (
+	defaultWidth  = 80
+	defaultHeight = 24
+	// the longer cells are truncated, e.g. the URIs
+	maxCellWidth = 60
+	columnGap    = 2
+)
+
+const (
+	escHome           = "\x1b[H"
+	escClearLine      = "\x1b[K"
+	escClearBelow     = "\x1b[J"
+	escReset          = "\x1b[0m"
+	escBold           = "\x1b[1m"
+	escUnderline      = "\x1b[4m"
+	escInverse        = "\x1b[7m"
+	escEnterAltScreen = "\x1b[?1049h\x1b[?25l"
+	escExitAltScreen  = "\x1b[?25h\x1b[?1049l"
+)
+
+// Table is the rows of the endpoints or the scenarios, the rows that have the details can be opened
+type Table struct {
+	Title   string
+	Columns []string
+	Rows    [][]string
+	Details []*Detail
+}
+
+// Detail is the table of the requests in a scenario and its exemplar trace IDs
+type Detail struct {
+	Columns  []string
+	Rows     [][]string
+	TraceIDs []string
+}
+
+// Source makes the tables from the requests that match the filters in the same syntax as --filters
+type Source interface {
+	Tables(filters string) ([]*Table, error)
+	Waterfall(traceID string) (string, error)
+}
+
+// screen is the terminal that the keys are read from and the tables are drawn on
+type screen interface {
+	io.ReadWriter
+	// size returns the columns and the rows
+	size() (int, int)
+	// resized receives when the size is changed
+	resized() <-chan os.Signal
+}
+
+type page int
+
+const (
+	pageTable page = iota
+	pageDetail
+	pageWaterfall
+)
+
+// tableState is the sort and the cursor of a table, they are kept when the tables are made again with the filters
+type tableState struct {
+	column  int
+	sorted  string
+	reverse bool
+	cursor  int
+	offset  int
+	first   int
+	order   []int
+}
+
+type explorer struct {
+	src     Source
+	title   string
+	tables  []*Table
+	states  []*tableState
+	view    int
+	page    page
+	filters string
+	editing bool
+	input   []rune
+	message string
+
+	detail       *Detail
+	detailTitle  string
+	detailCursor int
+	detailOffset int
+
+	waterfall       []string
+	waterfallOffset int
+
+	width  int
+	height int
+}
+
+// Run explores the tables of the source on the terminal until q is pressed,
+// the view is the index of the table shown first
+func Run(src Source, title string, view int) error {
+	tables, err := src.Tables("")
+	if err != nil {
+		return err
+	}
+
+	t, err := openTerminal()
+	if err != nil {
+		return err
+	}
+	defer t.Close()
+
+	e := newExplorer(src, title, tables, view)
+
+	return e.run(t)
+}
+
+func newExplorer(src Source, title string, tables []*Table, view int) *explorer {
+	e := &explorer{
+		src:    src,
+		title:  title,
+		width:  defaultWidth,
+		height: defaultHeight,
+	}
+	e.setTables(tables)
+
+	if view >= 0 && view < len(tables) {
+		e.view = view
+	}
+
+	return e
+}
+
+func (e *explorer) run(t screen) error {
+	fmt.Fprint(t, escEnterAltScreen)
+	defer fmt.Fprint(t, escExitAltScreen)
+
+	input := make(chan []byte)
+	errCh := make(chan error, 1)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		buf := make([]byte, 256)
+		for {
+			n, err := t.Read(buf)
+			if err != nil {
+				errCh <- err
+				return
+			}
+
+			b := make([]byte, n)
+			copy(b, buf[:n])
+			select {
+			case input <- b:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	for {
+		e.width, e.height = t.size()
+		e.draw(t)
+
+		select {
+		case b := <-input:
+			for _, k := range parseKeys(b) {
+				if !e.handle(k) {
+					return nil
+				}
+			}
+		case <-t.resized():
+		case err := <-errCh:
+			return err
+		}
+	}
+}
+
+func (e *explorer) setTables(tables []*Table) {
+	for len(e.states) < len(tables) {
+		e.states = append(e.states, &tableState{})
+	}
+
+	e.tables = tables
+	for i, t := range tables {
+		e.states[i].sort(t)
+	}
+}
+
+// sort orders the rows by the sorted column as same as the html format,
+// the numbers are compared as the numbers and the others as the strings
+func (st *tableState) sort(t *Table) {
+	st.order = make([]int, len(t.Rows))
+	for i := range st.order {
+		st.order[i] = i
+	}
+
+	if column := indexOf(t.Columns, st.sorted); column >= 0 {
+		sort.SliceStable(st.order, func(i, j int) bool {
+			c := compareCells(cell(t.Rows[st.order[i]], column), cell(t.Rows[st.order[j]], column))
+			if st.reverse {
+				return c > 0
+			}
+			return c < 0
+		})
+	} else if st.reverse {
+		for i, j := 0, len(st.order)-1; i < j; i, j = i+1, j-1 {
+			st.order[i], st.order[j] = st.order[j], st.order[i]
+		}
+	}
+
+	st.column = clamp(st.column, 0, len(t.Columns)-1)
+	st.cursor = clamp(st.cursor, 0, len(st.order)-1)
+}
+
+func compareCells(a, b string) int {
+	fa, errA := strconv.ParseFloat(a, 64)
+	fb, errB := strconv.ParseFloat(b, 64)
+	if errA == nil && errB == nil {
+		switch {
+		case fa < fb:
+			return -1
+		case fa > fb:
+			return 1
+		}
+		return 0
+	}
+
+	return strings.Compare(a, b)
+}
+
+func (k key) is(r rune) bool {
+	return k.code == keyRune && k.r == r
+}
+
+// handle changes the state by the key, it returns false to quit
+func (e *explorer) handle(k key) bool {
+	if k.code == keyCtrlC {
+		return false
+	}
+
+	if e.editing {
+		e.edit(k)
+		return true
+	}
+
+	e.message = ""
+	switch e.page {
+	case pageTable:
+		return e.handleTable(k)
+	case pageDetail:
+		e.handleDetail(k)
+	case pageWaterfall:
+		e.handleWaterfall(k)
+	}
+
+	return true
+}
+
+func (e *explorer) handleTable(k key) bool {
+	t, st := e.tables[e.view], e.states[e.view]
+
+	switch {
+	case k.code == keyUp || k.is('k'):
+		st.cursor--
+	case k.code == keyDown || k.is('j'):
+		st.cursor++
+	case k.code == keyPageUp:
+		st.cursor -= e.tableRows()
+	case k.code == keyPageDown || k.is(' '):
+		st.cursor += e.tableRows()
+	case k.code == keyHome || k.is('g'):
+		st.cursor = 0
+	case k.code == keyEnd || k.is('G'):
+		st.cursor = len(st.order) - 1
+	case k.code == keyLeft || k.is('h'):
+		st.column--
+	case k.code == keyRight || k.is('l'):
+		st.column++
+	case k.is('s'):
+		if len(t.Columns) == 0 {
+			break
+		}
+		// the same column is sorted in the reverse order
+		if name := t.Columns[st.column]; st.sorted == name {
+			st.reverse = !st.reverse
+		} else {
+			st.sorted = name
+			st.reverse = false
+		}
+		st.sort(t)
+	case k.is('r'):
+		st.reverse = !st.reverse
+		st.sort(t)
+	case k.is('/'):
+		e.editing = true
+		e.input = []rune(e.filters)
+	case k.code == keyTab:
+		e.view = (e.view + 1) % len(e.tables)
+		return true
+	case k.code == keyEnter:
+		if t.Details == nil || len(st.order) == 0 {
+			break
+		}
+		row := st.order[st.cursor]
+		e.detail = t.Details[row]
+		e.detailTitle = fmt.Sprintf("%s: %s", cell(t.Columns, 0), cell(t.Rows[row], 0))
+		e.detailCursor = 0
+		e.detailOffset = 0
+		e.page = pageDetail
+	case k.is('q'):
+		return false
+	}
+
+	st.cursor = clamp(st.cursor, 0, len(st.order)-1)
+	st.column = clamp(st.column, 0, len(t.Columns)-1)
+
+	return true
+}
+
+func (e *explorer) handleDetail(k key) {
+	switch {
+	case k.code == keyUp || k.is('k'):
+		e.detailCursor--
+	case k.code == keyDown || k.is('j'):
+		e.detailCursor++
+	case k.code == keyHome || k.is('g'):
+		e.detailCursor = 0
+	case k.code == keyEnd || k.is('G'):
+		e.detailCursor = len(e.detail.TraceIDs) - 1
+	case k.code == keyEnter:
+		if len(e.detail.TraceIDs) == 0 {
+			break
+		}
+		s, err := e.src.Waterfall(e.detail.TraceIDs[e.detailCursor])
+		if err != nil {
+			e.message = err.Error()
+			break
+		}
+		e.waterfall = strings.Split(strings.TrimRight(s, "\n"), "\n")
+		e.waterfallOffset = 0
+		e.page = pageWaterfall
+	case k.code == keyEscape || k.code == keyBackspace || k.code == keyLeft || k.is('h') || k.is('q'):
+		e.page = pageTable
+	}
+
+	e.detailCursor = clamp(e.detailCursor, 0, len(e.detail.TraceIDs)-1)
+}
+
+func (e *explorer) handleWaterfall(k key) {
+	switch {
+	case k.code == keyUp || k.is('k'):
+		e.waterfallOffset--
+	case k.code == keyDown || k.is('j'):
+		e.waterfallOffset++
+	case k.code == keyPageUp:
+		e.waterfallOffset -= e.bodyRows()
+	case k.code == keyPageDown || k.is(' '):
+		e.waterfallOffset += e.bodyRows()
+	case k.code == keyHome || k.is('g'):
+		e.waterfallOffset = 0
+	case k.code == keyEnd || k.is('G'):
+		e.waterfallOffset = len(e.waterfall)
+	case k.code == keyEscape || k.code == keyBackspace || k.code == keyLeft || k.is('h') || k.is('q'):
+		e.page = pageDetail
+	}
+
+	e.waterfallOffset = clamp(e.waterfallOffset, 0, len(e.waterfall)-e.bodyRows())
+}
+
+// edit changes the filters, the tables are made again by Enter and kept if the filters are invalid
+func (e *explorer) edit(k key) {
+	e.message = ""
+	switch k.code {
+	case keyEnter:
+		filters := string(e.input)
+		tables, err := e.src.Tables(filters)
+		if err != nil {
+			e.message = err.Error()
+			return
+		}
+		e.filters = filters
+		e.editing = false
+		e.page = pageTable
+		e.setTables(tables)
+	case keyEscape:
+		e.editing = false
+	case keyBackspace:
+		if len(e.input) > 0 {
+			e.input = e.input[:len(e.input)-1]
+		}
+	case keyCtrlU:
+		e.input = e.input[:0]
+	case keyRune:
+		e.input = append(e.input, k.r)
+	}
+}
+
+// bodyRows is the number of the lines between the title and the footer
+func (e *explorer) bodyRows() int {
+	if e.height < 3 {
+		return 1
+	}
+	return e.height - 2
+}
+
+// tableRows is the number of the rows below the header of the table
+func (e *explorer) tableRows() int {
+	if e.height < 4 {
+		return 1
+	}
+	return e.height - 3
+}
+
+func (e *explorer) draw(w io.Writer) {
+	var lines []string
+	switch e.page {
+	case pageTable:
+		lines = e.tableLines()
+	case pageDetail:
+		lines = e.detailLines()
+	case pageWaterfall:
+		lines = e.waterfallLines()
+	}
+
+	var b strings.Builder
+	b.WriteString(escHome)
+	for i, line := range lines {
+		if i > 0 {
+			b.WriteString("\r\n")
+		}
+		b.WriteString(line)
+		b.WriteString(escClearLine)
+	}
+	b.WriteString(escClearBelow)
+
+	io.WriteString(w, b.String())
+}
+
+func (e *explorer) titleBar(parts ...string) string {
+	return escInverse + pad(truncate(" "+strings.Join(parts, "  "), e.width), e.width) + escReset
+}
+
+func (e *explorer) footer(help string) string {
+	// the errors of the filters point the position in the following lines
+	message := strings.SplitN(e.message, "\n", 2)[0]
+
+	if e.editing {
+		line := "Filter: " + string(e.input) + "_"
+		if message != "" {
+			line += "  " + message
+		}
+		return truncate(line, e.width)
+	}
+
+	if message != "" {
+		return truncate(message, e.width)
+	}
+
+	return truncate(help, e.width)
+}
+
+func (e *explorer) tableLines() []string {
+	t, st := e.tables[e.view], e.states[e.view]
+
+	parts := []string{e.title}
+	for i, table := range e.tables {
+		if i == e.view {
+			parts = append(parts, "["+table.Title+"]")
+		} else {
+			parts = append(parts, table.Title)
+		}
+	}
+	parts = append(parts, fmt.Sprintf("%d rows", len(st.order)))
+	if e.filters != "" {
+		parts = append(parts, "filter: "+e.filters)
+	}
+
+	headers := make([]string, len(t.Columns))
+	for i, column := range t.Columns {
+		headers[i] = column
+		if column == st.sorted {
+			if st.reverse {
+				headers[i] += " ▼"
+			} else {
+				headers[i] += " ▲"
+			}
+		}
+	}
+
+	widths := columnWidths(headers, t.Rows)
+	e.scrollColumns(st, widths)
+
+	n := e.tableRows()
+	if st.cursor < st.offset {
+		st.offset = st.cursor
+	}
+	if st.cursor >= st.offset+n {
+		st.offset = st.cursor - n + 1
+	}
+
+	lines := make([]string, 0, e.height)
+	lines = append(lines, e.titleBar(parts...))
+	lines = append(lines, e.rowLine(headers, widths, st.first, st.column, escBold+escUnderline))
+	for i := st.offset; i < st.offset+n; i++ {
+		if i >= len(st.order) {
+			lines = append(lines, "")
+			continue
+		}
+
+		line := e.rowLine(t.Rows[st.order[i]], widths, st.first, -1, "")
+		if i == st.cursor {
+			line = escInverse + pad(line, e.width) + escReset
+		}
+		lines = append(lines, line)
+	}
+
+	help := "↑↓ move  ←→ column  s sort  r reverse  / filter  Tab view  q quit"
+	if t.Details != nil {
+		help = "↑↓ move  ←→ column  s sort  r reverse  / filter  Tab view  Enter detail  q quit"
+	}
+
+	return append(lines, e.footer(help))
+}
+
+// scrollColumns shows the selected column by scrolling the columns horizontally
+func (e *explorer) scrollColumns(st *tableState, widths []int) {
+	if st.column < st.first {
+		st.first = st.column
+	}
+
+	for st.first < st.column {
+		span := 0
+		for _, w := range widths[st.first : st.column+1] {
+			span += w + columnGap
+		}
+		if span-columnGap <= e.width {
+			break
+		}
+		st.first++
+	}
+}
+
+// rowLine joins the cells from the first column within the width of the terminal,
+// the selected cell is decorated by the style
+func (e *explorer) rowLine(cells []string, widths []int, first, selected int, style string) string {
+	var b strings.Builder
+	used := 0
+	for i := first; i < len(widths); i++ {
+		if i > first {
+			if used+columnGap >= e.width {
+				break
+			}
+			b.WriteString(strings.Repeat(" ", columnGap))
+			used += columnGap
+		}
+
+		text := pad(truncate(cell(cells, i), widths[i]), widths[i])
+		if avail := e.width - used; runewidth.StringWidth(text) > avail {
+			text = runewidth.Truncate(text, avail, "")
+		}
+		used += runewidth.StringWidth(text)
+
+		if i == selected && style != "" {
+			b.WriteString(style + text + escReset)
+		} else {
+			b.WriteString(text)
+		}
+
+		if used >= e.width {
+			break
+		}
+	}
+
+	return b.String()
+}
+
+func (e *explorer) detailLines() []string {
+	d := e.detail
+	widths := columnWidths(d.Columns, d.Rows)
+
+	body := make([]string, 0, len(d.Rows)+len(d.TraceIDs)+3)
+	body = append(body, e.rowLine(d.Columns, widths, 0, -1, ""))
+	for _, row := range d.Rows {
+		body = append(body, e.rowLine(row, widths, 0, -1, ""))
+	}
+	body = append(body, "", "Exemplar traces")
+	base := len(body)
+	for _, traceID := range d.TraceIDs {
+		body = append(body, truncate("  "+traceID, e.width))
+	}
+
+	n := e.bodyRows()
+	if len(d.TraceIDs) > 0 {
+		line := base + e.detailCursor
+		if line < e.detailOffset {
+			e.detailOffset = line
+		}
+		if line >= e.detailOffset+n {
+			e.detailOffset = line - n + 1
+		}
+		body[line] = escInverse + pad(body[line], e.width) + escReset
+	}
+
+	lines := make([]string, 0, e.height)
+	lines = append(lines, e.titleBar(e.title, e.detailTitle))
+	for i := e.detailOffset; i < e.detailOffset+n; i++ {
+		if i < len(body) {
+			lines = append(lines, body[i])
+		} else {
+			lines = append(lines, "")
+		}
+	}
+
+	return append(lines, e.footer("↑↓ move  Enter waterfall  Esc back"))
+}
+
+func (e *explorer) waterfallLines() []string {
+	lines := make([]string, 0, e.height)
+	lines = append(lines, e.titleBar(e.title, e.detailTitle, e.detail.TraceIDs[e.detailCursor]))
+	for i := e.waterfallOffset; i < e.waterfallOffset+e.bodyRows(); i++ {
+		if i < len(e.waterfall) {
+			lines = append(lines, truncate(e.waterfall[i], e.width))
+		} else {
+			lines = append(lines, "")
+		}
+	}
+
+	return append(lines, e.footer("↑↓ scroll  Esc back"))
+}
+
+func columnWidths(headers []string, rows [][]string) []int {
+	widths := make([]int, len(headers))
+	for i, header := range headers {
+		widths[i] = runewidth.StringWidth(header)
+	}
+
+	for _, row := range rows {
+		for i := range widths {
+			if w := runewidth.StringWidth(cell(row, i)); w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+
+	for i := range widths {
+		if widths[i] > maxCellWidth {
+			widths[i] = maxCellWidth
+		}
+	}
+
+	return widths
+}
+
+func cell(row []string, i int) string {
+	if i < 0 || i >= len(row) {
+		return ""
+	}
+	return row[i]
+}
+
+func indexOf(values []string, s string) int {
+	for i, v := range values {
+		if v == s {
+			return i
+		}
+	}
+	return -1
+}
+
+func clamp(v, min, max int) int {
+	if v > max {
+		v = max
+	}
+	if v < min {
+		v = min
+	}
+	return v
+}
+
+func truncate(s string, w int) string {
+	return runewidth.Truncate(s, w, "…")
+}
+
+func pad(s string, w int) string {
+	if n := w - runewidth.StringWidth(s); n > 0 {
+		return s + strings.Repeat(" ", n)
+	}
+	return s
+}
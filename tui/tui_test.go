@@ -0,0 +1,355 @@
+package tui
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeSource makes the tables of the endpoints and the scenarios, and records the filters
+type fakeSource struct {
+	filters []string
+}
+
+func (s *fakeSource) Tables(filters string) ([]*Table, error) {
+	s.filters = append(s.filters, filters)
+	if filters == "invalid" {
+		return nil, fmt.Errorf("unexpected token\n  | invalid")
+	}
+
+	endpoints := &Table{
+		Title:   "Endpoints",
+		Columns: []string{"count", "uri"},
+		Rows:    [][]string{{"2", "/b"}, {"10", "/a"}, {"1", "/c"}},
+	}
+	if filters != "" {
+		endpoints.Rows = endpoints.Rows[:1]
+	}
+
+	return []*Table{
+		endpoints,
+		{
+			Title:   "Scenarios",
+			Columns: []string{"id", "count"},
+			Rows:    [][]string{{"s1", "3"}, {"s2", "1"}},
+			Details: []*Detail{
+				{Columns: []string{"uri"}, Rows: [][]string{{"/a"}}, TraceIDs: []string{"t1", "t2"}},
+				{Columns: []string{"uri"}, Rows: [][]string{{"/b"}}, TraceIDs: []string{"missing"}},
+			},
+		},
+	}, nil
+}
+
+func (s *fakeSource) Waterfall(traceID string) (string, error) {
+	if traceID == "missing" {
+		return "", fmt.Errorf("trace not found: %s", traceID)
+	}
+
+	return fmt.Sprintf("waterfall of %s\n/a\n/b\n", traceID), nil
+}
+
+func newTestExplorer(t *testing.T, view int) (*explorer, *fakeSource) {
+	t.Helper()
+
+	src := &fakeSource{}
+	tables, err := src.Tables("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return newExplorer(src, "alp-trace", tables, view), src
+}
+
+// press handles the keys of the input, and returns false if it quits
+func press(e *explorer, input string) bool {
+	for _, k := range parseKeys([]byte(input)) {
+		if !e.handle(k) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestExplorerTable(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		wantCursor int
+		wantColumn int
+		wantOrder  []int
+	}{
+		{name: "down", input: "jj", wantCursor: 2, wantOrder: []int{0, 1, 2}},
+		{name: "down to the end", input: "jjjj\x1b[B", wantCursor: 2, wantOrder: []int{0, 1, 2}},
+		{name: "up from the start", input: "k\x1b[A", wantCursor: 0, wantOrder: []int{0, 1, 2}},
+		{name: "end and home", input: "G", wantCursor: 2, wantOrder: []int{0, 1, 2}},
+		{name: "home", input: "Gg", wantCursor: 0, wantOrder: []int{0, 1, 2}},
+		{name: "page down", input: " ", wantCursor: 2, wantOrder: []int{0, 1, 2}},
+		{name: "columns", input: "lll", wantColumn: 1, wantOrder: []int{0, 1, 2}},
+		{name: "left from the start", input: "h", wantColumn: 0, wantOrder: []int{0, 1, 2}},
+		// the numbers are compared as the numbers
+		{name: "sort", input: "s", wantOrder: []int{2, 0, 1}},
+		{name: "sort reverse", input: "ss", wantOrder: []int{1, 0, 2}},
+		{name: "sort other column", input: "sls", wantColumn: 1, wantOrder: []int{1, 0, 2}},
+		{name: "reverse", input: "r", wantOrder: []int{2, 1, 0}},
+		{name: "unknown", input: "x\x1b[2~", wantOrder: []int{0, 1, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, _ := newTestExplorer(t, 0)
+			if !press(e, tt.input) {
+				t.Fatal("want not to quit")
+			}
+
+			st := e.states[0]
+			if st.cursor != tt.wantCursor {
+				t.Errorf("want cursor: %d, got: %d", tt.wantCursor, st.cursor)
+			}
+			if st.column != tt.wantColumn {
+				t.Errorf("want column: %d, got: %d", tt.wantColumn, st.column)
+			}
+			if !reflect.DeepEqual(st.order, tt.wantOrder) {
+				t.Errorf("want order: %v, got: %v", tt.wantOrder, st.order)
+			}
+		})
+	}
+}
+
+func TestExplorerView(t *testing.T) {
+	e, _ := newTestExplorer(t, 1)
+	if e.view != 1 {
+		t.Fatalf("want the view of the scenarios, got: %d", e.view)
+	}
+
+	// the states are kept for each table
+	press(e, "j\tjj")
+	if e.view != 0 {
+		t.Errorf("want the view of the endpoints, got: %d", e.view)
+	}
+	if e.states[0].cursor != 2 || e.states[1].cursor != 1 {
+		t.Errorf("want cursors: 2, 1, got: %d, %d", e.states[0].cursor, e.states[1].cursor)
+	}
+
+	// the endpoints have no details
+	press(e, "\r")
+	if e.page != pageTable {
+		t.Errorf("want the table, got the page: %d", e.page)
+	}
+}
+
+func TestExplorerQuit(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "q", input: "jq"},
+		{name: "ctrl-c", input: "j\x03"},
+		{name: "ctrl-c while editing", input: "/q\x03"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, _ := newTestExplorer(t, 0)
+			if press(e, tt.input) {
+				t.Error("want to quit")
+			}
+		})
+	}
+}
+
+func TestExplorerFilter(t *testing.T) {
+	e, src := newTestExplorer(t, 0)
+
+	press(e, "G/statx")
+	if !e.editing || string(e.input) != "statx" {
+		t.Fatalf("want the filters edited, got: %v, %q", e.editing, string(e.input))
+	}
+
+	// the keys of the table are the input while editing
+	press(e, "\x7fus\r")
+	if e.editing || e.filters != "status" {
+		t.Errorf("want the filters applied, got: %v, %q", e.editing, e.filters)
+	}
+	if got := src.filters[len(src.filters)-1]; got != "status" {
+		t.Errorf("want the tables made with the filters, got: %q", got)
+	}
+	if len(e.tables[0].Rows) != 1 || e.states[0].cursor != 0 {
+		t.Errorf("want the cursor in the filtered rows, got: %d in %d rows", e.states[0].cursor, len(e.tables[0].Rows))
+	}
+
+	// the tables are kept if the filters are invalid
+	press(e, "/\x15invalid\r")
+	if !e.editing || e.filters != "status" || e.message == "" {
+		t.Errorf("want the filters kept with the error, got: %v, %q, %q", e.editing, e.filters, e.message)
+	}
+	if lines := e.tableLines(); !strings.Contains(lines[len(lines)-1], "Filter: invalid_  unexpected token") || strings.Contains(lines[len(lines)-1], "| invalid") {
+		t.Errorf("want the first line of the error in the footer, got: %q", lines[len(lines)-1])
+	}
+
+	press(e, "\x1b")
+	if e.editing || e.filters != "status" {
+		t.Errorf("want the editing canceled, got: %v, %q", e.editing, e.filters)
+	}
+
+	// the filters are cleared by the empty input
+	press(e, "/\x15\r")
+	if e.filters != "" || len(e.tables[0].Rows) != 3 {
+		t.Errorf("want the filters cleared, got: %q in %d rows", e.filters, len(e.tables[0].Rows))
+	}
+}
+
+func TestExplorerDetail(t *testing.T) {
+	e, _ := newTestExplorer(t, 1)
+
+	press(e, "\r")
+	if e.page != pageDetail || e.detailTitle != "id: s1" {
+		t.Fatalf("want the detail of s1, got the page: %d, %q", e.page, e.detailTitle)
+	}
+
+	press(e, "jj\r")
+	if e.page != pageWaterfall || e.waterfall[0] != "waterfall of t2" {
+		t.Fatalf("want the waterfall of t2, got the page: %d, %v", e.page, e.waterfall)
+	}
+
+	press(e, "\x1b")
+	if e.page != pageDetail {
+		t.Errorf("want the detail, got the page: %d", e.page)
+	}
+
+	press(e, "q")
+	if e.page != pageTable {
+		t.Errorf("want the table, got the page: %d", e.page)
+	}
+
+	// the error of the waterfall is shown in the detail
+	press(e, "j\r\r")
+	if e.page != pageDetail || e.message != "trace not found: missing" {
+		t.Errorf("want the error in the detail, got the page: %d, %q", e.page, e.message)
+	}
+}
+
+func TestExplorerWaterfallScroll(t *testing.T) {
+	e, _ := newTestExplorer(t, 1)
+	e.height = 4
+
+	press(e, "\r\r")
+	if e.page != pageWaterfall {
+		t.Fatalf("want the waterfall, got the page: %d", e.page)
+	}
+
+	tests := []struct {
+		input string
+		want  int
+	}{
+		{input: "j", want: 1},
+		// the last lines are kept in the screen
+		{input: "jjj", want: 1},
+		{input: "k", want: 0},
+		{input: "G", want: 1},
+		{input: "g", want: 0},
+	}
+
+	for _, tt := range tests {
+		press(e, tt.input)
+		if e.waterfallOffset != tt.want {
+			t.Errorf("%q: want offset: %d, got: %d", tt.input, tt.want, e.waterfallOffset)
+		}
+	}
+}
+
+// fakeTerminal reads the chunks of the input, and records the output
+type fakeTerminal struct {
+	input  chan []byte
+	output bytes.Buffer
+	width  int
+	height int
+	resize chan os.Signal
+}
+
+func newFakeTerminal(width, height int, input ...string) *fakeTerminal {
+	t := &fakeTerminal{
+		input:  make(chan []byte, len(input)),
+		width:  width,
+		height: height,
+		resize: make(chan os.Signal),
+	}
+	for _, s := range input {
+		t.input <- []byte(s)
+	}
+	close(t.input)
+
+	return t
+}
+
+func (t *fakeTerminal) Read(b []byte) (int, error) {
+	s, ok := <-t.input
+	if !ok {
+		return 0, io.EOF
+	}
+
+	return copy(b, s), nil
+}
+
+func (t *fakeTerminal) Write(b []byte) (int, error) {
+	return t.output.Write(b)
+}
+
+func (t *fakeTerminal) size() (int, int) {
+	return t.width, t.height
+}
+
+func (t *fakeTerminal) resized() <-chan os.Signal {
+	return t.resize
+}
+
+func TestExplorerRun(t *testing.T) {
+	e, _ := newTestExplorer(t, 0)
+	term := newFakeTerminal(30, 6, "j", "q")
+
+	if err := e.run(term); err != nil {
+		t.Fatal(err)
+	}
+
+	out := term.output.String()
+	if !strings.HasPrefix(out, escEnterAltScreen) || !strings.HasSuffix(out, escExitAltScreen) {
+		t.Errorf("want the alternate screen entered and exited, got: %q", out)
+	}
+
+	// the screen is drawn for the start and each input before q
+	frames := strings.Split(strings.TrimSuffix(strings.TrimPrefix(out, escEnterAltScreen), escExitAltScreen), escHome)[1:]
+	if len(frames) != 2 {
+		t.Fatalf("want 2 frames, got: %d", len(frames))
+	}
+
+	lines := strings.Split(frames[1], "\r\n")
+	if len(lines) != 6 {
+		t.Fatalf("want the lines of the height, got: %d", len(lines))
+	}
+	if !strings.Contains(lines[0], "alp-trace  [Endpoints]  Scen…") {
+		t.Errorf("want the title, got: %q", lines[0])
+	}
+	if want := escInverse + pad("10     /a", 30) + escReset; !strings.HasPrefix(lines[3], want) {
+		t.Errorf("want the cursor on the second row: %q, got: %q", want, lines[3])
+	}
+	// the help is truncated by the width
+	if !strings.HasSuffix(lines[5], "…"+escClearLine+escClearBelow) {
+		t.Errorf("want the footer truncated, got: %q", lines[5])
+	}
+}
+
+func TestExplorerRunInputError(t *testing.T) {
+	e, _ := newTestExplorer(t, 0)
+	term := newFakeTerminal(80, 24, "j")
+
+	if err := e.run(term); err != io.EOF {
+		t.Errorf("want the error of the input, got: %v", err)
+	}
+	if !strings.HasSuffix(term.output.String(), escExitAltScreen) {
+		t.Error("want the alternate screen exited")
+	}
+}